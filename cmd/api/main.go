@@ -48,7 +48,7 @@ func main() {
 	})
 
 	// Register all routes
-	routes.SetupRoutes(router, db.Database, cfg)
+	shutdown := routes.SetupRoutes(router, db.Database, cfg)
 
 	// config server
 	srv := &http.Server{
@@ -78,5 +78,8 @@ func main() {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	// Flush what the background workers still hold, e.g. pending view counts
+	shutdown(ctx)
+
 	log.Println("Server exited")
 }
@@ -23,8 +23,27 @@ type Config struct {
 	CloudinaryAPIKey           string
 	CloudinaryAPISecret        string
 	CloudinaryUploadFolder     string
+	StorageDriver              string
+	LocalStorageDir            string
+	LocalStorageURL            string
+	StorageSigningSecret       string
+	UploadIntentTTLMinutes     int
+	MediaGCGraceHours          int
 	FrontendURL                string
 	DevMode                    bool
+	ViewDedupWindowMinutes     int
+	ViewFlushIntervalSeconds   int
+	RateLimitEnabled           bool
+	RateLimitStore             string
+	RateLimitWindowSeconds     int
+	RateLimitAuthRequests      int
+	RateLimitReportRequests    int
+	RateLimitUploadRequests    int
+	RateLimitFeedRequests      int
+	RateLimitSearchRequests    int
+	PushProvider               string
+	ExportTTLHours             int
+	AccountDeletionGraceDays   int
 }
 
 func Load() *Config {
@@ -34,13 +53,30 @@ func Load() *Config {
 
 	jwtExpireHours, _ := strconv.Atoi(getEnv("JWT_EXPIRE_HOURS", "72"))
 	refreshTokenExpireHours, _ := strconv.Atoi(getEnv("REFRESH_TOKEN_EXPIRE_HOURS", "168")) // 7 days default
+	viewDedupWindowMinutes, _ := strconv.Atoi(getEnv("VIEW_DEDUP_WINDOW_MINUTES", "30"))
+	viewFlushIntervalSeconds, _ := strconv.Atoi(getEnv("VIEW_FLUSH_INTERVAL_SECONDS", "10"))
+	exportTTLHours, _ := strconv.Atoi(getEnv("EXPORT_TTL_HOURS", "72"))
+	uploadIntentTTLMinutes, _ := strconv.Atoi(getEnv("UPLOAD_INTENT_TTL_MINUTES", "30"))
+	mediaGCGraceHours, _ := strconv.Atoi(getEnv("MEDIA_GC_GRACE_HOURS", "24"))
+	deletionGraceDays, _ := strconv.Atoi(getEnv("ACCOUNT_DELETION_GRACE_DAYS", "30"))
+
+	// Rate limits are requests per client IP per window
+	rateLimitWindowSeconds, _ := strconv.Atoi(getEnv("RATE_LIMIT_WINDOW_SECONDS", "60"))
+	rateLimitAuthRequests, _ := strconv.Atoi(getEnv("RATE_LIMIT_AUTH_REQUESTS", "10"))
+	rateLimitReportRequests, _ := strconv.Atoi(getEnv("RATE_LIMIT_REPORT_REQUESTS", "5"))
+	rateLimitUploadRequests, _ := strconv.Atoi(getEnv("RATE_LIMIT_UPLOAD_REQUESTS", "20"))
+	rateLimitFeedRequests, _ := strconv.Atoi(getEnv("RATE_LIMIT_FEED_REQUESTS", "120"))
+	rateLimitSearchRequests, _ := strconv.Atoi(getEnv("RATE_LIMIT_SEARCH_REQUESTS", "60"))
+
+	port := getEnv("PORT", "8080")
+	jwtSecret := getEnv("JWT_SECRET", "change-this-secret")
 
 	return &Config{
-		Port:                       getEnv("PORT", "8080"),
+		Port:                       port,
 		AppEnv:                     getEnv("APP_ENV", "development"),
 		MongoURI:                   getEnv("MONGODB_URI", "mongodb://localhost:27017/?replicaSet=rs0"),
 		DBName:                     getEnv("DB_NAME", "anchor_db"),
-		JWTSecret:                  getEnv("JWT_SECRET", "change-this-secret"),
+		JWTSecret:                  jwtSecret,
 		JWTExpireHours:             jwtExpireHours,
 		RefreshTokenExpireHours:    refreshTokenExpireHours,
 		FirebaseProjectID:          getEnv("FIREBASE_PROJECT_ID", ""),
@@ -50,8 +86,27 @@ func Load() *Config {
 		CloudinaryAPIKey:           getEnv("CLOUDINARY_API_KEY", ""),
 		CloudinaryAPISecret:        getEnv("CLOUDINARY_API_SECRET", ""),
 		CloudinaryUploadFolder:     getEnv("CLOUDINARY_UPLOAD_FOLDER", "anchor"),
+		StorageDriver:              getEnv("STORAGE_DRIVER", "cloudinary"), // cloudinary or local
+		LocalStorageDir:            getEnv("LOCAL_STORAGE_DIR", "./uploads"),
+		LocalStorageURL:            getEnv("LOCAL_STORAGE_URL", "http://localhost:"+port+"/api/v1/media/files"),
+		StorageSigningSecret:       getEnv("STORAGE_SIGNING_SECRET", jwtSecret),
+		UploadIntentTTLMinutes:     uploadIntentTTLMinutes,
+		MediaGCGraceHours:          mediaGCGraceHours,
 		FrontendURL:                getEnv("FRONTEND_URL", "http://localhost:3000"),
 		DevMode:                    getEnv("DEV_MODE", "false") == "true",
+		ViewDedupWindowMinutes:     viewDedupWindowMinutes,
+		ViewFlushIntervalSeconds:   viewFlushIntervalSeconds,
+		RateLimitEnabled:           getEnv("RATE_LIMIT_ENABLED", "true") == "true",
+		RateLimitStore:             getEnv("RATE_LIMIT_STORE", "memory"), // memory, gcra or mongo
+		RateLimitWindowSeconds:     rateLimitWindowSeconds,
+		RateLimitAuthRequests:      rateLimitAuthRequests,
+		RateLimitReportRequests:    rateLimitReportRequests,
+		RateLimitUploadRequests:    rateLimitUploadRequests,
+		RateLimitFeedRequests:      rateLimitFeedRequests,
+		RateLimitSearchRequests:    rateLimitSearchRequests,
+		PushProvider:               getEnv("PUSH_PROVIDER", "stub"), // fcm or stub
+		ExportTTLHours:             exportTTLHours,
+		AccountDeletionGraceDays:   deletionGraceDays,
 	}
 }
 
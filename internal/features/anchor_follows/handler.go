@@ -212,6 +212,85 @@ func (h *Handler) GetFollowStatus(c *gin.Context) {
 	response.Success(c, resp)
 }
 
+// GetFollowChanges godoc
+// @Summary Get changes since last seen
+// @Description Summarize what changed on a followed anchor since the follower's last seen version. Does not mark the anchor as seen.
+// @Tags anchor-follows
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Success 200 {object} response.APIResponse{data=FollowChangesResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/follow/changes [get]
+func (h *Handler) GetFollowChanges(c *gin.Context) {
+	currentUser, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	user := currentUser.(*auth.User)
+
+	anchorIDStr := c.Param("id")
+	anchorID, err := primitive.ObjectIDFromHex(anchorIDStr)
+	if err != nil {
+		response.BadRequest(c, "INVALID_ID", "Invalid anchor ID")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	anchor, err := h.anchorsRepo.GetAnchorByID(ctx, anchorID)
+	if err != nil || anchor == nil || anchor.DeletedAt != nil || !anchor.CanBeViewed(user.ID) {
+		response.NotFound(c, "ANCHOR_NOT_FOUND", "Anchor not found")
+		return
+	}
+
+	follow, _ := h.repo.GetFollow(ctx, user.ID, anchorID)
+	if follow == nil {
+		response.BadRequest(c, "NOT_FOLLOWING", "You are not following this anchor")
+		return
+	}
+
+	resp := FollowChangesResponse{
+		LastSeenVersion: follow.LastSeenVersion,
+		CurrentVersion:  anchor.Version,
+		HasUpdates:      anchor.Version > follow.LastSeenVersion,
+		AddedItems:      []anchors.Item{},
+		UpdatedItems:    []anchors.Item{},
+		RemovedItemIDs:  []primitive.ObjectID{},
+		AnchorFields:    []string{},
+	}
+
+	if resp.HasUpdates {
+		revisions, err := h.anchorsRepo.GetRevisionsSince(ctx, anchorID, follow.LastSeenVersion)
+		if err != nil {
+			response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch changes")
+			return
+		}
+		changes := anchors.SummarizeRevisions(revisions)
+
+		// Items are served as they are now; ones removed since by moderation drop out
+		if resp.AddedItems, err = h.anchorsRepo.GetItemsByIDs(ctx, anchorID, changes.AddedItemIDs); err != nil {
+			response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch changes")
+			return
+		}
+		if resp.UpdatedItems, err = h.anchorsRepo.GetItemsByIDs(ctx, anchorID, changes.UpdatedItemIDs); err != nil {
+			response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch changes")
+			return
+		}
+		if changes.RemovedItemIDs != nil {
+			resp.RemovedItemIDs = changes.RemovedItemIDs
+		}
+		if changes.AnchorFields != nil {
+			resp.AnchorFields = changes.AnchorFields
+		}
+		resp.Reordered = changes.Reordered
+	}
+
+	response.Success(c, resp)
+}
+
 // ToggleNotifications godoc
 // @Summary Toggle update notifications for followed anchor
 // @Description Enable or disable notifications when anchor is updated
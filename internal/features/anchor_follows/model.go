@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/xyz-asif/gotodo/internal/features/anchors"
 )
 
 // AnchorFollow represents a user following an anchor
@@ -53,6 +55,18 @@ type FollowStatusResponse struct {
 	FollowedAt           *time.Time `json:"followedAt"`
 }
 
+// FollowChangesResponse for GET /anchors/:id/follow/changes
+type FollowChangesResponse struct {
+	LastSeenVersion int                  `json:"lastSeenVersion"`
+	CurrentVersion  int                  `json:"currentVersion"`
+	HasUpdates      bool                 `json:"hasUpdates"`
+	AddedItems      []anchors.Item       `json:"addedItems"`
+	UpdatedItems    []anchors.Item       `json:"updatedItems"`
+	RemovedItemIDs  []primitive.ObjectID `json:"removedItemIds"`
+	Reordered       bool                 `json:"reordered"`
+	AnchorFields    []string             `json:"anchorFields"` // anchor details edited, e.g. "title", "tags"
+}
+
 // FollowingAnchorItem for list response
 type FollowingAnchorItem struct {
 	ID                   primitive.ObjectID `json:"id"`
@@ -25,6 +25,7 @@ func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Con
 	{
 		anchorRoutes.POST("/follow", handler.FollowAnchor)
 		anchorRoutes.GET("/follow/status", handler.GetFollowStatus)
+		anchorRoutes.GET("/follow/changes", handler.GetFollowChanges)
 		anchorRoutes.PATCH("/follow/notifications", handler.ToggleNotifications)
 	}
 
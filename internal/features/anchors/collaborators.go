@@ -0,0 +1,399 @@
+package anchors
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListCollaborators lists an anchor's collaborators
+// @Summary List collaborators
+// @Description List an anchor's accepted collaborators. The owner also sees pending invitations.
+// @Tags anchors
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Success 200 {object} response.APIResponse{data=[]CollaboratorResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/collaborators [get]
+func (h *Handler) ListCollaborators(c *gin.Context) {
+	user, anchor, ok := h.getCollaborationAnchor(c)
+	if !ok {
+		return
+	}
+
+	if !anchor.IsMember(user.ID) {
+		response.Forbidden(c, "Only collaborators can see this anchor's collaborators", "ACCESS_DENIED")
+		return
+	}
+
+	collaborators := make([]Collaborator, 0, len(anchor.Collaborators))
+	for _, collaborator := range anchor.Collaborators {
+		if collaborator.Status == CollaboratorAccepted || anchor.IsOwnedBy(user.ID) {
+			collaborators = append(collaborators, collaborator)
+		}
+	}
+
+	resp, err := h.buildCollaboratorResponses(c.Request.Context(), collaborators)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch collaborators", "DATABASE_ERROR")
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// InviteCollaborator invites a user to collaborate on an anchor
+// @Summary Invite collaborator
+// @Description Invite a user as an editor (can add, delete and reorder items) or viewer. The invitee must accept before gaining access.
+// @Tags anchors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Param request body InviteCollaboratorRequest true "Invitee and role"
+// @Success 201 {object} response.APIResponse{data=CollaboratorResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /anchors/{id}/collaborators [post]
+func (h *Handler) InviteCollaborator(c *gin.Context) {
+	var req InviteCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format", "INVALID_JSON")
+		return
+	}
+
+	inviteeID, err := primitive.ObjectIDFromHex(req.UserID)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", "INVALID_ID")
+		return
+	}
+
+	user, anchor, ok := h.getCollaborationAnchor(c)
+	if !ok {
+		return
+	}
+
+	if !anchor.IsOwnedBy(user.ID) {
+		response.Forbidden(c, "Only the owner can invite collaborators", "NOT_OWNER")
+		return
+	}
+
+	if inviteeID == user.ID {
+		response.BadRequest(c, "You cannot invite yourself", "CANNOT_INVITE_SELF")
+		return
+	}
+
+	invitee, err := h.authRepo.GetUserByObjectID(c.Request.Context(), inviteeID)
+	if err != nil || invitee.IsDeleted() {
+		response.NotFound(c, "User not found", "USER_NOT_FOUND")
+		return
+	}
+
+	if containsObjectID(user.BlockedUsers, inviteeID) || containsObjectID(invitee.BlockedUsers, user.ID) {
+		response.Forbidden(c, "You cannot invite this user", "USER_BLOCKED")
+		return
+	}
+
+	collaborator := Collaborator{
+		UserID:    inviteeID,
+		Role:      req.Role,
+		Status:    CollaboratorPending,
+		InvitedAt: time.Now(),
+	}
+
+	if err := h.repo.AddCollaborator(c.Request.Context(), anchor.ID, collaborator); err != nil {
+		switch {
+		case errors.Is(err, ErrCollaboratorExists):
+			response.Conflict(c, "User is already a collaborator", "COLLABORATOR_EXISTS")
+		case errors.Is(err, ErrTooManyCollaborators):
+			response.BadRequest(c, "Anchors can have at most 20 collaborators", "TOO_MANY_COLLABORATORS")
+		default:
+			response.InternalServerError(c, "Failed to invite collaborator", "DATABASE_ERROR")
+		}
+		return
+	}
+
+	if h.notificationService != nil {
+		go func() {
+			if err := h.notificationService.CreateCollaboratorInviteNotification(context.Background(), anchor.ID, anchor.Title, user.ID, inviteeID); err != nil {
+				log.Printf("Failed to create collaborator invite notification: %v", err)
+			}
+		}()
+	}
+
+	response.Created(c, toCollaboratorResponse(collaborator, invitee))
+}
+
+// UpdateCollaborator changes a collaborator's role
+// @Summary Update collaborator role
+// @Description Change a collaborator's or pending invitee's role
+// @Tags anchors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Param userId path string true "Collaborator user ID"
+// @Param request body UpdateCollaboratorRequest true "New role"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/collaborators/{userId} [patch]
+func (h *Handler) UpdateCollaborator(c *gin.Context) {
+	var req UpdateCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format", "INVALID_JSON")
+		return
+	}
+
+	collaboratorID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", "INVALID_ID")
+		return
+	}
+
+	user, anchor, ok := h.getCollaborationAnchor(c)
+	if !ok {
+		return
+	}
+
+	if !anchor.IsOwnedBy(user.ID) {
+		response.Forbidden(c, "Only the owner can change roles", "NOT_OWNER")
+		return
+	}
+
+	if err := h.repo.SetCollaboratorRole(c.Request.Context(), anchor.ID, collaboratorID, req.Role); err != nil {
+		if errors.Is(err, ErrCollaboratorNotFound) {
+			response.NotFound(c, "Collaborator not found", "COLLABORATOR_NOT_FOUND")
+			return
+		}
+		response.InternalServerError(c, "Failed to update collaborator", "DATABASE_ERROR")
+		return
+	}
+
+	response.Success(c, "Collaborator updated")
+}
+
+// RemoveCollaborator removes a collaborator from an anchor
+// @Summary Remove collaborator
+// @Description The owner removes a collaborator or withdraws an invitation; a collaborator passes their own user ID to leave or decline.
+// @Tags anchors
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Param userId path string true "Collaborator user ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/collaborators/{userId} [delete]
+func (h *Handler) RemoveCollaborator(c *gin.Context) {
+	collaboratorID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", "INVALID_ID")
+		return
+	}
+
+	user, anchor, ok := h.getCollaborationAnchor(c)
+	if !ok {
+		return
+	}
+
+	if !anchor.IsOwnedBy(user.ID) && collaboratorID != user.ID {
+		response.Forbidden(c, "Only the owner can remove collaborators", "NOT_OWNER")
+		return
+	}
+
+	if err := h.repo.RemoveCollaborator(c.Request.Context(), anchor.ID, collaboratorID); err != nil {
+		if errors.Is(err, ErrCollaboratorNotFound) {
+			response.NotFound(c, "Collaborator not found", "COLLABORATOR_NOT_FOUND")
+			return
+		}
+		response.InternalServerError(c, "Failed to remove collaborator", "DATABASE_ERROR")
+		return
+	}
+
+	response.Success(c, "Collaborator removed")
+}
+
+// AcceptCollaboration accepts an invitation to collaborate on an anchor
+// @Summary Accept collaboration invite
+// @Description Accept the current user's pending invitation to an anchor
+// @Tags anchors
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/collaborators/accept [post]
+func (h *Handler) AcceptCollaboration(c *gin.Context) {
+	user, anchor, ok := h.getCollaborationAnchor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.AcceptCollaboration(c.Request.Context(), anchor.ID, user.ID); err != nil {
+		if errors.Is(err, ErrCollaboratorNotFound) {
+			response.NotFound(c, "No pending invitation", "INVITATION_NOT_FOUND")
+			return
+		}
+		response.InternalServerError(c, "Failed to accept invitation", "DATABASE_ERROR")
+		return
+	}
+
+	response.Success(c, "Invitation accepted")
+}
+
+// ListCollaborations lists anchors the current user collaborates on
+// @Summary List my collaborations
+// @Description List anchors the current user collaborates on, or has pending invitations to
+// @Tags anchors
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "accepted (default) or pending"
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page"
+// @Success 200 {object} response.APIResponse{data=PaginatedResponse}
+// @Failure 400 {object} response.APIResponse
+// @Router /users/me/collaborations [get]
+func (h *Handler) ListCollaborations(c *gin.Context) {
+	val, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "AUTH_FAILED")
+		return
+	}
+	user := val.(*auth.User)
+
+	var query CollaborationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, err.Error(), "INVALID_QUERY")
+		return
+	}
+
+	anchors, total, err := h.repo.GetCollaborations(c.Request.Context(), user.ID, query.Status, query.Page, query.Limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch collaborations", "DATABASE_ERROR")
+		return
+	}
+
+	items := make([]CollaborationResponse, 0, len(anchors))
+	for i := range anchors {
+		collaborator := anchors[i].GetCollaborator(user.ID)
+		if collaborator == nil {
+			continue
+		}
+		items = append(items, CollaborationResponse{
+			Anchor:    anchors[i].ToPublicAnchor(),
+			Role:      collaborator.Role,
+			Status:    collaborator.Status,
+			InvitedAt: collaborator.InvitedAt,
+		})
+	}
+
+	resp := PaginatedResponse{Data: items}
+	resp.Pagination.Page = query.Page
+	resp.Pagination.Limit = query.Limit
+	resp.Pagination.Total = total
+	resp.Pagination.TotalPages = int((total + int64(query.Limit) - 1) / int64(query.Limit))
+	resp.Pagination.HasMore = int64(query.Page*query.Limit) < total
+
+	response.Success(c, resp)
+}
+
+// getCollaborationAnchor loads the current user and the live anchor in the
+// path, responding with the error otherwise
+func (h *Handler) getCollaborationAnchor(c *gin.Context) (*auth.User, *Anchor, bool) {
+	val, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "AUTH_FAILED")
+		return nil, nil, false
+	}
+	user, ok := val.(*auth.User)
+	if !ok {
+		response.InternalServerError(c, "User context error", "INTERNAL_ERROR")
+		return nil, nil, false
+	}
+
+	anchorID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid anchor ID", "INVALID_ID")
+		return nil, nil, false
+	}
+
+	anchor, err := h.repo.GetAnchorByID(c.Request.Context(), anchorID)
+	if err != nil || anchor.DeletedAt != nil {
+		response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
+		return nil, nil, false
+	}
+
+	return user, anchor, true
+}
+
+// buildCollaboratorResponses attaches user details, dropping accounts that no longer exist
+func (h *Handler) buildCollaboratorResponses(ctx context.Context, collaborators []Collaborator) ([]CollaboratorResponse, error) {
+	resp := make([]CollaboratorResponse, 0, len(collaborators))
+	if len(collaborators) == 0 {
+		return resp, nil
+	}
+
+	userIDs := make([]primitive.ObjectID, len(collaborators))
+	for i, collaborator := range collaborators {
+		userIDs[i] = collaborator.UserID
+	}
+
+	users, err := h.authRepo.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	userMap := make(map[primitive.ObjectID]*auth.User, len(users))
+	for i := range users {
+		userMap[users[i].ID] = &users[i]
+	}
+
+	for _, collaborator := range collaborators {
+		user, exists := userMap[collaborator.UserID]
+		if !exists || user.IsDeleted() {
+			continue
+		}
+		resp = append(resp, toCollaboratorResponse(collaborator, user))
+	}
+
+	return resp, nil
+}
+
+func toCollaboratorResponse(collaborator Collaborator, user *auth.User) CollaboratorResponse {
+	return CollaboratorResponse{
+		User: CollaboratorUser{
+			ID:                user.ID,
+			Username:          user.Username,
+			DisplayName:       user.DisplayName,
+			ProfilePictureUrl: user.ProfilePictureURL,
+		},
+		Role:       collaborator.Role,
+		Status:     collaborator.Status,
+		InvitedAt:  collaborator.InvitedAt,
+		AcceptedAt: collaborator.AcceptedAt,
+	}
+}
+
+func containsObjectID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package anchors
+
+import (
+	"context"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/features/uploads"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FinalizeUpload turns a direct upload into an item
+// @Summary Finalize a direct upload
+// @Description Create an image, audio or file item from a file uploaded with POST /media/upload-intents. The stored file is checked against the upload limits; a file that fails is deleted along with its intent.
+// @Tags anchors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Param request body FinalizeUploadRequest true "Upload intent"
+// @Success 201 {object} response.APIResponse{data=Item}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 410 {object} response.APIResponse
+// @Failure 422 {object} response.APIResponse
+// @Router /anchors/{id}/items/finalize [post]
+func (h *Handler) FinalizeUpload(c *gin.Context) {
+	anchorID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid anchor ID", "INVALID_ID")
+		return
+	}
+
+	var req FinalizeUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format", "INVALID_JSON")
+		return
+	}
+	intentID, err := primitive.ObjectIDFromHex(req.IntentID)
+	if err != nil {
+		response.BadRequest(c, "Invalid intent ID", "INVALID_ID")
+		return
+	}
+	if req.Caption != nil {
+		if err := ValidateCaption(*req.Caption); err != nil {
+			response.BadRequest(c, err.Error(), "VALIDATION_FAILED")
+			return
+		}
+	}
+
+	val, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "AUTH_FAILED")
+		return
+	}
+	user, ok := val.(*auth.User)
+	if !ok {
+		response.InternalServerError(c, "User context error", "INTERNAL_ERROR")
+		return
+	}
+
+	anchor, err := h.repo.GetAnchorByID(c.Request.Context(), anchorID)
+	if err != nil {
+		response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
+		return
+	}
+
+	if !anchor.CanEdit(user.ID) {
+		response.Forbidden(c, "You do not have permission")
+		return
+	}
+
+	uploader, ok := h.storage.(storage.DirectUploader)
+	if !ok || h.uploadsRepo == nil {
+		response.ServiceUnavailable(c, "Direct uploads are not available", "UPLOAD_UNAVAILABLE")
+		return
+	}
+
+	intent, err := h.uploadsRepo.GetForUser(c.Request.Context(), intentID, user.ID)
+	if err != nil {
+		if errors.Is(err, uploads.ErrIntentNotFound) {
+			response.NotFound(c, "Upload intent not found", "INTENT_NOT_FOUND")
+			return
+		}
+		response.InternalServerError(c, "Failed to fetch upload intent", "DATABASE_ERROR")
+		return
+	}
+
+	// Claiming first keeps two requests from finalizing the same upload
+	if err := h.uploadsRepo.Claim(c.Request.Context(), intent.ID, user.ID, time.Now()); err != nil {
+		if !errors.Is(err, uploads.ErrIntentClaimed) {
+			response.InternalServerError(c, "Failed to finalize upload", "DATABASE_ERROR")
+			return
+		}
+		if intent.Status == uploads.StatusFinalizing {
+			response.Conflict(c, "Upload is already being finalized", "INTENT_IN_USE")
+			return
+		}
+		response.Error(c, http.StatusGone, "Upload intent has expired", "INTENT_EXPIRED")
+		return
+	}
+
+	stat, err := uploader.Stat(c.Request.Context(), intent.Kind, intent.PublicID)
+	if err != nil {
+		h.releaseIntent(intent.ID)
+		if errors.Is(err, storage.ErrNotFound) {
+			response.BadRequest(c, "The file has not been uploaded yet", "UPLOAD_NOT_FOUND")
+			return
+		}
+		log.Printf("Failed to look up upload %s: %v", intent.PublicID, err)
+		response.InternalServerError(c, "Failed to verify upload", "UPLOAD_FAILED")
+		return
+	}
+
+	// The client chose what to send, so check what was stored
+	if err := storage.ValidateStored(intent.Kind, stat); err != nil {
+		h.discardIntent(intent)
+		respondInvalidUpload(c, err)
+		return
+	}
+
+	count, err := h.repo.CountAnchorItems(c.Request.Context(), anchorID)
+	if err != nil {
+		h.releaseIntent(intent.ID)
+		response.InternalServerError(c, "Database error", "DATABASE_ERROR")
+		return
+	}
+
+	item := &Item{
+		AnchorID: anchorID,
+		Type:     string(intent.Kind),
+		Position: int(count),
+		AddedBy:  &user.ID,
+	}
+	switch intent.Kind {
+	case storage.KindImage:
+		item.ImageData = &ImageData{
+			CloudinaryURL: stat.URL,
+			PublicID:      stat.PublicID,
+			Width:         stat.Width,
+			Height:        stat.Height,
+			FileSize:      stat.FileSize,
+		}
+	case storage.KindAudio:
+		item.AudioData = &AudioData{
+			CloudinaryURL: stat.URL,
+			PublicID:      stat.PublicID,
+			Duration:      int(stat.Duration),
+			FileSize:      stat.FileSize,
+		}
+	default:
+		item.FileData = &FileData{
+			CloudinaryURL: stat.URL,
+			PublicID:      stat.PublicID,
+			Filename:      intent.Filename,
+			FileType:      fileType(stat, intent.Filename),
+			FileSize:      stat.FileSize,
+		}
+	}
+	if req.Caption != nil {
+		item.Caption = strings.TrimSpace(*req.Caption)
+	}
+
+	if err := h.repo.CreateItem(c.Request.Context(), item); err != nil {
+		h.releaseIntent(intent.ID)
+		response.InternalServerError(c, "Failed to create item", "DATABASE_ERROR")
+		return
+	}
+
+	// The asset now belongs to the item
+	if err := h.uploadsRepo.Delete(c.Request.Context(), intent.ID); err != nil {
+		log.Printf("Failed to delete finalized upload intent %s: %v", intent.ID.Hex(), err)
+	}
+
+	h.repo.UpdateAnchor(c.Request.Context(), anchorID, map[string]interface{}{
+		"$set": map[string]interface{}{"lastItemAddedAt": item.CreatedAt},
+		"$inc": map[string]interface{}{"itemCount": 1},
+	})
+
+	h.recordRevision(c.Request.Context(), Revision{
+		AnchorID: anchorID,
+		ActorID:  &user.ID,
+		Type:     RevisionItemAdded,
+		ItemID:   &item.ID,
+		ItemType: item.Type,
+	})
+
+	h.notifyMembers(anchor, user.ID)
+
+	response.Created(c, item)
+}
+
+// fileType is the sniffed type of a stored file, or the type its name implies
+// when the driver did not sniff it
+func fileType(stat *storage.UploadResult, filename string) string {
+	if stat.ContentType != "" {
+		return stat.ContentType
+	}
+	return mime.TypeByExtension(filepath.Ext(filename))
+}
+
+// releaseIntent returns a claimed intent to pending so the client can retry
+func (h *Handler) releaseIntent(intentID primitive.ObjectID) {
+	if err := h.uploadsRepo.Release(context.Background(), intentID); err != nil {
+		log.Printf("Failed to release upload intent %s: %v", intentID.Hex(), err)
+	}
+}
+
+// discardIntent deletes a rejected upload and its intent
+func (h *Handler) discardIntent(intent *uploads.Intent) {
+	if err := h.uploadsRepo.Delete(context.Background(), intent.ID); err != nil {
+		log.Printf("Failed to delete upload intent %s: %v", intent.ID.Hex(), err)
+	}
+	h.tracker.Release(context.Background(), intent.Kind, intent.PublicID)
+}
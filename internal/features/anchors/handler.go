@@ -2,8 +2,10 @@ package anchors
 
 import (
 	"context"
+	"errors"
 	"log"
 	"math"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -12,8 +14,9 @@ import (
 	"github.com/xyz-asif/gotodo/internal/config"
 	"github.com/xyz-asif/gotodo/internal/features/auth"
 	"github.com/xyz-asif/gotodo/internal/features/notifications"
-	"github.com/xyz-asif/gotodo/internal/pkg/cloudinary"
+	"github.com/xyz-asif/gotodo/internal/features/uploads"
 	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -28,23 +31,31 @@ type Handler struct {
 	authRepo            *auth.Repository
 	notificationService *notifications.Service
 	config              *config.Config
-	cloudinary          *cloudinary.Service
+	storage             storage.Storage
 	likesRepo           interface{}         // Using interface to avoid cycle
 	followsRepo         interface{}         // Using interface to avoid cycle
 	anchorFollowService AnchorFollowService // Interface to avoid cycle
+	viewTracker         *ViewTracker
+	uploadsRepo         *uploads.Repository
+	tracker             storage.Tracker
+	previewer           *Previewer
 }
 
 // NewHandler creates a new anchor handler
-func NewHandler(repo *Repository, authRepo *auth.Repository, notificationService *notifications.Service, cfg *config.Config, cld *cloudinary.Service, likesRepo interface{}, followsRepo interface{}, anchorFollowService AnchorFollowService) *Handler {
+func NewHandler(repo *Repository, authRepo *auth.Repository, notificationService *notifications.Service, cfg *config.Config, store storage.Storage, likesRepo interface{}, followsRepo interface{}, anchorFollowService AnchorFollowService, viewTracker *ViewTracker, uploadsRepo *uploads.Repository, tracker storage.Tracker, previewer *Previewer) *Handler {
 	return &Handler{
 		repo:                repo,
 		authRepo:            authRepo,
 		notificationService: notificationService,
 		config:              cfg,
-		cloudinary:          cld,
+		storage:             store,
 		likesRepo:           likesRepo,
 		followsRepo:         followsRepo,
 		anchorFollowService: anchorFollowService,
+		viewTracker:         viewTracker,
+		uploadsRepo:         uploadsRepo,
+		tracker:             tracker,
+		previewer:           previewer,
 	}
 }
 
@@ -106,6 +117,11 @@ func (h *Handler) CreateAnchor(c *gin.Context) {
 		req.Tags = normalizeTags(req.Tags)
 	}
 
+	commentPolicy := CommentPolicyEveryone
+	if req.CommentPolicy != nil {
+		commentPolicy = *req.CommentPolicy
+	}
+
 	anchor := &Anchor{
 		UserID:          user.ID,
 		Title:           req.Title,
@@ -114,6 +130,7 @@ func (h *Handler) CreateAnchor(c *gin.Context) {
 		CoverMediaValue: coverMediaValue,
 		Visibility:      visibility,
 		Tags:            req.Tags,
+		CommentPolicy:   commentPolicy,
 		ItemCount:       0,
 		LikeCount:       0,
 		CloneCount:      0,
@@ -205,17 +222,26 @@ func (h *Handler) UpdateAnchor(c *gin.Context) {
 		updates["coverMediaValue"] = *req.CoverMediaValue
 	}
 	if req.Visibility != nil {
+		// Anchors hidden by a moderator stay private until the decision is reversed
+		if anchor.HiddenByModeration && *req.Visibility != VisibilityPrivate {
+			response.Forbidden(c, "This anchor has been hidden by a moderator", "ANCHOR_HIDDEN")
+			return
+		}
 		updates["visibility"] = *req.Visibility
 	}
 	if req.Tags != nil {
 		updates["tags"] = normalizeTags(req.Tags)
 	}
+	if req.CommentPolicy != nil {
+		updates["commentPolicy"] = *req.CommentPolicy
+	}
 
 	if len(updates) == 0 {
 		response.Success(c, anchor)
 		return
 	}
 
+	fields := changedAnchorFields(updates)
 	updates["updatedAt"] = time.Now()
 
 	if err := h.repo.UpdateAnchor(c.Request.Context(), anchorID, updates); err != nil {
@@ -223,6 +249,13 @@ func (h *Handler) UpdateAnchor(c *gin.Context) {
 		return
 	}
 
+	h.recordRevision(c.Request.Context(), Revision{
+		AnchorID: anchorID,
+		ActorID:  &user.ID,
+		Type:     RevisionAnchorUpdated,
+		Fields:   fields,
+	})
+
 	// Fetch updated
 	updatedAnchor, err := h.repo.GetAnchorByID(c.Request.Context(), anchorID)
 	if err != nil {
@@ -324,21 +357,21 @@ func (h *Handler) GetAnchor(c *gin.Context) {
 	}
 
 	anchor, err := h.repo.GetAnchorByID(c.Request.Context(), anchorID)
-	if err != nil {
+	if err != nil || h.isOwnerDeleted(c.Request.Context(), anchor.UserID) {
 		response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
 		return
 	}
 
 	// Access control
 	if anchor.Visibility == VisibilityPrivate {
-		// Must be authenticated and owner
+		// Must be authenticated and the owner or a collaborator
 		val, exists := c.Get("user")
 		if !exists {
 			response.Unauthorized(c, "This anchor is private", "PRIVATE_ANCHOR")
 			return
 		}
 		user, ok := val.(*auth.User)
-		if !ok || user.ID != anchor.UserID {
+		if !ok || !anchor.IsMember(user.ID) {
 			response.Forbidden(c, "You cannot view this private anchor")
 			return
 		}
@@ -358,8 +391,11 @@ func (h *Handler) GetAnchor(c *gin.Context) {
 	}
 
 	// Update last seen version for authenticated user (async)
+	var viewerID *primitive.ObjectID
 	if val, exists := c.Get("user"); exists {
 		if user, ok := val.(*auth.User); ok {
+			viewerID = &user.ID
+			anchorResponse.Role = anchor.CollaboratorRole(user.ID)
 			go func(uid, aid primitive.ObjectID, ver int) {
 				if h.anchorFollowService != nil {
 					_ = h.anchorFollowService.UpdateLastSeenVersion(context.Background(), uid, aid, ver)
@@ -368,6 +404,12 @@ func (h *Handler) GetAnchor(c *gin.Context) {
 		}
 	}
 
+	// Record the view (async, deduplicated per viewer)
+	if h.viewTracker != nil && anchor.DeletedAt == nil {
+		viewerKey := ViewerKey(viewerID, c.ClientIP(), c.Request.UserAgent())
+		go h.viewTracker.RecordView(context.Background(), anchor, viewerKey, viewerID)
+	}
+
 	response.Success(c, anchorResponse)
 }
 
@@ -394,6 +436,11 @@ func (h *Handler) ListUserAnchors(c *gin.Context) {
 		return
 	}
 
+	if h.isOwnerDeleted(c.Request.Context(), userID) {
+		response.NotFound(c, "User not found", "USER_NOT_FOUND")
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	if limit > 50 {
@@ -440,6 +487,17 @@ func (h *Handler) ListUserAnchors(c *gin.Context) {
 	})
 }
 
+// isOwnerDeleted reports whether the user has deleted their account, in which
+// case their anchors are hidden. Lookup errors leave the anchors visible.
+func (h *Handler) isOwnerDeleted(ctx context.Context, userID primitive.ObjectID) bool {
+	deleted, err := h.authRepo.IsDeleted(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to check account status for %s: %v", userID.Hex(), err)
+		return false
+	}
+	return deleted
+}
+
 // ListAnchorItems lists items for an anchor with pagination
 func (h *Handler) ListAnchorItems(c *gin.Context) {
 	anchorIDStr := c.Param("id")
@@ -453,21 +511,21 @@ func (h *Handler) ListAnchorItems(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
 	anchor, err := h.repo.GetAnchorByID(c.Request.Context(), anchorID)
-	if err != nil {
+	if err != nil || h.isOwnerDeleted(c.Request.Context(), anchor.UserID) {
 		response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
 		return
 	}
 
 	// Access control
 	if anchor.Visibility == VisibilityPrivate {
-		// Must be authenticated and owner
+		// Must be authenticated and the owner or a collaborator
 		val, exists := c.Get("user")
 		if !exists {
 			response.Unauthorized(c, "This anchor is private", "PRIVATE_ANCHOR")
 			return
 		}
 		user, ok := val.(*auth.User)
-		if !ok || user.ID != anchor.UserID {
+		if !ok || !anchor.IsMember(user.ID) {
 			response.Forbidden(c, "You cannot view this private anchor")
 			return
 		}
@@ -513,14 +571,14 @@ func (h *Handler) AddItem(c *gin.Context) {
 		return
 	}
 
-	// Verify ownership
+	// Verify edit access
 	anchor, err := h.repo.GetAnchorByID(c.Request.Context(), anchorID)
 	if err != nil {
 		response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
 		return
 	}
 
-	if anchor.UserID != user.ID {
+	if !anchor.CanEdit(user.ID) {
 		response.Forbidden(c, "You do not have permission")
 		return
 	}
@@ -539,14 +597,18 @@ func (h *Handler) AddItem(c *gin.Context) {
 	if req.Type == ItemTypeText && req.Content != nil {
 		textData = &TextData{Content: *req.Content}
 	} else if req.Type == ItemTypeURL && req.URL != nil {
-		urlData = &URLData{OriginalURL: *req.URL}
-		// Fetch metadata logic omitted for brevity/fix
+		// The item is added even when no preview can be fetched
+		urlData, err = h.previewer.Preview(c.Request.Context(), *req.URL)
+		if err != nil {
+			urlData = &URLData{OriginalURL: *req.URL}
+		}
 	}
 
 	item := &Item{
 		AnchorID:  anchorID,
 		Type:      req.Type,
 		Position:  int(count),
+		AddedBy:   &user.ID,
 		TextData:  textData,
 		URLData:   urlData,
 		CreatedAt: time.Now(),
@@ -564,8 +626,13 @@ func (h *Handler) AddItem(c *gin.Context) {
 		"$inc": map[string]interface{}{"itemCount": 1},
 	})
 
-	// Increment anchor version
-	_ = h.repo.IncrementVersion(c.Request.Context(), anchorID)
+	h.recordRevision(c.Request.Context(), Revision{
+		AnchorID: anchorID,
+		ActorID:  &user.ID,
+		Type:     RevisionItemAdded,
+		ItemID:   &item.ID,
+		ItemType: item.Type,
+	})
 
 	// Send notifications to followers (async)
 	go func(aid primitive.ObjectID, title string, actorID primitive.ObjectID) {
@@ -581,9 +648,26 @@ func (h *Handler) AddItem(c *gin.Context) {
 		}
 	}(anchorID, anchor.Title, user.ID)
 
+	h.notifyMembers(anchor, user.ID)
+
 	response.Created(c, item)
 }
 
+// notifyMembers tells the anchor's owner and collaborators, other than the
+// actor, that an item was added (async)
+func (h *Handler) notifyMembers(anchor *Anchor, actorID primitive.ObjectID) {
+	if h.notificationService == nil || len(anchor.Collaborators) == 0 {
+		return
+	}
+
+	memberIDs := anchor.MemberIDs()
+	go func() {
+		if err := h.notificationService.CreateCollaboratorItemNotifications(context.Background(), anchor.ID, anchor.Title, actorID, memberIDs); err != nil {
+			log.Printf("Failed to create collaborator item notifications: %v", err)
+		}
+	}()
+}
+
 // UploadItem uploads a file as an item
 func (h *Handler) UploadItem(c *gin.Context) {
 	anchorIDStr := c.Param("id")
@@ -593,7 +677,7 @@ func (h *Handler) UploadItem(c *gin.Context) {
 		return
 	}
 
-	// Verify ownership first
+	// Verify edit access first
 	val, exists := c.Get("user")
 	if !exists {
 		response.Unauthorized(c, "Authentication required", "AUTH_FAILED")
@@ -611,11 +695,16 @@ func (h *Handler) UploadItem(c *gin.Context) {
 		return
 	}
 
-	if anchor.UserID != user.ID {
+	if !anchor.CanEdit(user.ID) {
 		response.Forbidden(c, "You do not have permission")
 		return
 	}
 
+	if h.storage == nil {
+		response.ServiceUnavailable(c, "File uploads are not available", "UPLOAD_UNAVAILABLE")
+		return
+	}
+
 	// Handle file upload
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -625,7 +714,6 @@ func (h *Handler) UploadItem(c *gin.Context) {
 
 	// Determine type based on mime type or extension
 	// Simplified logic: images, videos
-	contentType := file.Header.Get("Content-Type")
 	// itemType := ItemTypeText // fall back
 	// folder := "items/files"
 
@@ -649,19 +737,26 @@ func (h *Handler) UploadItem(c *gin.Context) {
 	}
 	defer fileContent.Close()
 
+	content, info, err := storage.Prepare(storage.KindFile, fileContent, file.Filename, file.Size)
+	if err != nil {
+		respondInvalidUpload(c, err)
+		return
+	}
+
 	// Upload new file using general input
-	uploadResult, err := h.cloudinary.UploadFile(c.Request.Context(), fileContent, file.Filename)
+	uploadResult, err := h.storage.Upload(c.Request.Context(), storage.KindFile, content, file.Filename)
 	if err != nil {
 		response.InternalServerError(c, "Failed to upload file", "UPLOAD_FAILED")
 		return
 	}
+	h.tracker.Track(c.Request.Context(), storage.KindFile, uploadResult.PublicID)
 
 	// Create item struct with FileData
 	fileData := &FileData{
 		CloudinaryURL: uploadResult.URL,
 		PublicID:      uploadResult.PublicID,
 		Filename:      file.Filename,
-		FileType:      contentType,
+		FileType:      info.ContentType,
 		FileSize:      uploadResult.FileSize,
 	}
 
@@ -673,11 +768,13 @@ func (h *Handler) UploadItem(c *gin.Context) {
 		Type:      ItemTypeFile, // General file type
 		FileData:  fileData,
 		Position:  int(count),
+		AddedBy:   &user.ID,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
 	if err := h.repo.CreateItem(c.Request.Context(), item); err != nil {
+		h.tracker.Release(c.Request.Context(), storage.KindFile, uploadResult.PublicID)
 		response.InternalServerError(c, "Failed to create item", "DATABASE_ERROR")
 		return
 	}
@@ -687,9 +784,272 @@ func (h *Handler) UploadItem(c *gin.Context) {
 		"$inc": map[string]interface{}{"itemCount": 1},
 	})
 
+	h.recordRevision(c.Request.Context(), Revision{
+		AnchorID: anchorID,
+		ActorID:  &user.ID,
+		Type:     RevisionItemAdded,
+		ItemID:   &item.ID,
+		ItemType: item.Type,
+	})
+
+	h.notifyMembers(anchor, user.ID)
+
 	response.Success(c, item)
 }
 
+// UpdateItem edits an item
+// @Summary Edit an item
+// @Description Edit a text item's content, override a URL item's title, description or thumbnail, or set any item's caption (JSON). Send multipart/form-data with a file to replace an image, audio or file item's upload, and/or a caption.
+// @Tags anchors
+// @Accept json,mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Param itemId path string true "Item ID"
+// @Param request body UpdateItemRequest false "Fields to change"
+// @Param file formData file false "Replacement upload"
+// @Success 200 {object} response.APIResponse{data=Item}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 422 {object} response.APIResponse
+// @Router /anchors/{id}/items/{itemId} [patch]
+func (h *Handler) UpdateItem(c *gin.Context) {
+	anchorIDStr := c.Param("id")
+	itemIDStr := c.Param("itemId")
+
+	anchorID, err := primitive.ObjectIDFromHex(anchorIDStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid anchor ID", "INVALID_ID")
+		return
+	}
+	itemID, err := primitive.ObjectIDFromHex(itemIDStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid item ID", "INVALID_ID")
+		return
+	}
+
+	val, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "AUTH_FAILED")
+		return
+	}
+	user, ok := val.(*auth.User)
+	if !ok {
+		response.InternalServerError(c, "User context error", "INTERNAL_ERROR")
+		return
+	}
+
+	// Verify edit access to anchor
+	anchor, err := h.repo.GetAnchorByID(c.Request.Context(), anchorID)
+	if err != nil {
+		response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
+		return
+	}
+
+	if !anchor.CanEdit(user.ID) {
+		response.Forbidden(c, "You do not have permission")
+		return
+	}
+
+	// Verify item belongs to anchor
+	item, err := h.repo.GetItemByID(c.Request.Context(), itemID)
+	if err != nil || item.DeletedAt != nil {
+		response.NotFound(c, "Item not found", "ITEM_NOT_FOUND")
+		return
+	}
+	if item.AnchorID != anchorID {
+		response.BadRequest(c, "Item does not belong to this anchor", "INVALID_RELATION")
+		return
+	}
+
+	set := map[string]interface{}{}
+	unset := map[string]interface{}{}
+	var replaced *storage.UploadResult
+
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		if caption, ok := c.GetPostForm("caption"); ok {
+			if err := ValidateCaption(caption); err != nil {
+				response.BadRequest(c, err.Error(), "VALIDATION_FAILED")
+				return
+			}
+			setCaption(set, unset, caption)
+		}
+
+		// A caption-only edit has no file part
+		if _, err := c.FormFile("file"); !errors.Is(err, http.ErrMissingFile) {
+			replaced, ok = h.uploadReplacement(c, item, set)
+			if !ok {
+				return
+			}
+		}
+	} else {
+		var req UpdateItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request format", "INVALID_JSON")
+			return
+		}
+
+		if err := ValidateUpdateItemRequest(&req, item.Type); err != nil {
+			response.BadRequest(c, err.Error(), "VALIDATION_FAILED")
+			return
+		}
+
+		if req.Content != nil {
+			set["textData.content"] = *req.Content
+		}
+		if req.Title != nil {
+			set["urlData.title"] = strings.TrimSpace(*req.Title)
+		}
+		if req.Description != nil {
+			set["urlData.description"] = strings.TrimSpace(*req.Description)
+		}
+		if req.Thumbnail != nil {
+			set["urlData.thumbnail"] = strings.TrimSpace(*req.Thumbnail)
+		}
+		if req.Caption != nil {
+			setCaption(set, unset, *req.Caption)
+		}
+	}
+
+	if len(set) == 0 && len(unset) == 0 {
+		response.Success(c, item)
+		return
+	}
+
+	updatedItem, err := h.repo.UpdateItem(c.Request.Context(), itemID, set, unset)
+	if err != nil {
+		if replaced != nil {
+			h.deleteCopiedAssets([]copiedAsset{{publicID: replaced.PublicID, kind: storage.Kind(item.Type)}})
+		}
+		response.InternalServerError(c, "Failed to update item", "DATABASE_ERROR")
+		return
+	}
+
+	// The old upload is only removed once nothing points at it
+	if replaced != nil {
+		h.tracker.Release(c.Request.Context(), storage.Kind(item.Type), itemPublicID(item))
+	}
+
+	h.recordRevision(c.Request.Context(), Revision{
+		AnchorID: anchorID,
+		ActorID:  &user.ID,
+		Type:     RevisionItemUpdated,
+		ItemID:   &item.ID,
+		ItemType: item.Type,
+		Fields:   changedItemFields(set, unset),
+	})
+
+	response.Success(c, updatedItem)
+}
+
+// respondInvalidUpload responds to an upload that failed inspection
+func respondInvalidUpload(c *gin.Context, err error) {
+	var invalid *storage.ValidationError
+	if errors.As(err, &invalid) {
+		response.ValidationError(c, invalid.Message, invalid.Code)
+		return
+	}
+	response.InternalServerError(c, "Failed to read file", "FILE_ERROR")
+}
+
+// uploadReplacement uploads the request's file as the item's new media and
+// adds the new media fields to set, responding with the error otherwise
+func (h *Handler) uploadReplacement(c *gin.Context, item *Item, set map[string]interface{}) (*storage.UploadResult, bool) {
+	if h.storage == nil {
+		response.ServiceUnavailable(c, "File uploads are not available", "UPLOAD_UNAVAILABLE")
+		return nil, false
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "File is required", "MISSING_FILE")
+		return nil, false
+	}
+
+	switch item.Type {
+	case ItemTypeImage, ItemTypeAudio, ItemTypeFile:
+	default:
+		response.BadRequest(c, "Only image, audio and file items have an upload to replace", "INVALID_ITEM_TYPE")
+		return nil, false
+	}
+
+	fileContent, err := file.Open()
+	if err != nil {
+		response.InternalServerError(c, "Failed to open file", "FILE_ERROR")
+		return nil, false
+	}
+	defer fileContent.Close()
+
+	content, info, err := storage.Prepare(storage.Kind(item.Type), fileContent, file.Filename, file.Size)
+	if err != nil {
+		respondInvalidUpload(c, err)
+		return nil, false
+	}
+
+	var result *storage.UploadResult
+	result, err = h.storage.Upload(c.Request.Context(), storage.Kind(item.Type), content, file.Filename)
+	if err != nil {
+		response.InternalServerError(c, "Failed to upload file", "UPLOAD_FAILED")
+		return nil, false
+	}
+	h.tracker.Track(c.Request.Context(), storage.Kind(item.Type), result.PublicID)
+	if result.Duration == 0 {
+		result.Duration = info.Duration
+	}
+
+	switch item.Type {
+	case ItemTypeImage:
+		set["imageData"] = ImageData{
+			CloudinaryURL: result.URL,
+			PublicID:      result.PublicID,
+			Width:         result.Width,
+			Height:        result.Height,
+			FileSize:      result.FileSize,
+		}
+	case ItemTypeAudio:
+		set["audioData"] = AudioData{
+			CloudinaryURL: result.URL,
+			PublicID:      result.PublicID,
+			Duration:      int(result.Duration),
+			FileSize:      result.FileSize,
+		}
+	default:
+		set["fileData"] = FileData{
+			CloudinaryURL: result.URL,
+			PublicID:      result.PublicID,
+			Filename:      file.Filename,
+			FileType:      info.ContentType,
+			FileSize:      result.FileSize,
+		}
+	}
+
+	return result, true
+}
+
+// setCaption sets the caption, or removes it when blank
+func setCaption(set, unset map[string]interface{}, caption string) {
+	caption = strings.TrimSpace(caption)
+	if caption == "" {
+		unset["caption"] = ""
+		return
+	}
+	set["caption"] = caption
+}
+
+// itemPublicID returns the public ID of the stored asset the item owns, if any
+func itemPublicID(item *Item) string {
+	switch {
+	case item.ImageData != nil:
+		return item.ImageData.PublicID
+	case item.AudioData != nil:
+		return item.AudioData.PublicID
+	case item.FileData != nil:
+		return item.FileData.PublicID
+	}
+	return ""
+}
+
 // DeleteItem deletes an item
 func (h *Handler) DeleteItem(c *gin.Context) {
 	anchorIDStr := c.Param("id")
@@ -717,21 +1077,21 @@ func (h *Handler) DeleteItem(c *gin.Context) {
 		return
 	}
 
-	// Verify ownership of anchor
+	// Verify edit access to anchor
 	anchor, err := h.repo.GetAnchorByID(c.Request.Context(), anchorID)
 	if err != nil {
 		response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
 		return
 	}
 
-	if anchor.UserID != user.ID {
+	if !anchor.CanEdit(user.ID) {
 		response.Forbidden(c, "You do not have permission")
 		return
 	}
 
 	// Verify item belongs to anchor
 	item, err := h.repo.GetItemByID(c.Request.Context(), itemID)
-	if err != nil {
+	if err != nil || item.DeletedAt != nil {
 		response.NotFound(c, "Item not found", "ITEM_NOT_FOUND")
 		return
 	}
@@ -741,15 +1101,31 @@ func (h *Handler) DeleteItem(c *gin.Context) {
 	}
 
 	if err := h.repo.DeleteItem(c.Request.Context(), itemID); err != nil {
+		if errors.Is(err, ErrItemNotFound) {
+			// Removed by moderation, or by a concurrent delete, since it was read
+			response.NotFound(c, "Item not found", "ITEM_NOT_FOUND")
+			return
+		}
 		response.InternalServerError(c, "Failed to delete item", "DATABASE_ERROR")
 		return
 	}
 
+	// Uploads are only owned by their item, so they go with it
+	h.tracker.Release(c.Request.Context(), storage.Kind(item.Type), itemPublicID(item))
+
 	// Decrement count
 	h.repo.UpdateAnchor(c.Request.Context(), anchorID, map[string]interface{}{
 		"$inc": map[string]interface{}{"itemCount": -1},
 	})
 
+	h.recordRevision(c.Request.Context(), Revision{
+		AnchorID: anchorID,
+		ActorID:  &user.ID,
+		Type:     RevisionItemRemoved,
+		ItemID:   &item.ID,
+		ItemType: item.Type,
+	})
+
 	response.Success(c, "Item deleted")
 }
 
@@ -787,7 +1163,7 @@ func (h *Handler) ReorderItems(c *gin.Context) {
 		return
 	}
 
-	if anchor.UserID != user.ID {
+	if !anchor.CanEdit(user.ID) {
 		response.Forbidden(c, "You do not have permission")
 		return
 	}
@@ -797,14 +1173,221 @@ func (h *Handler) ReorderItems(c *gin.Context) {
 		return
 	}
 
+	h.recordRevision(c.Request.Context(), Revision{
+		AnchorID: anchorID,
+		ActorID:  &user.ID,
+		Type:     RevisionItemsReordered,
+	})
+
 	response.Success(c, "Items reordered")
 }
 
 // CloneAnchor creates a copy of an anchor
+// @Summary Clone an anchor
+// @Description Deep-copy an anchor and all of its items into the authenticated user's account
+// @Tags anchors
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Success 201 {object} response.APIResponse{data=AnchorWithItemsResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/clone [post]
 func (h *Handler) CloneAnchor(c *gin.Context) {
-	// Implementation placeholder or reused from Clone System
-	// See previous task. Included here for completeness of Handler struct.
-	response.Success(c, "Clone feature implemented in separate module/task")
+	anchorIDStr := c.Param("id")
+	anchorID, err := primitive.ObjectIDFromHex(anchorIDStr)
+	if err != nil {
+		response.BadRequest(c, "Invalid anchor ID", "INVALID_ID")
+		return
+	}
+
+	val, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "AUTH_FAILED")
+		return
+	}
+	user, ok := val.(*auth.User)
+	if !ok {
+		response.InternalServerError(c, "User context error", "INTERNAL_ERROR")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	source, err := h.repo.GetAnchorByID(ctx, anchorID)
+	if err != nil || source.DeletedAt != nil {
+		response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
+		return
+	}
+
+	// Private anchors can never be cloned, not even by their owner
+	if source.Visibility == VisibilityPrivate {
+		response.Forbidden(c, "Private anchors cannot be cloned", "PRIVATE_ANCHOR")
+		return
+	}
+
+	items, err := h.repo.GetAnchorItems(ctx, anchorID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch items", "DATABASE_ERROR")
+		return
+	}
+
+	// Duplicate media first so a failure leaves nothing half-created in the database
+	copied := make([]copiedAsset, 0)
+	clonedItems := make([]Item, len(items))
+	for i, item := range items {
+		clonedItems[i], err = h.cloneItemAssets(ctx, item, &copied)
+		if err != nil {
+			log.Printf("Failed to duplicate assets for item %s: %v", item.ID.Hex(), err)
+			h.deleteCopiedAssets(copied)
+			response.InternalServerError(c, "Failed to copy anchor media", "UPLOAD_FAILED")
+			return
+		}
+	}
+
+	sourceUserID := source.UserID.Hex()
+	now := time.Now()
+	clone := &Anchor{
+		UserID:             user.ID,
+		Title:              source.Title,
+		Description:        source.Description,
+		CoverMediaType:     source.CoverMediaType,
+		CoverMediaValue:    source.CoverMediaValue,
+		Visibility:         source.Visibility,
+		Tags:               source.Tags,
+		ClonedFromAnchorID: &source.ID,
+		ClonedFromUserID:   &sourceUserID,
+		ItemCount:          len(clonedItems),
+		IsPinned:           false,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	if err := h.repo.CreateAnchor(ctx, clone); err != nil {
+		h.deleteCopiedAssets(copied)
+		response.InternalServerError(c, "Failed to create anchor", "DATABASE_ERROR")
+		return
+	}
+
+	docs := make([]interface{}, len(clonedItems))
+	for i := range clonedItems {
+		clonedItems[i].ID = primitive.NewObjectID()
+		clonedItems[i].AnchorID = clone.ID
+		clonedItems[i].AddedBy = &user.ID
+		clonedItems[i].CreatedAt = now
+		clonedItems[i].UpdatedAt = now
+		docs[i] = clonedItems[i]
+	}
+
+	if err := h.repo.CreateItems(ctx, docs); err != nil {
+		_ = h.repo.DeleteAnchor(ctx, clone.ID)
+		h.deleteCopiedAssets(copied)
+		response.InternalServerError(c, "Failed to copy items", "DATABASE_ERROR")
+		return
+	}
+
+	if err := h.authRepo.IncrementAnchorCount(ctx, user.ID, 1); err != nil {
+		log.Printf("Failed to increment anchor count for user %s: %v", user.ID.Hex(), err)
+	}
+
+	if err := h.repo.IncrementCloneCount(ctx, source.ID, 1); err != nil {
+		log.Printf("Failed to increment clone count for anchor %s: %v", source.ID.Hex(), err)
+	} else if err := h.repo.UpdateEngagementScore(ctx, source.ID); err != nil {
+		log.Printf("Failed to update engagement score for anchor %s: %v", source.ID.Hex(), err)
+	}
+
+	if h.notificationService != nil {
+		go func() {
+			if err := h.notificationService.CreateCloneNotification(context.Background(), clone.ID, source.ID, source.Title, user.ID, source.UserID); err != nil {
+				log.Printf("Failed to create clone notification: %v", err)
+			}
+		}()
+	}
+
+	response.Created(c, AnchorWithItemsResponse{
+		Anchor: *clone,
+		Items:  clonedItems,
+	})
+}
+
+// copiedAsset tracks a duplicated asset so it can be rolled back
+type copiedAsset struct {
+	publicID string
+	kind     storage.Kind
+}
+
+// cloneItemAssets returns a copy of item whose media points at freshly duplicated assets
+func (h *Handler) cloneItemAssets(ctx context.Context, item Item, copied *[]copiedAsset) (Item, error) {
+	clone := item
+
+	switch {
+	case item.ImageData != nil:
+		data := *item.ImageData
+		data.PublicID = "" // never let the clone own (and later delete) the source asset
+		if h.storage != nil && data.CloudinaryURL != "" {
+			result, err := h.storage.Copy(ctx, storage.KindImage, item.ImageData.PublicID, data.CloudinaryURL)
+			if err != nil {
+				return clone, err
+			}
+			h.tracker.Track(ctx, storage.KindImage, result.PublicID)
+			*copied = append(*copied, copiedAsset{publicID: result.PublicID, kind: storage.KindImage})
+			data.CloudinaryURL = result.URL
+			data.PublicID = result.PublicID
+		}
+		clone.ImageData = &data
+	case item.AudioData != nil:
+		data := *item.AudioData
+		data.PublicID = "" // never let the clone own (and later delete) the source asset
+		if h.storage != nil && data.CloudinaryURL != "" {
+			result, err := h.storage.Copy(ctx, storage.KindAudio, item.AudioData.PublicID, data.CloudinaryURL)
+			if err != nil {
+				return clone, err
+			}
+			h.tracker.Track(ctx, storage.KindAudio, result.PublicID)
+			*copied = append(*copied, copiedAsset{publicID: result.PublicID, kind: storage.KindAudio})
+			data.CloudinaryURL = result.URL
+			data.PublicID = result.PublicID
+		}
+		clone.AudioData = &data
+	case item.FileData != nil:
+		data := *item.FileData
+		data.PublicID = "" // never let the clone own (and later delete) the source asset
+		if h.storage != nil && data.CloudinaryURL != "" {
+			result, err := h.storage.Copy(ctx, storage.KindFile, item.FileData.PublicID, data.CloudinaryURL)
+			if err != nil {
+				return clone, err
+			}
+			h.tracker.Track(ctx, storage.KindFile, result.PublicID)
+			*copied = append(*copied, copiedAsset{publicID: result.PublicID, kind: storage.KindFile})
+			data.CloudinaryURL = result.URL
+			data.PublicID = result.PublicID
+		}
+		clone.FileData = &data
+	}
+
+	if item.URLData != nil {
+		data := *item.URLData
+		clone.URLData = &data
+	}
+	if item.TextData != nil {
+		data := *item.TextData
+		clone.TextData = &data
+	}
+
+	return clone, nil
+}
+
+// deleteCopiedAssets removes assets duplicated during a clone that did not complete
+func (h *Handler) deleteCopiedAssets(copied []copiedAsset) {
+	if len(copied) == 0 {
+		return
+	}
+	go func() {
+		for _, asset := range copied {
+			h.tracker.Release(context.Background(), asset.kind, asset.publicID)
+		}
+	}()
 }
 
 // GetAnchorClones godoc
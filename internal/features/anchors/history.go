@@ -0,0 +1,222 @@
+package anchors
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetAnchorHistory lists an anchor's revisions
+// @Summary Get anchor history
+// @Description List an anchor's change log, newest first: items added, removed, edited and reordered, and anchor details edited
+// @Tags anchors
+// @Produce json
+// @Param id path string true "Anchor ID"
+// @Param since query int false "Only revisions after this version"
+// @Param page query int false "Page number"
+// @Param limit query int false "Limit per page"
+// @Success 200 {object} response.APIResponse{data=PaginatedResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/history [get]
+func (h *Handler) GetAnchorHistory(c *gin.Context) {
+	anchorID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid anchor ID", "INVALID_ID")
+		return
+	}
+
+	var query HistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, err.Error(), "INVALID_QUERY")
+		return
+	}
+
+	anchor, err := h.repo.GetAnchorByID(c.Request.Context(), anchorID)
+	if err != nil || h.isOwnerDeleted(c.Request.Context(), anchor.UserID) {
+		response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
+		return
+	}
+
+	// Access control
+	if anchor.Visibility == VisibilityPrivate {
+		// Must be authenticated and the owner or a collaborator
+		val, exists := c.Get("user")
+		if !exists {
+			response.Unauthorized(c, "This anchor is private", "PRIVATE_ANCHOR")
+			return
+		}
+		user, ok := val.(*auth.User)
+		if !ok || !anchor.IsMember(user.ID) {
+			response.Forbidden(c, "You cannot view this private anchor")
+			return
+		}
+	}
+
+	revisions, total, err := h.repo.GetRevisions(c.Request.Context(), anchorID, query.Since, query.Page, query.Limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch history", "DATABASE_ERROR")
+		return
+	}
+
+	items, err := h.buildRevisionResponses(c.Request.Context(), revisions)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch history", "DATABASE_ERROR")
+		return
+	}
+
+	resp := PaginatedResponse{Data: items}
+	resp.Pagination.Page = query.Page
+	resp.Pagination.Limit = query.Limit
+	resp.Pagination.Total = total
+	resp.Pagination.TotalPages = int((total + int64(query.Limit) - 1) / int64(query.Limit))
+	resp.Pagination.HasMore = int64(query.Page*query.Limit) < total
+
+	response.Success(c, resp)
+}
+
+// buildRevisionResponses attaches actor details; actors whose accounts no
+// longer exist are left null
+func (h *Handler) buildRevisionResponses(ctx context.Context, revisions []Revision) ([]RevisionResponse, error) {
+	resp := make([]RevisionResponse, 0, len(revisions))
+	if len(revisions) == 0 {
+		return resp, nil
+	}
+
+	var actorIDs []primitive.ObjectID
+	for _, rev := range revisions {
+		if rev.ActorID != nil && !containsObjectID(actorIDs, *rev.ActorID) {
+			actorIDs = append(actorIDs, *rev.ActorID)
+		}
+	}
+
+	userMap := make(map[primitive.ObjectID]*auth.User)
+	if len(actorIDs) > 0 {
+		users, err := h.authRepo.GetUsersByIDs(ctx, actorIDs)
+		if err != nil {
+			return nil, err
+		}
+		for i := range users {
+			userMap[users[i].ID] = &users[i]
+		}
+	}
+
+	for _, rev := range revisions {
+		item := RevisionResponse{Revision: rev}
+		if rev.ActorID != nil {
+			if user, exists := userMap[*rev.ActorID]; exists && !user.IsDeleted() {
+				item.Actor = &CollaboratorUser{
+					ID:                user.ID,
+					Username:          user.Username,
+					DisplayName:       user.DisplayName,
+					ProfilePictureUrl: user.ProfilePictureURL,
+				}
+			}
+		}
+		resp = append(resp, item)
+	}
+
+	return resp, nil
+}
+
+// recordRevision appends to the anchor's history. The change itself has
+// already been saved, so failures are only logged.
+func (h *Handler) recordRevision(ctx context.Context, rev Revision) {
+	if err := h.repo.RecordRevision(ctx, &rev); err != nil {
+		log.Printf("Failed to record %s revision for anchor %s: %v", rev.Type, rev.AnchorID.Hex(), err)
+	}
+}
+
+// changedItemFields names the item fields touched by an UpdateItem call
+func changedItemFields(set, unset map[string]interface{}) []string {
+	var fields []string
+	for _, updates := range []map[string]interface{}{set, unset} {
+		for key := range updates {
+			field := key[strings.LastIndex(key, ".")+1:]
+			switch field {
+			case "imageData", "audioData", "fileData":
+				field = "upload"
+			}
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// changedAnchorFields names the anchor fields in an UpdateAnchor update
+func changedAnchorFields(updates map[string]interface{}) []string {
+	fields := make([]string, 0, len(updates))
+	for key := range updates {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// SummarizeRevisions folds revisions, oldest first, into their net effect.
+// An item added and then removed in the same run is not reported at all, and
+// edits to an item added in the run are folded into the addition.
+func SummarizeRevisions(revisions []Revision) RevisionChanges {
+	var changes RevisionChanges
+	for _, rev := range revisions {
+		switch rev.Type {
+		case RevisionItemAdded:
+			if rev.ItemID != nil {
+				changes.AddedItemIDs = append(changes.AddedItemIDs, *rev.ItemID)
+			}
+		case RevisionItemRemoved:
+			if rev.ItemID == nil {
+				continue
+			}
+			changes.UpdatedItemIDs = removeObjectID(changes.UpdatedItemIDs, *rev.ItemID)
+			if containsObjectID(changes.AddedItemIDs, *rev.ItemID) {
+				changes.AddedItemIDs = removeObjectID(changes.AddedItemIDs, *rev.ItemID)
+			} else {
+				changes.RemovedItemIDs = append(changes.RemovedItemIDs, *rev.ItemID)
+			}
+		case RevisionItemUpdated:
+			if rev.ItemID == nil || containsObjectID(changes.AddedItemIDs, *rev.ItemID) || containsObjectID(changes.UpdatedItemIDs, *rev.ItemID) {
+				continue
+			}
+			changes.UpdatedItemIDs = append(changes.UpdatedItemIDs, *rev.ItemID)
+		case RevisionItemsReordered:
+			changes.Reordered = true
+		case RevisionAnchorUpdated:
+			for _, field := range rev.Fields {
+				if !containsString(changes.AnchorFields, field) {
+					changes.AnchorFields = append(changes.AnchorFields, field)
+				}
+			}
+		}
+	}
+	sort.Strings(changes.AnchorFields)
+	return changes
+}
+
+func removeObjectID(ids []primitive.ObjectID, id primitive.ObjectID) []primitive.ObjectID {
+	result := ids[:0]
+	for _, candidate := range ids {
+		if candidate != id {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
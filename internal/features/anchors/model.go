@@ -13,7 +13,39 @@ const (
 	VisibilityPublic   = "public"
 )
 
-// Item type constants
+// Comment policy constants
+const (
+	CommentPolicyEveryone  = "everyone"
+	CommentPolicyFollowers = "followers" // only users following the owner
+	CommentPolicyDisabled  = "disabled"
+)
+
+// Collaborator role constants
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor" // can add, delete and reorder items
+	RoleViewer = "viewer" // can view the anchor, even when private
+)
+
+// Collaborator invitation status constants
+const (
+	CollaboratorPending  = "pending"
+	CollaboratorAccepted = "accepted"
+)
+
+// MaxCollaborators limits how many users can be invited to one anchor
+const MaxCollaborators = 20
+
+// Revision type constants
+const (
+	RevisionItemAdded      = "item_added"
+	RevisionItemRemoved    = "item_removed"
+	RevisionItemUpdated    = "item_updated"
+	RevisionItemsReordered = "items_reordered"
+	RevisionAnchorUpdated  = "anchor_updated"
+)
+
+// Item type constants. Image, audio and file match the storage.Kind their upload is stored as.
 const (
 	ItemTypeURL   = "url"
 	ItemTypeImage = "image"
@@ -41,8 +73,12 @@ type Anchor struct {
 	ViewCount          int                 `bson:"viewCount" json:"viewCount"`
 	ItemCount          int                 `bson:"itemCount" json:"itemCount"`
 	EngagementScore    int                 `bson:"engagementScore" json:"engagementScore"`
-	Version            int                 `bson:"version" json:"version"`             // Increments when items added
+	Version            int                 `bson:"version" json:"version"`             // Increments with every revision
 	FollowerCount      int                 `bson:"followerCount" json:"followerCount"` // How many users follow this anchor
+	HiddenByModeration bool                `bson:"hiddenByModeration,omitempty" json:"hiddenByModeration,omitempty"`
+	CommentPolicy      string              `bson:"commentPolicy,omitempty" json:"commentPolicy,omitempty"` // "everyone" when empty
+	PinnedCommentID    *primitive.ObjectID `bson:"pinnedCommentId,omitempty" json:"pinnedCommentId,omitempty"`
+	Collaborators      []Collaborator      `bson:"collaborators,omitempty" json:"-"` // served by GET /anchors/:id/collaborators
 
 	CreatedAt       time.Time  `bson:"createdAt" json:"createdAt"`
 	UpdatedAt       time.Time  `bson:"updatedAt" json:"updatedAt"`
@@ -50,19 +86,46 @@ type Anchor struct {
 	DeletedAt       *time.Time `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
 }
 
+// Collaborator is a user invited by the owner to view or edit an anchor.
+// Invitations grant nothing until accepted.
+type Collaborator struct {
+	UserID     primitive.ObjectID `bson:"userId" json:"userId"`
+	Role       string             `bson:"role" json:"role"`     // "editor", "viewer"
+	Status     string             `bson:"status" json:"status"` // "pending", "accepted"
+	InvitedAt  time.Time          `bson:"invitedAt" json:"invitedAt"`
+	AcceptedAt *time.Time         `bson:"acceptedAt,omitempty" json:"acceptedAt,omitempty"`
+}
+
+// Revision is an entry in an anchor's append-only change log. Each revision
+// carries the anchor version it produced.
+type Revision struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	AnchorID  primitive.ObjectID  `bson:"anchorId" json:"anchorId"`
+	Version   int                 `bson:"version" json:"version"`
+	ActorID   *primitive.ObjectID `bson:"actorId,omitempty" json:"-"` // unset when the actor's account is purged
+	Type      string              `bson:"type" json:"type"`           // "item_added", "item_removed", "item_updated", "items_reordered", "anchor_updated"
+	ItemID    *primitive.ObjectID `bson:"itemId,omitempty" json:"itemId,omitempty"`
+	ItemType  string              `bson:"itemType,omitempty" json:"itemType,omitempty"`
+	Fields    []string            `bson:"fields,omitempty" json:"fields,omitempty"` // changed fields for item_updated and anchor_updated
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+}
+
 // Item represents a single content item within an anchor
 type Item struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	AnchorID  primitive.ObjectID `bson:"anchorId" json:"anchorId"`
-	Type      string             `bson:"type" json:"type"` // "url", "image", "audio", "file", "text"
-	Position  int                `bson:"position" json:"position"`
-	URLData   *URLData           `bson:"urlData,omitempty" json:"urlData,omitempty"`
-	ImageData *ImageData         `bson:"imageData,omitempty" json:"imageData,omitempty"`
-	AudioData *AudioData         `bson:"audioData,omitempty" json:"audioData,omitempty"`
-	FileData  *FileData          `bson:"fileData,omitempty" json:"fileData,omitempty"`
-	TextData  *TextData          `bson:"textData,omitempty" json:"textData,omitempty"`
-	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	AnchorID  primitive.ObjectID  `bson:"anchorId" json:"anchorId"`
+	Type      string              `bson:"type" json:"type"` // "url", "image", "audio", "file", "text"
+	Position  int                 `bson:"position" json:"position"`
+	AddedBy   *primitive.ObjectID `bson:"addedBy,omitempty" json:"addedBy,omitempty"` // unset on items added before collaboration
+	URLData   *URLData            `bson:"urlData,omitempty" json:"urlData,omitempty"`
+	ImageData *ImageData          `bson:"imageData,omitempty" json:"imageData,omitempty"`
+	AudioData *AudioData          `bson:"audioData,omitempty" json:"audioData,omitempty"`
+	FileData  *FileData           `bson:"fileData,omitempty" json:"fileData,omitempty"`
+	TextData  *TextData           `bson:"textData,omitempty" json:"textData,omitempty"`
+	Caption   string              `bson:"caption,omitempty" json:"caption,omitempty"` // optional note on any item type
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time           `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt *time.Time          `bson:"deletedAt,omitempty" json:"-"` // set when removed by moderation
 }
 
 // URLData contains metadata for URL items
@@ -113,6 +176,7 @@ type CreateAnchorRequest struct {
 	CoverMediaValue *string  `json:"coverMediaValue" binding:"omitempty"`
 	Visibility      *string  `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
 	Tags            []string `json:"tags" binding:"omitempty,max=5,dive,min=3,max=20"`
+	CommentPolicy   *string  `json:"commentPolicy" binding:"omitempty,oneof=everyone followers disabled"`
 }
 
 // UpdateAnchorRequest represents the payload for updating an anchor
@@ -123,6 +187,7 @@ type UpdateAnchorRequest struct {
 	CoverMediaValue *string  `json:"coverMediaValue" binding:"omitempty"`
 	Visibility      *string  `json:"visibility" binding:"omitempty,oneof=private unlisted public"`
 	Tags            []string `json:"tags" binding:"omitempty,max=5,dive,min=3,max=20"`
+	CommentPolicy   *string  `json:"commentPolicy" binding:"omitempty,oneof=everyone followers disabled"`
 }
 
 // AddItemRequest represents the payload for adding an item to an anchor
@@ -132,11 +197,45 @@ type AddItemRequest struct {
 	Content *string `json:"content" binding:"omitempty,max=10000"`
 }
 
+// FinalizeUploadRequest represents the payload for turning a direct upload into an item
+type FinalizeUploadRequest struct {
+	IntentID string  `json:"intentId" binding:"required"`
+	Caption  *string `json:"caption"`
+}
+
+// UpdateItemRequest represents the payload for editing an item. Omitted fields
+// are left unchanged.
+type UpdateItemRequest struct {
+	Content     *string `json:"content"`     // text items
+	Title       *string `json:"title"`       // url items: overrides the scraped title
+	Description *string `json:"description"` // url items: overrides the scraped description
+	Thumbnail   *string `json:"thumbnail"`   // url items: overrides the scraped thumbnail, "" removes it
+	Caption     *string `json:"caption"`     // any item, "" removes it
+}
+
 // ReorderItemsRequest represents the payload for reordering items
 type ReorderItemsRequest struct {
 	ItemIDs []string `json:"itemIds" binding:"required,min=1"`
 }
 
+// InviteCollaboratorRequest represents the payload for inviting a collaborator
+type InviteCollaboratorRequest struct {
+	UserID string `json:"userId" binding:"required"`
+	Role   string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// UpdateCollaboratorRequest represents the payload for changing a collaborator's role
+type UpdateCollaboratorRequest struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// CollaborationsQuery represents the query for listing the user's collaborations
+type CollaborationsQuery struct {
+	Status string `form:"status,default=accepted" binding:"oneof=pending accepted"`
+	Page   int    `form:"page,default=1" binding:"min=1"`
+	Limit  int    `form:"limit,default=20" binding:"min=1,max=50"`
+}
+
 // AnchorResponse represents the response for a single anchor
 type AnchorResponse struct {
 	*Anchor
@@ -147,6 +246,54 @@ type AnchorWithItemsResponse struct {
 	Anchor      Anchor      `json:"anchor"`
 	Items       []Item      `json:"items"`
 	LikeSummary interface{} `json:"likeSummary,omitempty"`
+	Role        string      `json:"role,omitempty"` // the viewer's role: "owner", "editor" or "viewer"
+}
+
+// CollaboratorUser represents a collaborator's basic info
+type CollaboratorUser struct {
+	ID                primitive.ObjectID `json:"id"`
+	Username          string             `json:"username"`
+	DisplayName       string             `json:"displayName"`
+	ProfilePictureUrl string             `json:"profilePictureUrl"`
+}
+
+// CollaboratorResponse represents a collaborator in the list
+type CollaboratorResponse struct {
+	User       CollaboratorUser `json:"user"`
+	Role       string           `json:"role"`
+	Status     string           `json:"status"`
+	InvitedAt  time.Time        `json:"invitedAt"`
+	AcceptedAt *time.Time       `json:"acceptedAt,omitempty"`
+}
+
+// CollaborationResponse represents an anchor the user was invited to
+type CollaborationResponse struct {
+	Anchor    map[string]interface{} `json:"anchor"`
+	Role      string                 `json:"role"`
+	Status    string                 `json:"status"`
+	InvitedAt time.Time              `json:"invitedAt"`
+}
+
+// HistoryQuery represents query parameters for an anchor's history
+type HistoryQuery struct {
+	Since int `form:"since" binding:"min=0"` // only revisions after this version
+	Page  int `form:"page,default=1" binding:"min=1"`
+	Limit int `form:"limit,default=20" binding:"min=1,max=50"`
+}
+
+// RevisionResponse represents a revision in the history list
+type RevisionResponse struct {
+	Revision
+	Actor *CollaboratorUser `json:"actor"` // null when the account no longer exists
+}
+
+// RevisionChanges is the net effect of a run of revisions
+type RevisionChanges struct {
+	AddedItemIDs   []primitive.ObjectID
+	RemovedItemIDs []primitive.ObjectID
+	UpdatedItemIDs []primitive.ObjectID
+	Reordered      bool
+	AnchorFields   []string
 }
 
 // ItemResponse represents the response for a single item
@@ -165,6 +312,8 @@ func (a *Anchor) ToPublicAnchor() map[string]interface{} {
 		"coverMediaValue": a.CoverMediaValue,
 		"visibility":      a.Visibility,
 		"isPinned":        a.IsPinned,
+		"commentPolicy":   a.GetCommentPolicy(),
+		"pinnedCommentId": a.PinnedCommentID,
 		"tags":            a.Tags,
 		"likeCount":       a.LikeCount,
 		"cloneCount":      a.CloneCount,
@@ -194,10 +343,68 @@ func (a *Anchor) CanBeViewed(viewerUserID primitive.ObjectID) bool {
 		return true
 	}
 
-	// Private anchors can only be viewed by owner
+	// Private anchors can only be viewed by the owner and collaborators
+	return a.CollaboratorRole(viewerUserID) != ""
+}
+
+// CollaboratorRole returns the user's role on this anchor: RoleOwner, the role
+// of an accepted collaborator, or "" for everyone else
+func (a *Anchor) CollaboratorRole(userID primitive.ObjectID) string {
+	if a.UserID == userID {
+		return RoleOwner
+	}
+	if collaborator := a.GetCollaborator(userID); collaborator != nil && collaborator.Status == CollaboratorAccepted {
+		return collaborator.Role
+	}
+	return ""
+}
+
+// GetCollaborator returns the user's collaborator entry, pending or accepted
+func (a *Anchor) GetCollaborator(userID primitive.ObjectID) *Collaborator {
+	for i := range a.Collaborators {
+		if a.Collaborators[i].UserID == userID {
+			return &a.Collaborators[i]
+		}
+	}
+	return nil
+}
+
+// IsMember checks if the user is the owner or an accepted collaborator
+func (a *Anchor) IsMember(userID primitive.ObjectID) bool {
+	return a.CollaboratorRole(userID) != ""
+}
+
+// CanEdit checks if the user may add, delete and reorder items. Only the
+// owner keeps access to a deleted anchor.
+func (a *Anchor) CanEdit(userID primitive.ObjectID) bool {
+	switch a.CollaboratorRole(userID) {
+	case RoleOwner:
+		return true
+	case RoleEditor:
+		return a.DeletedAt == nil
+	}
 	return false
 }
 
+// MemberIDs returns the owner and accepted collaborators
+func (a *Anchor) MemberIDs() []primitive.ObjectID {
+	ids := []primitive.ObjectID{a.UserID}
+	for _, collaborator := range a.Collaborators {
+		if collaborator.Status == CollaboratorAccepted {
+			ids = append(ids, collaborator.UserID)
+		}
+	}
+	return ids
+}
+
+// GetCommentPolicy returns who may comment on this anchor
+func (a *Anchor) GetCommentPolicy() string {
+	if a.CommentPolicy == "" {
+		return CommentPolicyEveryone
+	}
+	return a.CommentPolicy
+}
+
 // IsOwnedBy checks if the anchor is owned by the given user
 func (a *Anchor) IsOwnedBy(userID primitive.ObjectID) bool {
 	return a.UserID == userID
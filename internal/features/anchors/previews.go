@@ -0,0 +1,216 @@
+package anchors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/pkg/safehttp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Preview cache settings
+const (
+	previewTTL        = 24 * time.Hour
+	previewFailureTTL = 15 * time.Minute // for fetches that failed or found no metadata
+)
+
+// ErrPreviewFailed is returned when a page could not be fetched, including
+// when an earlier failure is still cached
+var ErrPreviewFailed = errors.New("failed to fetch link preview")
+
+// LinkPreview is a cached scrape of a URL. Documents expire via a TTL index.
+type LinkPreview struct {
+	Key       string    `bson:"_id"` // hash of the normalized URL
+	URL       string    `bson:"url"`
+	Data      *URLData  `bson:"data,omitempty"`
+	Error     string    `bson:"error,omitempty"`   // set when the fetch failed
+	Blocked   bool      `bson:"blocked,omitempty"` // the URL resolved to an address that is not allowed
+	FetchedAt time.Time `bson:"fetchedAt"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// Previewer scrapes link previews through a cache shared by every instance,
+// so a URL added by many users is fetched once per TTL. Concurrent requests
+// for the same URL within an instance wait for a single fetch.
+type Previewer struct {
+	collection *mongo.Collection
+
+	mu       sync.Mutex
+	inflight map[string]*previewCall
+}
+
+// previewCall is a fetch in progress that other requests can wait on
+type previewCall struct {
+	done chan struct{}
+	data *URLData
+	err  error
+}
+
+// NewPreviewer creates a previewer and its cache indexes
+func NewPreviewer(db *mongo.Database) *Previewer {
+	collection := db.Collection("link_previews")
+
+	_, _ = collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+
+	return &Previewer{
+		collection: collection,
+		inflight:   make(map[string]*previewCall),
+	}
+}
+
+// Preview returns the metadata for targetURL, from the cache when possible.
+// It fails with ErrURLNotAllowed or ErrPreviewFailed.
+func (p *Previewer) Preview(ctx context.Context, targetURL string) (*URLData, error) {
+	key, err := previewKey(targetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := p.lookup(ctx, key)
+	if err != nil {
+		log.Printf("Failed to read link preview cache: %v", err)
+	}
+	if cached != nil {
+		return cached.result(targetURL)
+	}
+
+	p.mu.Lock()
+	call, ok := p.inflight[key]
+	if !ok {
+		call = &previewCall{done: make(chan struct{})}
+		p.inflight[key] = call
+		go p.fetch(key, targetURL, call)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+	data := *call.data
+	data.OriginalURL = targetURL
+	return &data, nil
+}
+
+// fetch scrapes the URL and caches the outcome. It runs detached from the
+// request that started it, since other requests may be waiting on it.
+func (p *Previewer) fetch(key, targetURL string, call *previewCall) {
+	defer func() {
+		p.mu.Lock()
+		delete(p.inflight, key)
+		p.mu.Unlock()
+		close(call.done)
+	}()
+
+	now := time.Now()
+	entry := LinkPreview{Key: key, URL: targetURL, FetchedAt: now}
+
+	data, err := FetchURLMetadata(context.Background(), targetURL)
+	switch {
+	case errors.Is(err, ErrURLNotAllowed):
+		entry.Error = err.Error()
+		entry.Blocked = true
+		entry.ExpiresAt = now.Add(previewFailureTTL)
+	case err != nil:
+		entry.Error = err.Error()
+		entry.ExpiresAt = now.Add(previewFailureTTL)
+	case data.Title == "" && data.Description == "" && data.Thumbnail == "" && data.Favicon == "":
+		// Often a transient block or error page; try again sooner
+		entry.Data = data
+		entry.ExpiresAt = now.Add(previewFailureTTL)
+	default:
+		entry.Data = data
+		entry.ExpiresAt = now.Add(previewTTL)
+	}
+	call.data, call.err = entry.result(targetURL)
+
+	_, err = p.collection.ReplaceOne(context.Background(), bson.M{"_id": key}, entry, options.Replace().SetUpsert(true))
+	if err != nil {
+		log.Printf("Failed to cache link preview for %s: %v", targetURL, err)
+	}
+}
+
+// lookup returns the unexpired cache entry for key, or nil
+func (p *Previewer) lookup(ctx context.Context, key string) (*LinkPreview, error) {
+	var entry LinkPreview
+	err := p.collection.FindOne(ctx, bson.M{
+		"_id":       key,
+		"expiresAt": bson.M{"$gt": time.Now()}, // the TTL monitor only runs every minute
+	}).Decode(&entry)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// result turns a cache entry into what Preview returns for targetURL
+func (e *LinkPreview) result(targetURL string) (*URLData, error) {
+	switch {
+	case e.Blocked:
+		return nil, ErrURLNotAllowed
+	case e.Error != "" || e.Data == nil:
+		return nil, ErrPreviewFailed
+	}
+	data := *e.Data
+	data.OriginalURL = targetURL
+	return &data, nil
+}
+
+// previewKey checks that a URL may be fetched and identifies it in the cache.
+// Scheme and host case, default ports and fragments do not change what is
+// fetched, so they are normalized.
+func previewKey(targetURL string) (string, error) {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+	}
+
+	if err := safehttp.CheckURL(u); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]" // IPv6 literal
+	default:
+		u.Host = host
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	sum := sha256.Sum256([]byte(u.String()))
+	return hex.EncodeToString(sum[:]), nil
+}
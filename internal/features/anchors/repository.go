@@ -3,6 +3,7 @@ package anchors
 import (
 	"context"
 	"errors"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,15 +14,17 @@ import (
 
 // Repository handles database interactions for the anchors feature
 type Repository struct {
-	anchorsCollection *mongo.Collection
-	itemsCollection   *mongo.Collection
-	db                *mongo.Database
+	anchorsCollection   *mongo.Collection
+	itemsCollection     *mongo.Collection
+	revisionsCollection *mongo.Collection
+	db                  *mongo.Database
 }
 
 // NewRepository initializes the repository and creates necessary indexes
 func NewRepository(db *mongo.Database) *Repository {
 	anchorsCollection := db.Collection("anchors")
 	itemsCollection := db.Collection("items")
+	revisionsCollection := db.Collection("anchor_revisions")
 
 	// Create indexes for anchors collection
 	_, _ = anchorsCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
@@ -37,10 +40,18 @@ func NewRepository(db *mongo.Database) *Repository {
 		{
 			Keys: bson.D{{Key: "lastItemAddedAt", Value: -1}},
 		},
+		{
+			Keys:    bson.D{{Key: "clonedFromAnchorId", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 		{
 			Keys:    bson.D{{Key: "deletedAt", Value: 1}},
 			Options: options.Index().SetSparse(true),
 		},
+		{
+			// Anchors a user collaborates on
+			Keys: bson.D{{Key: "collaborators.userId", Value: 1}},
+		},
 		{
 			// Discovery feed index
 			Keys: bson.D{
@@ -85,12 +96,42 @@ func NewRepository(db *mongo.Database) *Repository {
 		{
 			Keys: bson.D{{Key: "position", Value: 1}},
 		},
+		{
+			Keys:    bson.D{{Key: "addedBy", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// Media reconciler reference checks
+			Keys:    bson.D{{Key: "imageData.publicId", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "audioData.publicId", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "fileData.publicId", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+
+	// Create indexes for revisions collection
+	_, _ = revisionsCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "anchorId", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "actorId", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
 	})
 
 	return &Repository{
-		anchorsCollection: anchorsCollection,
-		itemsCollection:   itemsCollection,
-		db:                db,
+		anchorsCollection:   anchorsCollection,
+		itemsCollection:     itemsCollection,
+		revisionsCollection: revisionsCollection,
+		db:                  db,
 	}
 }
 
@@ -295,7 +336,7 @@ func (r *Repository) CreateItems(ctx context.Context, items []interface{}) error
 
 // GetAnchorItems retrieves all items for a specific anchor, ordered by position
 func (r *Repository) GetAnchorItems(ctx context.Context, anchorID primitive.ObjectID) ([]Item, error) {
-	filter := bson.M{"anchorId": anchorID}
+	filter := bson.M{"anchorId": anchorID, "deletedAt": nil}
 	cursor, err := r.itemsCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "position", Value: 1}}))
 	if err != nil {
 		return nil, err
@@ -312,7 +353,7 @@ func (r *Repository) GetAnchorItems(ctx context.Context, anchorID primitive.Obje
 
 // GetAnchorItemsPaginated retrieves items for an anchor with pagination
 func (r *Repository) GetAnchorItemsPaginated(ctx context.Context, anchorID primitive.ObjectID, page int, limit int) ([]Item, int64, error) {
-	filter := bson.M{"anchorId": anchorID}
+	filter := bson.M{"anchorId": anchorID, "deletedAt": nil}
 
 	opts := options.Find().
 		SetSort(bson.D{{Key: "position", Value: 1}}).
@@ -352,15 +393,40 @@ func (r *Repository) GetItemByID(ctx context.Context, itemID primitive.ObjectID)
 	return &item, nil
 }
 
+// UpdateItem sets and unsets fields on a live item and returns the updated item
+func (r *Repository) UpdateItem(ctx context.Context, itemID primitive.ObjectID, set bson.M, unset bson.M) (*Item, error) {
+	set["updatedAt"] = time.Now()
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	var item Item
+	err := r.itemsCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": itemID, "deletedAt": nil},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&item)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("item not found")
+		}
+		return nil, err
+	}
+
+	return &item, nil
+}
+
 // DeleteItem removes an item from the database
 func (r *Repository) DeleteItem(ctx context.Context, itemID primitive.ObjectID) error {
-	result, err := r.itemsCollection.DeleteOne(ctx, bson.M{"_id": itemID})
+	// Items removed by moderation have already left the anchor's count
+	result, err := r.itemsCollection.DeleteOne(ctx, bson.M{"_id": itemID, "deletedAt": nil})
 	if err != nil {
 		return err
 	}
 
 	if result.DeletedCount == 0 {
-		return errors.New("item not found")
+		return ErrItemNotFound
 	}
 
 	return nil
@@ -411,7 +477,7 @@ func (r *Repository) ReorderItems(ctx context.Context, anchorID string, itemIDs
 
 // CountAnchorItems counts the total number of items in an anchor
 func (r *Repository) CountAnchorItems(ctx context.Context, anchorID primitive.ObjectID) (int64, error) {
-	filter := bson.M{"anchorId": anchorID}
+	filter := bson.M{"anchorId": anchorID, "deletedAt": nil}
 	count, err := r.itemsCollection.CountDocuments(ctx, filter)
 	if err != nil {
 		return 0, err
@@ -420,31 +486,6 @@ func (r *Repository) CountAnchorItems(ctx context.Context, anchorID primitive.Ob
 	return count, nil
 }
 
-// IncrementLikeCount increments or decrements an anchor's like count
-func (r *Repository) IncrementLikeCount(ctx context.Context, anchorID primitive.ObjectID, delta int) error {
-	filter := bson.M{"_id": anchorID}
-	update := bson.M{
-		"$inc": bson.M{"likeCount": delta},
-		"$set": bson.M{"updatedAt": time.Now()},
-	}
-
-	_, err := r.anchorsCollection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
-	}
-
-	// If decrementing, ensure count doesn't go negative
-	if delta < 0 {
-		// Fix negative count if it occurred
-		_, _ = r.anchorsCollection.UpdateOne(ctx,
-			bson.M{"_id": anchorID, "likeCount": bson.M{"$lt": 0}},
-			bson.M{"$set": bson.M{"likeCount": 0}},
-		)
-	}
-
-	return nil
-}
-
 // GetPinnedAnchors retrieves pinned anchors for a specific user
 func (r *Repository) GetPinnedAnchors(ctx context.Context, userID primitive.ObjectID, includePrivate bool) ([]Anchor, error) {
 	filter := bson.M{
@@ -482,8 +523,8 @@ func (r *Repository) UpdateEngagementScore(ctx context.Context, anchorID primiti
 		return err
 	}
 
-	// Calculate: (likes * 2) + (clones * 3) + (comments * 1)
-	score := (anchor.LikeCount * 2) + (anchor.CloneCount * 3) + (anchor.CommentCount * 1)
+	// Calculate: (likes * 2) + (clones * 3) + (comments * 1) + (views / 10)
+	score := (anchor.LikeCount * 2) + (anchor.CloneCount * 3) + (anchor.CommentCount * 1) + (anchor.ViewCount / 10)
 
 	return r.UpdateAnchor(ctx, anchorID, bson.M{
 		"engagementScore": score,
@@ -515,6 +556,30 @@ func (r *Repository) IncrementCommentCount(ctx context.Context, anchorID primiti
 	return nil
 }
 
+// SetPinnedComment pins a comment on an anchor, replacing any pinned before
+func (r *Repository) SetPinnedComment(ctx context.Context, anchorID, commentID primitive.ObjectID) error {
+	result, err := r.anchorsCollection.UpdateOne(ctx,
+		bson.M{"_id": anchorID, "deletedAt": nil},
+		bson.M{"$set": bson.M{"pinnedCommentId": commentID, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errors.New("anchor not found")
+	}
+	return nil
+}
+
+// UnpinComment unpins a comment if it is the one pinned on the anchor
+func (r *Repository) UnpinComment(ctx context.Context, anchorID, commentID primitive.ObjectID) error {
+	_, err := r.anchorsCollection.UpdateOne(ctx,
+		bson.M{"_id": anchorID, "pinnedCommentId": commentID},
+		bson.M{"$unset": bson.M{"pinnedCommentId": ""}, "$set": bson.M{"updatedAt": time.Now()}},
+	)
+	return err
+}
+
 // GetAnchorTitles batch fetches titles for a list of anchor IDs
 func (r *Repository) GetAnchorTitles(ctx context.Context, anchorIDs []primitive.ObjectID) (map[primitive.ObjectID]string, error) {
 	if len(anchorIDs) == 0 {
@@ -562,7 +627,7 @@ func (r *Repository) GetAllUserAnchors(ctx context.Context, userID primitive.Obj
 	return anchors, nil
 }
 
-// DeleteAnchor permanently deletes an anchor and its items
+// DeleteAnchor permanently deletes an anchor, its items and its history
 func (r *Repository) DeleteAnchor(ctx context.Context, anchorID primitive.ObjectID) error {
 	// Delete items first
 	_, err := r.itemsCollection.DeleteMany(ctx, bson.M{"anchorId": anchorID})
@@ -570,6 +635,11 @@ func (r *Repository) DeleteAnchor(ctx context.Context, anchorID primitive.Object
 		return err
 	}
 
+	_, err = r.revisionsCollection.DeleteMany(ctx, bson.M{"anchorId": anchorID})
+	if err != nil {
+		return err
+	}
+
 	// Delete anchor
 	_, err = r.anchorsCollection.DeleteOne(ctx, bson.M{"_id": anchorID})
 	return err
@@ -578,9 +648,9 @@ func (r *Repository) DeleteAnchor(ctx context.Context, anchorID primitive.Object
 // GetUserClonedAnchors returns anchors cloned by a user
 func (r *Repository) GetUserClonedAnchors(ctx context.Context, userID primitive.ObjectID, page, limit int) ([]Anchor, int64, error) {
 	filter := bson.M{
-		"userId":    userID,
-		"isClone":   true,
-		"deletedAt": nil,
+		"userId":             userID,
+		"clonedFromAnchorId": bson.M{"$exists": true},
+		"deletedAt":          nil,
 	}
 
 	total, err := r.anchorsCollection.CountDocuments(ctx, filter)
@@ -610,9 +680,8 @@ func (r *Repository) GetUserClonedAnchors(ctx context.Context, userID primitive.
 // GetAnchorClones returns clones of an anchor
 func (r *Repository) GetAnchorClones(ctx context.Context, originalAnchorID primitive.ObjectID, page, limit int) ([]Anchor, int64, error) {
 	filter := bson.M{
-		"originalAnchorId": originalAnchorID,
-		"isClone":          true,
-		"deletedAt":        nil,
+		"clonedFromAnchorId": originalAnchorID,
+		"deletedAt":          nil,
 	}
 
 	total, err := r.anchorsCollection.CountDocuments(ctx, filter)
@@ -676,22 +745,158 @@ func (r *Repository) IncrementFollowerCount(ctx context.Context, anchorID primit
 	return err
 }
 
-// IncrementVersion increments the anchor version (called when items added)
-func (r *Repository) IncrementVersion(ctx context.Context, anchorID primitive.ObjectID) error {
-	now := time.Now()
+// IncrementViewCounts applies a batch of view count increments in a single bulk write
+func (r *Repository) IncrementViewCounts(ctx context.Context, counts map[primitive.ObjectID]int) error {
+	if len(counts) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(counts))
+	for anchorID, delta := range counts {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": anchorID}).
+			SetUpdate(bson.M{"$inc": bson.M{"viewCount": delta}}))
+	}
+
+	_, err := r.anchorsCollection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// IncrementCloneCount increments the anchor's clone count
+func (r *Repository) IncrementCloneCount(ctx context.Context, anchorID primitive.ObjectID, delta int) error {
 	filter := bson.M{"_id": anchorID}
 	update := bson.M{
-		"$inc": bson.M{"version": 1},
-		"$set": bson.M{
-			"lastItemAddedAt": now,
-			"updatedAt":       now,
-		},
+		"$inc": bson.M{"cloneCount": delta},
+		"$set": bson.M{"updatedAt": time.Now()},
 	}
 
 	_, err := r.anchorsCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	// Ensure count doesn't go negative
+	if delta < 0 {
+		_, _ = r.anchorsCollection.UpdateOne(ctx,
+			bson.M{"_id": anchorID, "cloneCount": bson.M{"$lt": 0}},
+			bson.M{"$set": bson.M{"cloneCount": 0}},
+		)
+	}
+
+	return nil
+}
+
+// RecordRevision increments the anchor version and appends rev to the
+// anchor's history under the new version
+func (r *Repository) RecordRevision(ctx context.Context, rev *Revision) error {
+	now := time.Now()
+	set := bson.M{"updatedAt": now}
+	if rev.Type == RevisionItemAdded {
+		set["lastItemAddedAt"] = now
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"version": 1})
+
+	var anchor Anchor
+	err := r.anchorsCollection.FindOneAndUpdate(ctx, bson.M{"_id": rev.AnchorID}, bson.M{
+		"$inc": bson.M{"version": 1},
+		"$set": set,
+	}, opts).Decode(&anchor)
+	if err != nil {
+		return err
+	}
+
+	rev.ID = primitive.NewObjectID()
+	rev.Version = anchor.Version
+	rev.CreatedAt = now
+
+	_, err = r.revisionsCollection.InsertOne(ctx, rev)
 	return err
 }
 
+// GetRevisions returns an anchor's revisions after sinceVersion, newest first
+func (r *Repository) GetRevisions(ctx context.Context, anchorID primitive.ObjectID, sinceVersion, page, limit int) ([]Revision, int64, error) {
+	filter := bson.M{
+		"anchorId": anchorID,
+		"version":  bson.M{"$gt": sinceVersion},
+	}
+
+	total, err := r.revisionsCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	skip := (page - 1) * limit
+	opts := options.Find().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.revisionsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var revisions []Revision
+	if err = cursor.All(ctx, &revisions); err != nil {
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+// GetRevisionsSince returns all of an anchor's revisions after sinceVersion, oldest first
+func (r *Repository) GetRevisionsSince(ctx context.Context, anchorID primitive.ObjectID, sinceVersion int) ([]Revision, error) {
+	filter := bson.M{
+		"anchorId": anchorID,
+		"version":  bson.M{"$gt": sinceVersion},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+
+	cursor, err := r.revisionsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var revisions []Revision
+	if err = cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// GetItemsByIDs returns the anchor's live items among itemIDs, in position order
+func (r *Repository) GetItemsByIDs(ctx context.Context, anchorID primitive.ObjectID, itemIDs []primitive.ObjectID) ([]Item, error) {
+	if len(itemIDs) == 0 {
+		return []Item{}, nil
+	}
+
+	filter := bson.M{
+		"_id":       bson.M{"$in": itemIDs},
+		"anchorId":  anchorID,
+		"deletedAt": nil,
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "position", Value: 1}})
+
+	cursor, err := r.itemsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := []Item{}
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
 type TagCount struct {
 	Name  string `bson:"name"`
 	Count int    `bson:"count"`
@@ -735,3 +940,140 @@ func (r *Repository) GetPopularTags(ctx context.Context, limit int) ([]TagCount,
 
 	return results, nil
 }
+
+// Collaborator errors
+var (
+	ErrItemNotFound         = errors.New("item not found")
+	ErrCollaboratorExists   = errors.New("user is already a collaborator")
+	ErrCollaboratorNotFound = errors.New("collaborator not found")
+	ErrTooManyCollaborators = errors.New("too many collaborators")
+)
+
+// AddCollaborator invites a user to the anchor. The filter re-checks the
+// duplicate and the limit so concurrent invitations can't overshoot.
+func (r *Repository) AddCollaborator(ctx context.Context, anchorID primitive.ObjectID, collaborator Collaborator) error {
+	filter := bson.M{
+		"_id":                  anchorID,
+		"deletedAt":            nil,
+		"collaborators.userId": bson.M{"$ne": collaborator.UserID},
+		"collaborators." + strconv.Itoa(MaxCollaborators-1): bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$push": bson.M{"collaborators": collaborator},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+
+	result, err := r.anchorsCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		anchor, err := r.GetAnchorByID(ctx, anchorID)
+		if err != nil {
+			return err
+		}
+		if anchor.GetCollaborator(collaborator.UserID) != nil {
+			return ErrCollaboratorExists
+		}
+		return ErrTooManyCollaborators
+	}
+
+	return nil
+}
+
+// SetCollaboratorRole changes a collaborator's role
+func (r *Repository) SetCollaboratorRole(ctx context.Context, anchorID, userID primitive.ObjectID, role string) error {
+	result, err := r.anchorsCollection.UpdateOne(ctx,
+		bson.M{"_id": anchorID, "collaborators.userId": userID},
+		bson.M{"$set": bson.M{"collaborators.$.role": role, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrCollaboratorNotFound
+	}
+
+	return nil
+}
+
+// AcceptCollaboration accepts the user's pending invitation to the anchor
+func (r *Repository) AcceptCollaboration(ctx context.Context, anchorID, userID primitive.ObjectID) error {
+	now := time.Now()
+	result, err := r.anchorsCollection.UpdateOne(ctx,
+		bson.M{
+			"_id":       anchorID,
+			"deletedAt": nil,
+			"collaborators": bson.M{"$elemMatch": bson.M{
+				"userId": userID,
+				"status": CollaboratorPending,
+			}},
+		},
+		bson.M{"$set": bson.M{
+			"collaborators.$.status":     CollaboratorAccepted,
+			"collaborators.$.acceptedAt": now,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrCollaboratorNotFound
+	}
+
+	return nil
+}
+
+// RemoveCollaborator removes a collaborator or declines a pending invitation
+func (r *Repository) RemoveCollaborator(ctx context.Context, anchorID, userID primitive.ObjectID) error {
+	result, err := r.anchorsCollection.UpdateOne(ctx,
+		bson.M{"_id": anchorID, "collaborators.userId": userID},
+		bson.M{
+			"$pull": bson.M{"collaborators": bson.M{"userId": userID}},
+			"$set":  bson.M{"updatedAt": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrCollaboratorNotFound
+	}
+
+	return nil
+}
+
+// GetCollaborations retrieves anchors the user was invited to with the given
+// invitation status, most recently updated first
+func (r *Repository) GetCollaborations(ctx context.Context, userID primitive.ObjectID, status string, page, limit int) ([]Anchor, int64, error) {
+	filter := bson.M{
+		"deletedAt": nil,
+		"collaborators": bson.M{"$elemMatch": bson.M{
+			"userId": userID,
+			"status": status,
+		}},
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "lastItemAddedAt", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.anchorsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var anchors []Anchor
+	if err = cursor.All(ctx, &anchors); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.anchorsCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return anchors, total, nil
+}
@@ -7,26 +7,29 @@ import (
 	"github.com/xyz-asif/gotodo/internal/config"
 	"github.com/xyz-asif/gotodo/internal/features/auth"
 	"github.com/xyz-asif/gotodo/internal/features/notifications"
+	"github.com/xyz-asif/gotodo/internal/features/uploads"
 	"github.com/xyz-asif/gotodo/internal/middleware"
-	"github.com/xyz-asif/gotodo/internal/pkg/cloudinary"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-// RegisterRoutes registers the anchor-related routes
-func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config, anchorFollowService AnchorFollowService, notificationService *notifications.Service) {
+// RegisterRoutes registers the anchor-related routes. tracker records uploaded
+// assets and queues the ones items no longer use for deletion; previewer fetches link
+// previews for URL items; viewTracker counts views and must already be started.
+func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config, anchorFollowService AnchorFollowService, notificationService *notifications.Service, tracker storage.Tracker, previewer *Previewer, viewTracker *ViewTracker) {
 	// Initialize repositories
 	repo := NewRepository(db)
 	authRepo := auth.NewRepository(db)
 	// notificationService := notifications.GetService(db) // This line is now removed as notificationService is passed in
 
-	// Initialize Cloudinary service
-	cloudinarySvc, err := cloudinary.NewService(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret, "anchors")
+	// Initialize media storage
+	store, err := storage.New(cfg, "anchors")
 	if err != nil {
-		log.Printf("Failed to initialize cloudinary service: %v", err)
+		log.Printf("Failed to initialize media storage: %v", err)
 	}
 
 	// Initialize handler (repos passed as nil to avoid import cycles)
-	handler := NewHandler(repo, authRepo, notificationService, cfg, cloudinarySvc, nil, nil, anchorFollowService)
+	handler := NewHandler(repo, authRepo, notificationService, cfg, store, nil, nil, anchorFollowService, viewTracker, uploads.NewRepository(db), tracker, previewer)
 
 	// Initialize auth middleware
 	authMiddleware := middleware.NewAuthMiddleware(authRepo, cfg)
@@ -39,6 +42,7 @@ func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Con
 		anchors.GET("/:id", optionalAuth, handler.GetAnchor)
 		anchors.GET("/:id/items", optionalAuth, handler.ListAnchorItems)
 		anchors.GET("/:id/clones", optionalAuth, handler.GetAnchorClones) // Added route
+		anchors.GET("/:id/history", optionalAuth, handler.GetAnchorHistory)
 		anchors.GET("", optionalAuth, handler.ListUserAnchors)
 
 		// Protected routes (require authentication)
@@ -54,8 +58,20 @@ func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Con
 			// Item routes
 			protected.POST("/:id/items", handler.AddItem)
 			protected.POST("/:id/items/upload", handler.UploadItem)
+			protected.POST("/:id/items/finalize", handler.FinalizeUpload)
+			protected.PATCH("/:id/items/:itemId", handler.UpdateItem)
 			protected.DELETE("/:id/items/:itemId", handler.DeleteItem)
 			protected.PATCH("/:id/items/reorder", handler.ReorderItems)
+
+			// Collaborator routes
+			protected.GET("/:id/collaborators", handler.ListCollaborators)
+			protected.POST("/:id/collaborators", handler.InviteCollaborator)
+			protected.POST("/:id/collaborators/accept", handler.AcceptCollaboration)
+			protected.PATCH("/:id/collaborators/:userId", handler.UpdateCollaborator)
+			protected.DELETE("/:id/collaborators/:userId", handler.RemoveCollaborator)
 		}
 	}
+
+	// Anchors shared with the current user
+	router.GET("/users/me/collaborations", authMiddleware, handler.ListCollaborations)
 }
@@ -1,21 +1,50 @@
 package anchors
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/xyz-asif/gotodo/internal/pkg/safehttp"
 	"golang.org/x/net/html"
 )
 
-// FetchURLMetadata fetches and parses metadata from a given URL
+// Scraper settings
+const (
+	scrapeTimeout = 10 * time.Second
+	// maxScrapeBytes caps how much of a page is read; the metadata is in the head
+	maxScrapeBytes = 1 << 20
+)
+
+// ErrURLNotAllowed is returned for URLs that point at internal addresses or
+// use a scheme or port the scraper does not fetch
+var ErrURLNotAllowed = errors.New("url not allowed")
+
+// scrapeClient only connects to public addresses, checking every redirect
+var scrapeClient = safehttp.NewClient(scrapeTimeout)
+
+// htmlTypes are the content types whose metadata is parsed
+var htmlTypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+}
+
+// FetchURLMetadata fetches and parses metadata from a given URL. Pages that
+// are not HTML, or answer with an error status, only get their URL.
 func FetchURLMetadata(ctx context.Context, targetURL string) (*URLData, error) {
-	// Create client with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+	}
+	if err := safehttp.CheckURL(u); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
 	}
 
 	// Create request
@@ -26,10 +55,14 @@ func FetchURLMetadata(ctx context.Context, targetURL string) (*URLData, error) {
 
 	// Set User-Agent to avoid blocks
 	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; AnchorBot/1.0)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
 
 	// Execute request
-	resp, err := client.Do(req)
+	resp, err := scrapeClient.Do(req)
 	if err != nil {
+		if errors.Is(err, safehttp.ErrBlocked) {
+			return nil, fmt.Errorf("%w: %v", ErrURLNotAllowed, err)
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
@@ -38,7 +71,25 @@ func FetchURLMetadata(ctx context.Context, targetURL string) (*URLData, error) {
 		return &URLData{OriginalURL: targetURL}, nil
 	}
 
-	return parseHTMLMetadata(resp.Body, targetURL), nil
+	body := bufio.NewReader(io.LimitReader(resp.Body, maxScrapeBytes))
+	if !isHTML(resp.Header.Get("Content-Type"), body) {
+		return &URLData{OriginalURL: targetURL}, nil
+	}
+
+	// Relative links are relative to the page, which is wherever redirects ended up
+	data := parseHTMLMetadata(body, resp.Request.URL.String())
+	data.OriginalURL = targetURL
+	return data, nil
+}
+
+// isHTML checks the declared content type, or sniffs the body when there is none
+func isHTML(contentType string, body *bufio.Reader) bool {
+	if contentType == "" {
+		head, _ := body.Peek(512)
+		contentType = http.DetectContentType(head)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && htmlTypes[mediaType]
 }
 
 // parseHTMLMetadata parses the HTML body and extracts metadata
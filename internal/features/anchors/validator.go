@@ -250,6 +250,51 @@ func ValidateAddItemRequest(req *AddItemRequest) error {
 	return nil
 }
 
+// ValidateUpdateItemRequest validates an item edit against the item's type
+func ValidateUpdateItemRequest(req *UpdateItemRequest, itemType string) error {
+	if req.Content != nil {
+		if itemType != ItemTypeText {
+			return errors.New("content can only be edited on text items")
+		}
+		if err := ValidateTextContent(*req.Content); err != nil {
+			return err
+		}
+	}
+
+	if req.Title != nil || req.Description != nil || req.Thumbnail != nil {
+		if itemType != ItemTypeURL {
+			return errors.New("title, description and thumbnail can only be edited on URL items")
+		}
+	}
+	if req.Title != nil && len(strings.TrimSpace(*req.Title)) > 200 {
+		return errors.New("title cannot exceed 200 characters")
+	}
+	if req.Description != nil && len(strings.TrimSpace(*req.Description)) > 500 {
+		return errors.New("description cannot exceed 500 characters")
+	}
+	if req.Thumbnail != nil && *req.Thumbnail != "" {
+		if err := ValidateURL(*req.Thumbnail); err != nil {
+			return fmt.Errorf("thumbnail: %w", err)
+		}
+	}
+
+	if req.Caption != nil {
+		if err := ValidateCaption(*req.Caption); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateCaption validates an item caption
+func ValidateCaption(caption string) error {
+	if len(strings.TrimSpace(caption)) > 500 {
+		return errors.New("caption cannot exceed 500 characters")
+	}
+	return nil
+}
+
 // ValidateReorderItemsRequest validates the reorder request
 func ValidateReorderItemsRequest(req *ReorderItemsRequest, expectedCount int) error {
 	if len(req.ItemIDs) == 0 {
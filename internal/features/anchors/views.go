@@ -0,0 +1,192 @@
+package anchors
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AnchorView records that a viewer has been counted for an anchor.
+// Documents expire via a TTL index, which defines the deduplication window.
+type AnchorView struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	AnchorID  primitive.ObjectID `bson:"anchorId"`
+	ViewerKey string             `bson:"viewerKey"`
+	ExpiresAt time.Time          `bson:"expiresAt"`
+}
+
+// ViewTracker deduplicates anchor views and batches viewCount increments
+type ViewTracker struct {
+	viewsCollection *mongo.Collection
+	repo            *Repository
+	window          time.Duration
+
+	mu      sync.Mutex
+	pending map[primitive.ObjectID]int
+	done    chan struct{} // closed when the flush loop exits; nil until started
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewViewTracker creates a view tracker and its indexes
+func NewViewTracker(db *mongo.Database, repo *Repository, window time.Duration) *ViewTracker {
+	viewsCollection := db.Collection("anchor_views")
+
+	_, _ = viewsCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "anchorId", Value: 1}, {Key: "viewerKey", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+
+	if window <= 0 {
+		window = 30 * time.Minute
+	}
+
+	return &ViewTracker{
+		viewsCollection: viewsCollection,
+		repo:            repo,
+		window:          window,
+		pending:         make(map[primitive.ObjectID]int),
+		stop:            make(chan struct{}),
+	}
+}
+
+// ViewerKey builds a dedup key for a viewer. Authenticated users are keyed by ID,
+// anonymous viewers by a hash of their IP and user agent so raw IPs are never stored.
+func ViewerKey(userID *primitive.ObjectID, clientIP, userAgent string) string {
+	if userID != nil {
+		return "u:" + userID.Hex()
+	}
+	sum := sha256.Sum256([]byte(clientIP + "|" + userAgent))
+	return "a:" + hex.EncodeToString(sum[:16])
+}
+
+// RecordView counts a view unless the viewer is the owner or was already counted in the window
+func (t *ViewTracker) RecordView(ctx context.Context, anchor *Anchor, viewerKey string, viewerID *primitive.ObjectID) {
+	if viewerID != nil && *viewerID == anchor.UserID {
+		return
+	}
+
+	now := time.Now()
+
+	// Expired markers may linger until the TTL monitor runs, so match them explicitly
+	filter := bson.M{
+		"anchorId":  anchor.ID,
+		"viewerKey": viewerKey,
+		"expiresAt": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"expiresAt": now.Add(t.window)},
+	}
+
+	result, err := t.viewsCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		// Duplicate key means a live marker already exists: the view was counted recently
+		if !mongo.IsDuplicateKeyError(err) {
+			log.Printf("Failed to record view for anchor %s: %v", anchor.ID.Hex(), err)
+		}
+		return
+	}
+
+	if result.UpsertedCount == 0 && result.ModifiedCount == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	t.pending[anchor.ID]++
+	t.mu.Unlock()
+}
+
+// Start flushes pending view counts on the given interval until ctx is
+// cancelled or Stop is called
+func (t *ViewTracker) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	done := make(chan struct{})
+	t.mu.Lock()
+	t.done = done
+	t.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				t.Flush(context.Background())
+			case <-ctx.Done():
+				t.Flush(context.Background())
+				return
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the flush loop and writes the counts still pending, so views
+// recorded since the last tick survive a shutdown. It gives up when ctx is done.
+func (t *ViewTracker) Stop(ctx context.Context) {
+	t.stopOnce.Do(func() { close(t.stop) })
+
+	t.mu.Lock()
+	done := t.done
+	t.mu.Unlock()
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	t.Flush(ctx)
+}
+
+// Flush writes all pending view counts to Mongo and refreshes engagement scores
+func (t *ViewTracker) Flush(ctx context.Context) {
+	t.mu.Lock()
+	if len(t.pending) == 0 {
+		t.mu.Unlock()
+		return
+	}
+	batch := t.pending
+	t.pending = make(map[primitive.ObjectID]int)
+	t.mu.Unlock()
+
+	if err := t.repo.IncrementViewCounts(ctx, batch); err != nil {
+		log.Printf("Failed to flush %d anchor view counts: %v", len(batch), err)
+
+		// Put the counts back so they are retried on the next tick
+		t.mu.Lock()
+		for id, n := range batch {
+			t.pending[id] += n
+		}
+		t.mu.Unlock()
+		return
+	}
+
+	for anchorID := range batch {
+		if err := t.repo.UpdateEngagementScore(ctx, anchorID); err != nil {
+			log.Printf("Failed to update engagement score for anchor %s: %v", anchorID.Hex(), err)
+		}
+	}
+}
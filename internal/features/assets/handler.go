@@ -0,0 +1,44 @@
+package assets
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+)
+
+type Handler struct {
+	reconciler *Reconciler
+}
+
+func NewHandler(reconciler *Reconciler) *Handler {
+	return &Handler{reconciler: reconciler}
+}
+
+// GetOrphanReport godoc
+// @Summary Report unused media (admin)
+// @Description Dry run of the media reconciler: lists stored assets older than the grace period that no item, user or anchor cover references, which the reconciler would delete, and deletes that failed and are waiting to be retried. Nothing is deleted.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page of tracked assets to check (default 1)"
+// @Param limit query int false "Assets to check per page (default 100, max 500)"
+// @Success 200 {object} response.APIResponse{data=ReportResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /admin/media/orphans [get]
+func (h *Handler) GetOrphanReport(c *gin.Context) {
+	var query ReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters", "INVALID_QUERY")
+		return
+	}
+
+	report, err := h.reconciler.Report(c.Request.Context(), time.Now(), query.Page, query.Limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to build media report", "DATABASE_ERROR")
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -0,0 +1,55 @@
+package assets
+
+import (
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Asset status constants
+const (
+	StatusActive   = "active"   // stored; checked for references once it is due
+	StatusDeleting = "deleting" // unused; the delete failed and is retried once it is due
+)
+
+// Asset is a file in media storage. The record is created when the file is
+// uploaded and removed once the file has been deleted.
+type Asset struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	PublicID string             `bson:"publicId" json:"publicId"`
+	Kind     storage.Kind       `bson:"kind" json:"kind"`
+	Status   string             `bson:"status" json:"status"`
+	// DueAt is when the reconciler next looks at the asset: its next reference
+	// check while active, or its next delete attempt while deleting
+	DueAt     time.Time  `bson:"dueAt" json:"dueAt"`
+	Attempts  int        `bson:"attempts" json:"attempts"` // failed deletes so far
+	LastError string     `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	CheckedAt *time.Time `bson:"checkedAt,omitempty" json:"checkedAt,omitempty"`
+	CreatedAt time.Time  `bson:"createdAt" json:"createdAt"`
+}
+
+// Request DTOs
+
+type ReportQuery struct {
+	Page  int `form:"page,default=1" binding:"min=1"`
+	Limit int `form:"limit,default=100" binding:"min=1,max=500"`
+}
+
+// Response DTOs
+
+// ReportResponse lists what the reconciler would delete, without deleting it
+type ReportResponse struct {
+	// Orphans are the assets on this page that nothing references
+	Orphans []Asset `json:"orphans"`
+	// Retrying are assets whose delete failed, with the most attempts first
+	Retrying   []Asset `json:"retrying"`
+	Pagination struct {
+		Page       int   `json:"page"`
+		Limit      int   `json:"limit"`
+		Scanned    int   `json:"scanned"` // assets checked for references on this page
+		Total      int64 `json:"total"`   // assets old enough to be collected
+		TotalPages int   `json:"totalPages"`
+		HasMore    bool  `json:"hasMore"`
+	} `json:"pagination"`
+}
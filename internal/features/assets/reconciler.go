@@ -0,0 +1,145 @@
+package assets
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+)
+
+// Reconciler settings
+const (
+	reconcileInterval  = 10 * time.Minute
+	reconcileBatchSize = 100
+	reconcileLease     = 10 * time.Minute   // before an interrupted check is retried
+	recheckInterval    = 7 * 24 * time.Hour // between checks of an asset that is in use
+	retryBackoff       = time.Minute        // doubled after each failed delete
+	maxRetryBackoff    = 24 * time.Hour
+)
+
+// retryDelay is how long to wait before retrying a delete that has failed attempts times
+func retryDelay(attempts int) time.Duration {
+	if attempts > 20 {
+		return maxRetryBackoff
+	}
+	return min(retryBackoff<<(attempts-1), maxRetryBackoff)
+}
+
+// Reconciler deletes tracked assets that nothing references any more, and
+// retries deletes that failed. Every asset is checked again periodically, so
+// assets whose owner was removed without releasing them are still collected.
+type Reconciler struct {
+	repo  *Repository
+	store storage.Storage
+	grace time.Duration
+}
+
+func NewReconciler(repo *Repository, store storage.Storage, grace time.Duration) *Reconciler {
+	return &Reconciler{
+		repo:  repo,
+		store: store,
+		grace: grace,
+	}
+}
+
+// Start reconciles until ctx is cancelled
+func (r *Reconciler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(reconcileInterval)
+		defer ticker.Stop()
+
+		for {
+			if deleted, err := r.Reconcile(ctx, time.Now()); err != nil {
+				log.Printf("Media reconciler error: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Media reconciler deleted %d unused assets", deleted)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Reconcile handles up to one batch of assets that are due and returns how
+// many were deleted. References are checked again before every delete attempt,
+// so an asset that came back into use is kept.
+func (r *Reconciler) Reconcile(ctx context.Context, now time.Time) (int, error) {
+	deleted := 0
+	for i := 0; i < reconcileBatchSize; i++ {
+		asset, err := r.repo.ClaimDue(ctx, now, reconcileLease)
+		if err != nil {
+			return deleted, err
+		}
+		if asset == nil {
+			return deleted, nil
+		}
+
+		referenced, err := r.repo.IsReferenced(ctx, asset.PublicID)
+		if err != nil {
+			return deleted, err
+		}
+		if referenced {
+			if err := r.repo.MarkReferenced(ctx, asset.ID, now, now.Add(recheckInterval)); err != nil {
+				return deleted, err
+			}
+			continue
+		}
+
+		if err := r.store.Delete(ctx, asset.Kind, asset.PublicID); err != nil {
+			attempts := asset.Attempts + 1
+			log.Printf("Failed to delete asset %s (attempt %d): %v", asset.PublicID, attempts, err)
+			if err := r.repo.RecordFailure(ctx, asset.Kind, asset.PublicID, attempts, err, now.Add(retryDelay(attempts))); err != nil {
+				return deleted, err
+			}
+			continue
+		}
+		if err := r.repo.Forget(ctx, asset.PublicID); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// Report lists the assets on one page that a reconcile would delete, and the
+// deletes waiting to be retried, without changing anything
+func (r *Reconciler) Report(ctx context.Context, now time.Time, page, limit int) (*ReportResponse, error) {
+	candidates, total, err := r.repo.ListActive(ctx, now.Add(-r.grace), page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ReportResponse{Orphans: []Asset{}}
+	for _, asset := range candidates {
+		referenced, err := r.repo.IsReferenced(ctx, asset.PublicID)
+		if err != nil {
+			return nil, err
+		}
+		if !referenced {
+			resp.Orphans = append(resp.Orphans, asset)
+		}
+	}
+
+	resp.Retrying, err = r.repo.ListDeleting(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Retrying == nil {
+		resp.Retrying = []Asset{}
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	resp.Pagination.Page = page
+	resp.Pagination.Limit = limit
+	resp.Pagination.Scanned = len(candidates)
+	resp.Pagination.Total = total
+	resp.Pagination.TotalPages = totalPages
+	resp.Pagination.HasMore = page < totalPages
+	return resp, nil
+}
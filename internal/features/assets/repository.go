@@ -0,0 +1,237 @@
+package assets
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Repository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+func NewRepository(db *mongo.Database) *Repository {
+	collection := db.Collection("media_assets")
+
+	_, _ = collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "publicId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Reconciler queue
+			Keys: bson.D{{Key: "dueAt", Value: 1}},
+		},
+		{
+			// Dry-run report
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "createdAt", Value: 1},
+			},
+		},
+	})
+
+	return &Repository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+// Track records a new asset, first due for a reference check at dueAt.
+// Tracking an asset twice keeps the first record.
+func (r *Repository) Track(ctx context.Context, kind storage.Kind, publicID string, dueAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"publicId": publicID},
+		bson.M{"$setOnInsert": bson.M{
+			"kind":      kind,
+			"status":    StatusActive,
+			"dueAt":     dueAt,
+			"attempts":  0,
+			"createdAt": time.Now(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// Release makes an asset due for a reference check at now, unless it is
+// already due sooner. The record is created if the asset was never tracked.
+func (r *Repository) Release(ctx context.Context, kind storage.Kind, publicID string, now time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"publicId": publicID},
+		bson.M{
+			"$min": bson.M{"dueAt": now},
+			"$setOnInsert": bson.M{
+				"kind":      kind,
+				"status":    StatusActive,
+				"attempts":  0,
+				"createdAt": now,
+			},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// Forget removes the record of an asset that has been deleted
+func (r *Repository) Forget(ctx context.Context, publicID string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"publicId": publicID})
+	return err
+}
+
+// RecordFailure marks an asset for deletion after a failed attempt, to be
+// retried at retryAt. The record is created if the asset was never tracked.
+func (r *Repository) RecordFailure(ctx context.Context, kind storage.Kind, publicID string, attempts int, cause error, retryAt time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"publicId": publicID},
+		bson.M{
+			"$set": bson.M{
+				"kind":      kind,
+				"status":    StatusDeleting,
+				"dueAt":     retryAt,
+				"attempts":  attempts,
+				"lastError": cause.Error(),
+			},
+			"$setOnInsert": bson.M{"createdAt": time.Now()},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// MarkReferenced records that an asset is still in use and schedules its next
+// check. An asset that was waiting to be deleted goes back to active.
+func (r *Repository) MarkReferenced(ctx context.Context, id primitive.ObjectID, now, nextCheck time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$set": bson.M{
+				"status":    StatusActive,
+				"dueAt":     nextCheck,
+				"attempts":  0,
+				"checkedAt": now,
+			},
+			"$unset": bson.M{"lastError": ""},
+		},
+	)
+	return err
+}
+
+// ClaimDue claims the asset that has been due the longest, or returns nil when
+// none is due. Its due time is pushed back by lease, so an asset whose check
+// was interrupted is picked up again later and other instances skip it.
+func (r *Repository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*Asset, error) {
+	var asset Asset
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"dueAt": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"dueAt": now.Add(lease)}},
+		options.FindOneAndUpdate().SetSort(bson.D{{Key: "dueAt", Value: 1}}),
+	).Decode(&asset)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// ListActive returns active assets created before createdBefore, oldest first
+func (r *Repository) ListActive(ctx context.Context, createdBefore time.Time, page, limit int) ([]Asset, int64, error) {
+	filter := bson.M{
+		"status":    StatusActive,
+		"createdAt": bson.M{"$lte": createdBefore},
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var assets []Asset
+	if err := cursor.All(ctx, &assets); err != nil {
+		return nil, 0, err
+	}
+	return assets, total, nil
+}
+
+// ListDeleting returns assets whose delete failed, with the most attempts first
+func (r *Repository) ListDeleting(ctx context.Context, limit int) ([]Asset, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "attempts", Value: -1}, {Key: "createdAt", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"status": StatusDeleting}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var assets []Asset
+	if err := cursor.All(ctx, &assets); err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// references are the fields, by collection, that hold the public ID of an
+// asset they use
+var references = []struct {
+	collection string
+	fields     []string
+}{
+	{collection: "items", fields: []string{"imageData.publicId", "audioData.publicId", "fileData.publicId"}},
+	{collection: "users", fields: []string{"profilePicturePublicId", "coverImagePublicId"}},
+	// Direct uploads still waiting to be finalized; expired ones are released by their sweeper
+	{collection: "upload_intents", fields: []string{"publicId"}},
+}
+
+// IsReferenced reports whether any item, user, upload intent or anchor cover
+// still uses the asset
+func (r *Repository) IsReferenced(ctx context.Context, publicID string) (bool, error) {
+	for _, ref := range references {
+		or := make([]bson.M, len(ref.fields))
+		for i, field := range ref.fields {
+			or[i] = bson.M{field: publicID}
+		}
+		n, err := r.db.Collection(ref.collection).CountDocuments(ctx, bson.M{"$or": or}, options.Count().SetLimit(1))
+		if err != nil {
+			return false, err
+		}
+		if n > 0 {
+			return true, nil
+		}
+	}
+
+	// Anchor covers keep only the delivery URL, which contains the public ID
+	n, err := r.db.Collection("anchors").CountDocuments(ctx,
+		bson.M{
+			"coverMediaType":  "image",
+			"coverMediaValue": primitive.Regex{Pattern: regexp.QuoteMeta(publicID)},
+		},
+		options.Count().SetLimit(1),
+	)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,37 @@
+package assets
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/config"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/middleware"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RegisterRoutes starts the media reconciler and registers the admin report route.
+// Features record and release their assets through a Tracker.
+func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config, media storage.Storage) {
+	repo := NewRepository(db)
+	authRepo := auth.NewRepository(db)
+
+	reconciler := NewReconciler(repo, media, time.Duration(cfg.MediaGCGraceHours)*time.Hour)
+	if media != nil {
+		reconciler.Start(context.Background())
+	} else {
+		log.Printf("Media reconciler not started: no media storage configured")
+	}
+
+	handler := NewHandler(reconciler)
+	authMiddleware := middleware.NewAuthMiddleware(authRepo, cfg)
+
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware, middleware.RequireAdmin())
+	{
+		admin.GET("/media/orphans", handler.GetOrphanReport)
+	}
+}
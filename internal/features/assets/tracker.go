@@ -0,0 +1,48 @@
+package assets
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+)
+
+// Tracker records uploaded assets and queues the ones features release for
+// the reconciler to delete.
+type Tracker struct {
+	repo  *Repository
+	grace time.Duration
+}
+
+// NewTracker creates a tracker. Assets are first checked for references once
+// grace has passed, giving uploads time to be attached to something.
+func NewTracker(repo *Repository, grace time.Duration) *Tracker {
+	return &Tracker{
+		repo:  repo,
+		grace: grace,
+	}
+}
+
+// Track records an asset. Errors are logged rather than returned so the upload
+// still succeeds; an untracked asset is just never collected.
+func (t *Tracker) Track(ctx context.Context, kind storage.Kind, publicID string) {
+	if publicID == "" {
+		return
+	}
+	if err := t.repo.Track(context.WithoutCancel(ctx), kind, publicID, time.Now().Add(t.grace)); err != nil {
+		log.Printf("Failed to track asset %s: %v", publicID, err)
+	}
+}
+
+// Release queues an asset its owner no longer uses for the reconciler, which
+// deletes it unless something else still references it. The reference check
+// scans anchor covers, so it is kept off the request path.
+func (t *Tracker) Release(ctx context.Context, kind storage.Kind, publicID string) {
+	if publicID == "" {
+		return
+	}
+	if err := t.repo.Release(context.WithoutCancel(ctx), kind, publicID, time.Now()); err != nil {
+		log.Printf("Failed to queue release of asset %s: %v", publicID, err)
+	}
+}
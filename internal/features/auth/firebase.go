@@ -12,13 +12,22 @@ import (
 	"github.com/xyz-asif/gotodo/internal/config"
 )
 
-// InitFirebase initializes the Firebase Admin SDK and returns the Auth client
-func InitFirebase(cfg *config.Config) (*auth.Client, error) {
+// NewFirebaseApp initializes the Firebase Admin SDK from the service account file
+func NewFirebaseApp(cfg *config.Config) (*firebase.App, error) {
 	opt := option.WithCredentialsFile(cfg.FirebaseServiceAccountPath)
 	app, err := firebase.NewApp(context.Background(), nil, opt)
 	if err != nil {
 		return nil, fmt.Errorf("error initializing firebase app: %v", err)
 	}
+	return app, nil
+}
+
+// InitFirebase initializes the Firebase Admin SDK and returns the Auth client
+func InitFirebase(cfg *config.Config) (*auth.Client, error) {
+	app, err := NewFirebaseApp(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	client, err := app.Auth(context.Background())
 	if err != nil {
@@ -2,7 +2,10 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
 	"strings"
 	"time"
 
@@ -12,9 +15,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/xyz-asif/gotodo/internal/config"
-	"github.com/xyz-asif/gotodo/internal/pkg/cloudinary"
 	idToken "github.com/xyz-asif/gotodo/internal/pkg/jwt"
 	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -26,7 +29,6 @@ type FollowService interface {
 // AnchorService defines the interface for anchor operations to avoid import cycle
 type AnchorService interface {
 	GetPinnedAnchors(ctx context.Context, userID primitive.ObjectID, includePrivate bool) ([]PinnedAnchorData, error)
-	DeleteAllByUser(ctx context.Context, userID primitive.ObjectID) error
 }
 
 // PinnedAnchorData represents anchor data returned from anchor service
@@ -47,17 +49,19 @@ type Handler struct {
 	repo           *Repository
 	firebaseClient *auth.Client
 	config         *config.Config
-	cloudinary     *cloudinary.Service
+	storage        storage.Storage
+	tracker        storage.Tracker
 	followService  FollowService
 	anchorService  AnchorService
 }
 
-func NewHandler(repo *Repository, firebaseClient *auth.Client, cfg *config.Config, cld *cloudinary.Service, followService FollowService, anchorService AnchorService) *Handler {
+func NewHandler(repo *Repository, firebaseClient *auth.Client, cfg *config.Config, store storage.Storage, tracker storage.Tracker, followService FollowService, anchorService AnchorService) *Handler {
 	return &Handler{
 		repo:           repo,
 		firebaseClient: firebaseClient,
 		config:         cfg,
-		cloudinary:     cld,
+		storage:        store,
+		tracker:        tracker,
 		followService:  followService,
 		anchorService:  anchorService,
 	}
@@ -189,6 +193,7 @@ func (h *Handler) getJWTConfig() *idToken.Config {
 func (h *Handler) Login(c *gin.Context) {
 	var req LoginRequest
 =======
+// @Failure 403 {object} response.APIResponse
 // @Router /auth/google [post]
 func (h *Handler) GoogleLogin(c *gin.Context) {
 	var req GoogleAuthRequest
@@ -280,6 +285,15 @@ func (h *Handler) GoogleLogin(c *gin.Context) {
 		}
 	}
 
+	// Deleted accounts can be restored during the grace period
+	if user.IsDeleted() && !h.restoreDeletedAccount(c, user, req.Restore) {
+		return
+	}
+	if user.IsSuspended(time.Now()) {
+		response.Forbidden(c, "Account suspended", "ACCOUNT_SUSPENDED")
+		return
+	}
+
 	// Generate Token Pair
 	jwtConfig := h.getJWTConfig()
 	accessToken, refreshToken, err := idToken.GenerateTokenPair(user.ID.Hex(), user.Email, jwtConfig)
@@ -382,6 +396,14 @@ func (h *Handler) DevLogin(c *gin.Context) {
 		}
 	}
 
+	if user.IsDeleted() && !h.restoreDeletedAccount(c, user, req.Restore) {
+		return
+	}
+	if user.IsSuspended(time.Now()) {
+		response.Forbidden(c, "Account suspended", "ACCOUNT_SUSPENDED")
+		return
+	}
+
 	// Generate Token Pair
 	jwtConfig := h.getJWTConfig()
 	accessToken, refreshToken, err := idToken.GenerateTokenPair(user.ID.Hex(), user.Email, jwtConfig)
@@ -421,6 +443,39 @@ func (h *Handler) DevLogin(c *gin.Context) {
 	})
 }
 
+// restoreDeletedAccount handles a login to an account pending deletion.
+// Without restore it tells the client the account can still be restored;
+// with restore it cancels the deletion. It reports whether the login may continue.
+func (h *Handler) restoreDeletedAccount(c *gin.Context, user *User, restore bool) bool {
+	if !user.CanRestore(time.Now()) {
+		response.Error(c, http.StatusGone, "This account has been deleted", "ACCOUNT_DELETED")
+		return false
+	}
+
+	if !restore {
+		response.Respond(c, http.StatusConflict, false,
+			"This account is scheduled for deletion. Log in with restore set to keep it.",
+			PendingDeletionResponse{DeletedAt: *user.DeletedAt, PurgeAfter: *user.PurgeAfter},
+			"ACCOUNT_PENDING_DELETION")
+		return false
+	}
+
+	restored, err := h.repo.RestoreDeleted(c.Request.Context(), user.ID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to restore account", "DATABASE_ERROR")
+		return false
+	}
+	if !restored {
+		// The grace period ran out while logging in
+		response.Error(c, http.StatusGone, "This account has been deleted", "ACCOUNT_DELETED")
+		return false
+	}
+
+	user.DeletedAt = nil
+	user.PurgeAfter = nil
+	return true
+}
+
 // RefreshToken handles token refresh
 // @Summary Refresh access token
 // @Description Get a new access token using a valid refresh token
@@ -431,6 +486,7 @@ func (h *Handler) DevLogin(c *gin.Context) {
 // @Success 200 {object} response.APIResponse{data=AuthResponse}
 // @Failure 400 {object} response.APIResponse
 // @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
 // @Router /auth/refresh [post]
 func (h *Handler) RefreshToken(c *gin.Context) {
 	var req RefreshTokenRequest
@@ -469,6 +525,20 @@ func (h *Handler) RefreshToken(c *gin.Context) {
 		return
 	}
 
+	// Suspended accounts lose their sessions, as in the auth middleware
+	user, err := h.repo.GetUserByID(c.Request.Context(), claims.UserID)
+	if err != nil {
+		response.Unauthorized(c, "User not found", "USER_NOT_FOUND")
+		return
+	}
+	if user.IsSuspended(time.Now()) {
+		if err := h.repo.RevokeAllUserTokens(c.Request.Context(), user.ID); err != nil {
+			log.Printf("Failed to revoke sessions for suspended user %s: %v", user.ID.Hex(), err)
+		}
+		response.Forbidden(c, "Account suspended", "ACCOUNT_SUSPENDED")
+		return
+	}
+
 	// Generate NEW Access Token
 	jwtConfig := h.getJWTConfig()
 	newAccessToken, err := idToken.GenerateToken(claims.UserID, claims.Email, jwtConfig)
@@ -609,7 +679,7 @@ func (h *Handler) GetPublicProfile(c *gin.Context) {
 	}
 
 	user, err := h.repo.GetUserByObjectID(c.Request.Context(), userID)
-	if err != nil {
+	if err != nil || user.IsDeleted() {
 		response.NotFound(c, "User not found", "USER_NOT_FOUND")
 		return
 	}
@@ -831,6 +901,7 @@ func (h *Handler) UpdateUsername(c *gin.Context) {
 // @Success 200 {object} response.APIResponse{data=ProfilePictureResponse}
 // @Failure 400 {object} response.APIResponse
 // @Failure 401 {object} response.APIResponse
+// @Failure 422 {object} response.APIResponse
 // @Router /users/me/profile-picture [post]
 func (h *Handler) UploadProfilePicture(c *gin.Context) {
 	// Get user
@@ -866,17 +937,25 @@ func (h *Handler) UploadProfilePicture(c *gin.Context) {
 	}
 	defer fileContent.Close()
 
-	// Delete old picture if exists
-	if user.ProfilePicturePublicID != "" {
-		_ = h.cloudinary.Delete(c.Request.Context(), user.ProfilePicturePublicID, "image")
+	// Check the contents and strip metadata such as GPS location
+	content, _, err := storage.Prepare(storage.KindImage, fileContent, file.Filename, file.Size)
+	if err != nil {
+		var invalid *storage.ValidationError
+		if errors.As(err, &invalid) {
+			response.ValidationError(c, invalid.Message, invalid.Code)
+			return
+		}
+		response.InternalServerError(c, "Failed to read file", "FILE_ERROR")
+		return
 	}
 
 	// Upload new picture
-	uploadResult, err := h.cloudinary.UploadImage(c.Request.Context(), fileContent, file.Filename)
+	uploadResult, err := h.storage.Upload(c.Request.Context(), storage.KindImage, content, file.Filename)
 	if err != nil {
 		response.InternalServerError(c, "Failed to upload image", "UPLOAD_FAILED")
 		return
 	}
+	h.tracker.Track(c.Request.Context(), storage.KindImage, uploadResult.PublicID)
 
 	// Update user
 	updates := map[string]interface{}{
@@ -885,10 +964,14 @@ func (h *Handler) UploadProfilePicture(c *gin.Context) {
 	}
 
 	if err := h.repo.UpdateUser(c.Request.Context(), user.ID.Hex(), updates); err != nil {
+		h.tracker.Release(c.Request.Context(), storage.KindImage, uploadResult.PublicID)
 		response.BadRequest(c, "Failed to update user profile", "DATABASE_ERROR")
 		return
 	}
 
+	// The old picture is only removed once the profile no longer points at it
+	h.tracker.Release(c.Request.Context(), storage.KindImage, user.ProfilePicturePublicID)
+
 	response.Success(c, ProfilePictureResponse{
 		ProfilePictureURL: uploadResult.URL,
 	})
@@ -905,6 +988,7 @@ func (h *Handler) UploadProfilePicture(c *gin.Context) {
 // @Success 200 {object} response.APIResponse{data=CoverImageResponse}
 // @Failure 400 {object} response.APIResponse
 // @Failure 401 {object} response.APIResponse
+// @Failure 422 {object} response.APIResponse
 // @Router /users/me/cover-image [post]
 func (h *Handler) UploadCoverImage(c *gin.Context) {
 	// Get user
@@ -940,17 +1024,25 @@ func (h *Handler) UploadCoverImage(c *gin.Context) {
 	}
 	defer fileContent.Close()
 
-	// Delete old cover if exists
-	if user.CoverImagePublicID != "" {
-		_ = h.cloudinary.Delete(c.Request.Context(), user.CoverImagePublicID, "image")
+	// Check the contents and strip metadata such as GPS location
+	content, _, err := storage.Prepare(storage.KindImage, fileContent, file.Filename, file.Size)
+	if err != nil {
+		var invalid *storage.ValidationError
+		if errors.As(err, &invalid) {
+			response.ValidationError(c, invalid.Message, invalid.Code)
+			return
+		}
+		response.InternalServerError(c, "Failed to read file", "FILE_ERROR")
+		return
 	}
 
 	// Upload new cover
-	uploadResult, err := h.cloudinary.UploadImage(c.Request.Context(), fileContent, file.Filename)
+	uploadResult, err := h.storage.Upload(c.Request.Context(), storage.KindImage, content, file.Filename)
 	if err != nil {
 		response.InternalServerError(c, "Failed to upload image", "UPLOAD_FAILED")
 		return
 	}
+	h.tracker.Track(c.Request.Context(), storage.KindImage, uploadResult.PublicID)
 
 	// Update user
 	updates := map[string]interface{}{
@@ -959,10 +1051,14 @@ func (h *Handler) UploadCoverImage(c *gin.Context) {
 	}
 
 	if err := h.repo.UpdateUser(c.Request.Context(), user.ID.Hex(), updates); err != nil {
+		h.tracker.Release(c.Request.Context(), storage.KindImage, uploadResult.PublicID)
 		response.BadRequest(c, "Failed to update user profile", "DATABASE_ERROR")
 		return
 	}
 
+	// The old cover is only removed once the profile no longer points at it
+	h.tracker.Release(c.Request.Context(), storage.KindImage, user.CoverImagePublicID)
+
 	response.Success(c, CoverImageResponse{
 		CoverImageURL: uploadResult.URL,
 	})
@@ -989,10 +1085,6 @@ func (h *Handler) RemoveProfilePicture(c *gin.Context) {
 		return
 	}
 
-	if user.ProfilePicturePublicID != "" {
-		_ = h.cloudinary.Delete(c.Request.Context(), user.ProfilePicturePublicID, "image")
-	}
-
 	updates := map[string]interface{}{
 		"profilePictureUrl":      "",
 		"profilePicturePublicId": "",
@@ -1003,6 +1095,8 @@ func (h *Handler) RemoveProfilePicture(c *gin.Context) {
 		return
 	}
 
+	h.tracker.Release(c.Request.Context(), storage.KindImage, user.ProfilePicturePublicID)
+
 	response.Success(c, "Profile picture removed")
 }
 
@@ -1027,10 +1121,6 @@ func (h *Handler) RemoveCoverImage(c *gin.Context) {
 		return
 	}
 
-	if user.CoverImagePublicID != "" {
-		_ = h.cloudinary.Delete(c.Request.Context(), user.CoverImagePublicID, "image")
-	}
-
 	updates := map[string]interface{}{
 		"coverImageUrl":      "",
 		"coverImagePublicId": "",
@@ -1041,6 +1131,8 @@ func (h *Handler) RemoveCoverImage(c *gin.Context) {
 		return
 	}
 
+	h.tracker.Release(c.Request.Context(), storage.KindImage, user.CoverImagePublicID)
+
 	response.Success(c, "Cover image removed")
 }
 
@@ -1088,8 +1180,8 @@ func (h *Handler) GetPinnedAnchors(c *gin.Context) {
 	}
 
 	// Determine visibility access and user existence
-	_, err = h.repo.GetUserByObjectID(c.Request.Context(), userID)
-	if err != nil {
+	owner, err := h.repo.GetUserByObjectID(c.Request.Context(), userID)
+	if err != nil || owner.IsDeleted() {
 		response.NotFound(c, "User not found", "USER_NOT_FOUND")
 		return
 	}
@@ -1136,13 +1228,13 @@ func (h *Handler) GetPinnedAnchors(c *gin.Context) {
 	response.Success(c, pinnedAnchors)
 }
 
-// DeleteAccount deletes the user's account and all associated data
+// DeleteAccount schedules the user's account for deletion
 // @Summary Delete account
-// @Description Permanently delete the authenticated user's account and all content
+// @Description Delete the authenticated user's account. The account and its content are hidden immediately and every session is ended. Logging in again before purgeAfter restores the account; after that everything is permanently removed by a background job.
 // @Tags users
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} response.APIResponse
+// @Success 202 {object} response.APIResponse{data=DeleteAccountResponse}
 // @Failure 401 {object} response.APIResponse
 // @Failure 500 {object} response.APIResponse
 // @Router /users/me [delete]
@@ -1158,28 +1250,17 @@ func (h *Handler) DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	// 1. Delete all user content (Anchors, Items, Assets) via AnchorService
-	if h.anchorService != nil {
-		if err := h.anchorService.DeleteAllByUser(c.Request.Context(), user.ID); err != nil {
-			fmt.Printf("Failed to delete user content: %v\n", err)
-			response.InternalServerError(c, "Failed to delete user content", "DELETE_FAILED")
-			return
-		}
-	}
-
-	// 2. Delete Profile Picture and Cover Image from Cloudinary
-	if user.ProfilePicturePublicID != "" {
-		_ = h.cloudinary.Delete(c.Request.Context(), user.ProfilePicturePublicID, "image")
-	}
-	if user.CoverImagePublicID != "" {
-		_ = h.cloudinary.Delete(c.Request.Context(), user.CoverImagePublicID, "image")
+	// 1. Hide the account; the purge sweeper removes it once the grace period ends
+	purgeAfter := time.Now().Add(time.Duration(h.config.AccountDeletionGraceDays) * 24 * time.Hour)
+	if err := h.repo.MarkDeleted(c.Request.Context(), user.ID, purgeAfter); err != nil {
+		response.InternalServerError(c, "Failed to delete account", "DATABASE_ERROR")
+		return
 	}
 
-	// 3. Delete User from DB
-	if err := h.repo.DeleteUser(c.Request.Context(), user.ID); err != nil {
-		response.InternalServerError(c, "Failed to delete user", "DATABASE_ERROR")
-		return
+	// 2. End every session
+	if err := h.repo.RevokeAllUserTokens(c.Request.Context(), user.ID); err != nil {
+		fmt.Printf("Failed to revoke sessions for deleted user: %v\n", err)
 	}
 
-	response.Success(c, "Account deleted successfully")
+	response.Respond(c, http.StatusAccepted, true, "Account scheduled for deletion", DeleteAccountResponse{PurgeAfter: purgeAfter})
 }
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// hiddenUsersTTL bounds how long a ban, unban, deletion or restore made by
+// another instance takes to show up. Changes made by this instance invalidate
+// the cache straight away.
+const hiddenUsersTTL = 30 * time.Second
+
+// hiddenUsers caches the IDs of shadow-banned and deleted accounts, so the
+// many read paths that exclude them do not each query for the full set.
+// Deleted accounts only stay in it for their grace period, until they are purged.
+type hiddenUsers struct {
+	collection *mongo.Collection
+
+	mu       sync.Mutex
+	ids      []primitive.ObjectID
+	loadedAt time.Time
+}
+
+var (
+	hiddenUsersMu    sync.Mutex
+	hiddenUsersCache = map[string]*hiddenUsers{}
+)
+
+// sharedHiddenUsers returns the cache for the users collection. Each feature
+// creates its own Repository, so the cache is shared per database.
+func sharedHiddenUsers(collection *mongo.Collection) *hiddenUsers {
+	hiddenUsersMu.Lock()
+	defer hiddenUsersMu.Unlock()
+
+	key := collection.Database().Name()
+	cache, ok := hiddenUsersCache[key]
+	if !ok {
+		cache = &hiddenUsers{collection: collection}
+		hiddenUsersCache[key] = cache
+	}
+	return cache
+}
+
+// get returns the cached IDs, reloading them once they are older than the TTL.
+// If a reload fails the previous set is used rather than showing hidden content.
+func (h *hiddenUsers) get(ctx context.Context) ([]primitive.ObjectID, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.ids != nil && time.Since(h.loadedAt) < hiddenUsersTTL {
+		return h.ids, nil
+	}
+
+	ids, err := h.load(ctx)
+	if err != nil {
+		if h.ids != nil {
+			log.Printf("Failed to reload hidden users, using cached set: %v", err)
+			return h.ids, nil
+		}
+		return nil, err
+	}
+	h.ids = ids
+	h.loadedAt = time.Now()
+	return ids, nil
+}
+
+// invalidate makes the next get reload the set
+func (h *hiddenUsers) invalidate() {
+	h.mu.Lock()
+	h.loadedAt = time.Time{}
+	h.mu.Unlock()
+}
+
+func (h *hiddenUsers) load(ctx context.Context) ([]primitive.ObjectID, error) {
+	cursor, err := h.collection.Find(ctx, bson.M{"$or": []bson.M{
+		{"moderationStatus": ModerationShadowBanned},
+		{"deletedAt": bson.M{"$ne": nil}},
+	}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	return ids, nil
+}
@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"log"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
@@ -43,7 +45,39 @@ func NewAuthMiddleware(repo *Repository, cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// Suspended accounts lose every session so they cannot keep refreshing tokens
+		if user.IsSuspended(time.Now()) {
+			if err := repo.RevokeAllUserTokens(c.Request.Context(), user.ID); err != nil {
+				log.Printf("Failed to revoke sessions for suspended user %s: %v", user.ID.Hex(), err)
+			}
+			response.Forbidden(c, "Account suspended", "ACCOUNT_SUSPENDED")
+			c.Abort()
+			return
+		}
+
 		c.Set("user", user)
 		c.Next()
 	}
 }
+
+// NewOptionalAuthMiddleware sets "user" when the request carries a valid
+// token, and otherwise continues without it. Suspended users are treated as
+// anonymous.
+func NewOptionalAuthMiddleware(repo *Repository, cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.GetHeader("Authorization")
+		if tokenString == "" || len(tokenString) < 8 {
+			c.Next()
+			return
+		}
+		bearerToken := tokenString[7:]
+		claims, err := idToken.ValidateToken(bearerToken, cfg.JWTSecret)
+		if err == nil {
+			user, err := repo.GetUserByID(c.Request.Context(), claims.UserID)
+			if err == nil && !user.IsSuspended(time.Now()) {
+				c.Set("user", user)
+			}
+		}
+		c.Next()
+	}
+}
@@ -34,6 +34,56 @@ type User struct {
 	UpdatedAt              time.Time            `bson:"updatedAt" json:"updatedAt"`
 	Interests              []string             `bson:"interests" json:"interests"`
 	BlockedUsers           []primitive.ObjectID `bson:"blockedUsers" json:"blockedUsers"`
+	Role                   string               `bson:"role,omitempty" json:"role,omitempty"` // "user" (default) or "admin"
+	ModerationStatus       string               `bson:"moderationStatus,omitempty" json:"-"` // "active" (default), "suspended" or "shadow_banned"
+	SuspendedUntil         *time.Time           `bson:"suspendedUntil,omitempty" json:"suspendedUntil,omitempty"`
+	WarningCount           int                  `bson:"warningCount,omitempty" json:"warningCount,omitempty"`
+	DeletedAt              *time.Time           `bson:"deletedAt,omitempty" json:"-"`      // deletion requested; the account is hidden from then on
+	PurgeAfter             *time.Time           `bson:"purgeAfter,omitempty" json:"-"`     // end of the grace period in which the account can be restored
+	PurgeStartedAt         *time.Time           `bson:"purgeStartedAt,omitempty" json:"-"` // set once the purge is queued; no restore after this
+}
+
+// User role constants
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Moderation status constants
+const (
+	ModerationActive       = "active"
+	ModerationSuspended    = "suspended"
+	ModerationShadowBanned = "shadow_banned"
+)
+
+// IsAdmin reports whether the user has the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsSuspended reports whether the account is suspended at the given time.
+// A suspension without an end date lasts until a moderator lifts it.
+func (u *User) IsSuspended(now time.Time) bool {
+	if u.ModerationStatus != ModerationSuspended {
+		return false
+	}
+	return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+}
+
+// IsShadowBanned reports whether the user's content is hidden from everyone else
+func (u *User) IsShadowBanned() bool {
+	return u.ModerationStatus == ModerationShadowBanned
+}
+
+// IsDeleted reports whether the user has deleted their account, whether or
+// not the grace period has run out
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// CanRestore reports whether a deleted account can still be restored at the given time
+func (u *User) CanRestore(now time.Time) bool {
+	return u.DeletedAt != nil && u.PurgeStartedAt == nil && u.PurgeAfter != nil && now.Before(*u.PurgeAfter)
 }
 
 <<<<<<< HEAD
@@ -58,12 +108,14 @@ type RegisterRequest struct {
 // GoogleAuthRequest represents the payload for Google OAuth login
 type GoogleAuthRequest struct {
 	GoogleIDToken string `json:"googleIdToken" binding:"required"`
+	Restore       bool   `json:"restore"` // restore an account pending deletion
 }
 
 // DevLoginRequest for development login (bypasses Google OAuth)
 type DevLoginRequest struct {
 	Email       string `json:"email" binding:"required,email"`
 	DisplayName string `json:"displayName"`
+	Restore     bool   `json:"restore"` // restore an account pending deletion
 }
 
 // RefreshTokenRequest represents the payload for refreshing access token
@@ -167,6 +219,18 @@ type PinnedAnchorResponse struct {
 	CreatedAt       time.Time          `json:"createdAt"`
 }
 
+// DeleteAccountResponse reports when a deleted account's data will be purged
+type DeleteAccountResponse struct {
+	PurgeAfter time.Time `json:"purgeAfter"`
+}
+
+// PendingDeletionResponse is returned when logging in to an account pending
+// deletion without asking to restore it
+type PendingDeletionResponse struct {
+	DeletedAt  time.Time `json:"deletedAt"`
+	PurgeAfter time.Time `json:"purgeAfter"`
+}
+
 // UpdateUsernameRequest represents the payload for updating username
 type UpdateUsernameRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=20"`
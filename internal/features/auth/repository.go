@@ -17,6 +17,7 @@ import (
 type Repository struct {
 	collection              *mongo.Collection
 	refreshTokensCollection *mongo.Collection
+	hiddenUsers             *hiddenUsers
 }
 
 // NewRepository initializes the repository and creates necessary indexes
@@ -52,6 +53,28 @@ func NewRepository(db *mongo.Database) *Repository {
 				}).
 				SetName("user_text_search"),
 		},
+		{
+			// Only moderated accounts carry a status, so the index stays small
+			Keys:    bson.D{{Key: "moderationStatus", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// Hidden deleted accounts
+			Keys:    bson.D{{Key: "deletedAt", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// Deletion sweeper
+			Keys:    bson.D{{Key: "purgeAfter", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+		{
+			// Media reconciler reference checks
+			Keys: bson.D{{Key: "profilePicturePublicId", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "coverImagePublicId", Value: 1}},
+		},
 	})
 
 	refreshTokensCollection := db.Collection("refresh_tokens")
@@ -73,6 +96,7 @@ func NewRepository(db *mongo.Database) *Repository {
 	return &Repository{
 		collection:              collection,
 		refreshTokensCollection: refreshTokensCollection,
+		hiddenUsers:             sharedHiddenUsers(collection),
 	}
 }
 
@@ -137,7 +161,7 @@ func (r *Repository) GetUserByUsername(ctx context.Context, username string) (*U
 	return &user, nil
 }
 
-// GetUserByID finds a user by their MongoDB ID
+// GetUserByID finds a user by their MongoDB ID. Accounts being deleted are not found.
 func (r *Repository) GetUserByID(ctx context.Context, userID string) (*User, error) {
 	oid, err := primitive.ObjectIDFromHex(userID)
 	if err != nil {
@@ -145,7 +169,7 @@ func (r *Repository) GetUserByID(ctx context.Context, userID string) (*User, err
 	}
 
 	var user User
-	err = r.collection.FindOne(ctx, bson.M{"_id": oid}).Decode(&user)
+	err = r.collection.FindOne(ctx, bson.M{"_id": oid, "deletedAt": nil}).Decode(&user)
 	if err != nil {
 		return nil, err // Return error if not found as per requirement
 	}
@@ -267,6 +291,44 @@ func (r *Repository) GetUsersByIDs(ctx context.Context, userIDs []primitive.Obje
 	return users, nil
 }
 
+// GetHiddenUserIDs returns the IDs of users whose content must be hidden from the
+// viewer: shadow-banned users and deleted accounts. A shadow-banned viewer still
+// sees their own content. The set is cached; see hiddenUsers.
+func (r *Repository) GetHiddenUserIDs(ctx context.Context, viewerID *primitive.ObjectID) ([]primitive.ObjectID, error) {
+	ids, err := r.hiddenUsers.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if viewerID == nil || id != *viewerID {
+			hidden = append(hidden, id)
+		}
+	}
+	return hidden, nil
+}
+
+// InvalidateHiddenUsers must be called after a user is shadow-banned or
+// reinstated. Deleting and restoring accounts through the repository
+// invalidates it already; a purged account's content is gone, so it can stay
+// in the set until the next reload.
+func (r *Repository) InvalidateHiddenUsers() {
+	r.hiddenUsers.invalidate()
+}
+
+// IsShadowBanned reports whether the given user is shadow-banned
+func (r *Repository) IsShadowBanned(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"_id":              userID,
+		"moderationStatus": ModerationShadowBanned,
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // GetUserByObjectID finds a user by their ObjectID directly
 func (r *Repository) GetUserByObjectID(ctx context.Context, userID primitive.ObjectID) (*User, error) {
 	var user User
@@ -359,6 +421,102 @@ func (r *Repository) RevokeAllUserTokens(ctx context.Context, userID primitive.O
 	return err
 }
 
+// IsDeleted reports whether the given user has deleted their account
+func (r *Repository) IsDeleted(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"_id":       userID,
+		"deletedAt": bson.M{"$ne": nil},
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// MarkDeleted hides the account and schedules its purge for purgeAfter.
+// Until then the account can be restored.
+func (r *Repository) MarkDeleted(ctx context.Context, userID primitive.ObjectID, purgeAfter time.Time) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": userID, "deletedAt": nil},
+		bson.M{"$set": bson.M{"deletedAt": now, "purgeAfter": purgeAfter, "updatedAt": now}},
+	)
+	r.hiddenUsers.invalidate()
+	return err
+}
+
+// RestoreDeleted cancels a pending deletion. It reports false when the grace
+// period is over or the purge has already been queued.
+func (r *Repository) RestoreDeleted(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	now := time.Now()
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{
+			"_id":            userID,
+			"deletedAt":      bson.M{"$ne": nil},
+			"purgeStartedAt": nil,
+			"purgeAfter":     bson.M{"$gt": now},
+		},
+		bson.M{
+			"$set":   bson.M{"updatedAt": now},
+			"$unset": bson.M{"deletedAt": "", "purgeAfter": ""},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	r.hiddenUsers.invalidate()
+	return result.ModifiedCount > 0, nil
+}
+
+// GetUsersDueForPurge returns deleted accounts whose grace period ended before now
+// and whose purge has not been queued yet
+func (r *Repository) GetUsersDueForPurge(ctx context.Context, now time.Time, limit int) ([]User, error) {
+	cursor, err := r.collection.Find(ctx,
+		bson.M{
+			"purgeAfter": bson.M{"$lte": now},
+			"deletedAt":  bson.M{"$ne": nil},
+		},
+		options.Find().SetSort(bson.D{{Key: "purgeAfter", Value: 1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// MarkPurgeStarted closes the restore window for a deleted account whose grace
+// period has ended. It reports false if the account was restored meanwhile.
+func (r *Repository) MarkPurgeStarted(ctx context.Context, userID primitive.ObjectID, now time.Time) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{
+			"_id":        userID,
+			"deletedAt":  bson.M{"$ne": nil},
+			"purgeAfter": bson.M{"$lte": now},
+		},
+		// $min keeps the first start time when a failed sweep is retried
+		bson.M{"$min": bson.M{"purgeStartedAt": now}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// MarkPurgeQueued takes an account whose purge has been queued off the sweeper's list
+func (r *Repository) MarkPurgeQueued(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": userID, "purgeStartedAt": bson.M{"$ne": nil}},
+		bson.M{"$unset": bson.M{"purgeAfter": ""}},
+	)
+	return err
+}
+
 // DeleteUser permanently removes a user from the database
 func (r *Repository) DeleteUser(ctx context.Context, userID primitive.ObjectID) error {
 	// Also delete all refresh tokens
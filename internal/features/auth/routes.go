@@ -5,32 +5,32 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
-	"github.com/xyz-asif/gotodo/internal/pkg/cloudinary"
-	idToken "github.com/xyz-asif/gotodo/internal/pkg/jwt"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // RegisterRoutes registers the auth routes and initializes dependencies
-// We accept followService and anchorService as interfaces because we can't import those packages due to cycle
-func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config, followService FollowService, anchorService AnchorService) {
+// We accept followService, anchorService and the media tracker as interfaces because we can't import those packages due to cycle
+func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config, followService FollowService, anchorService AnchorService, tracker storage.Tracker) {
 	// Init Firebase
 	firebaseClient, err := InitFirebase(cfg)
 	if err != nil {
 		log.Fatalf("Failed to initialize Firebase: %v", err)
 	}
 
-	// Initialize Cloudinary service
-	cloudinarySvc, err := cloudinary.NewService(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret, "profiles")
+	// Initialize media storage
+	store, err := storage.New(cfg, "profiles")
 	if err != nil {
-		log.Printf("Failed to initialize cloudinary service for auth: %v", err)
+		log.Printf("Failed to initialize media storage for auth: %v", err)
 	}
 
 	// Init dependencies
 	repo := NewRepository(db)
 
 	// Use the passed services
-	handler := NewHandler(repo, firebaseClient, cfg, cloudinarySvc, followService, anchorService)
+	handler := NewHandler(repo, firebaseClient, cfg, store, tracker, followService, anchorService)
 	authMiddleware := NewAuthMiddleware(repo, cfg)
+	optionalAuth := NewOptionalAuthMiddleware(repo, cfg)
 
 	// Auth routes
 	auth := router.Group("/auth")
@@ -64,48 +64,8 @@ func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Con
 			me.DELETE("/cover-image", handler.RemoveCoverImage)
 		}
 
-		// Public profile routes (after /me)
-		users.GET("/:id", func(c *gin.Context) {
-			// Manually run auth check without aborting
-			tokenString := c.GetHeader("Authorization")
-			if tokenString == "" || len(tokenString) < 8 {
-				c.Next()
-				return
-			}
-			bearerToken := tokenString[7:]
-			claims, err := idToken.ValidateToken(bearerToken, cfg.JWTSecret)
-			if err == nil {
-				user, err := repo.GetUserByID(c.Request.Context(), claims.UserID)
-				if err == nil {
-					c.Set("user", user)
-				}
-			}
-			c.Next()
-		}, handler.GetPublicProfile)
-
-		// Pinned anchors route
-		// Note: We use the same manual auth middleware for optional auth here too if needed,
-		// but GetPinnedAnchors logic handles "user" context if present.
-		// However, handler.go logic currently uses `c.Get("user")` directly.
-		// If we want it to work for unauthenticated users (public view), we need optional auth.
-		// Reuse the inline middleware logic
-		optionalAuth := func(c *gin.Context) {
-			tokenString := c.GetHeader("Authorization")
-			if tokenString == "" || len(tokenString) < 8 {
-				c.Next()
-				return
-			}
-			bearerToken := tokenString[7:]
-			claims, err := idToken.ValidateToken(bearerToken, cfg.JWTSecret)
-			if err == nil {
-				user, err := repo.GetUserByID(c.Request.Context(), claims.UserID)
-				if err == nil {
-					c.Set("user", user)
-				}
-			}
-			c.Next()
-		}
-
+		// Public profile routes (after /me); the viewer is optional
+		users.GET("/:id", optionalAuth, handler.GetPublicProfile)
 		users.GET("/:id/pinned", optionalAuth, handler.GetPinnedAnchors)
 	}
 }
@@ -0,0 +1,571 @@
+package bookmarks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/features/anchors"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/features/feed"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Handler struct {
+	repo        *Repository
+	anchorsRepo *anchors.Repository
+	authRepo    *auth.Repository
+}
+
+func NewHandler(repo *Repository, anchorsRepo *anchors.Repository, authRepo *auth.Repository) *Handler {
+	return &Handler{
+		repo:        repo,
+		anchorsRepo: anchorsRepo,
+		authRepo:    authRepo,
+	}
+}
+
+// SaveAnchor godoc
+// @Summary Save anchor
+// @Description Save an anchor to the current user's private saved list, optionally into a folder. Saving an already saved anchor moves it between folders.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Param request body SaveAnchorRequest false "Folder"
+// @Success 200 {object} response.APIResponse{data=SaveStatusResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/save [post]
+func (h *Handler) SaveAnchor(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	anchorID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid anchor ID", "INVALID_ID")
+		return
+	}
+
+	// Body is optional
+	var req SaveAnchorRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request format", "INVALID_JSON")
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+
+	anchor, err := h.anchorsRepo.GetAnchorByID(ctx, anchorID)
+	if err != nil || anchor == nil || anchor.DeletedAt != nil {
+		response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
+		return
+	}
+
+	if !anchor.CanBeViewed(currentUser.ID) {
+		response.Forbidden(c, "Cannot save private anchor", "ACCESS_DENIED")
+		return
+	}
+
+	if !anchor.IsOwnedBy(currentUser.ID) {
+		if containsID(currentUser.BlockedUsers, anchor.UserID) {
+			response.Forbidden(c, "Cannot save an anchor by a user you blocked", "USER_BLOCKED")
+			return
+		}
+
+		// Authors who blocked the user, or who are hidden, look like the anchor is gone
+		author, err := h.authRepo.GetUserByObjectID(ctx, anchor.UserID)
+		if err != nil || author == nil || !canSeeAuthor(currentUser, author) {
+			response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
+			return
+		}
+	}
+
+	var folderID *primitive.ObjectID
+	setFolder := req.FolderID != nil
+	if setFolder && *req.FolderID != "" {
+		id, err := primitive.ObjectIDFromHex(*req.FolderID)
+		if err != nil {
+			response.BadRequest(c, "Invalid folder ID", "INVALID_ID")
+			return
+		}
+		if _, err := h.repo.GetFolder(ctx, currentUser.ID, id); err != nil {
+			h.respondFolderError(c, err)
+			return
+		}
+		folderID = &id
+	}
+
+	bookmark, err := h.repo.SaveAnchor(ctx, currentUser.ID, anchorID, folderID, setFolder)
+	if err != nil {
+		response.InternalServerError(c, "Failed to save anchor", "DATABASE_ERROR")
+		return
+	}
+
+	response.Success(c, SaveStatusResponse{
+		IsSaved:  true,
+		FolderID: bookmark.FolderID,
+		SavedAt:  &bookmark.CreatedAt,
+	})
+}
+
+// UnsaveAnchor godoc
+// @Summary Unsave anchor
+// @Description Remove an anchor from the current user's saved list (idempotent). Works for anchors that have become unavailable.
+// @Tags bookmarks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Success 200 {object} response.APIResponse{data=SaveStatusResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /anchors/{id}/save [delete]
+func (h *Handler) UnsaveAnchor(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	anchorID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid anchor ID", "INVALID_ID")
+		return
+	}
+
+	if err := h.repo.DeleteBookmark(c.Request.Context(), currentUser.ID, anchorID); err != nil {
+		response.InternalServerError(c, "Failed to unsave anchor", "DATABASE_ERROR")
+		return
+	}
+
+	response.Success(c, SaveStatusResponse{IsSaved: false})
+}
+
+// GetSaveStatus godoc
+// @Summary Get save status
+// @Description Check whether the current user has saved an anchor, and in which folder
+// @Tags bookmarks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Success 200 {object} response.APIResponse{data=SaveStatusResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /anchors/{id}/save [get]
+func (h *Handler) GetSaveStatus(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	anchorID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid anchor ID", "INVALID_ID")
+		return
+	}
+
+	bookmark, err := h.repo.GetBookmark(c.Request.Context(), currentUser.ID, anchorID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch save status", "DATABASE_ERROR")
+		return
+	}
+
+	resp := SaveStatusResponse{}
+	if bookmark != nil {
+		resp.IsSaved = true
+		resp.FolderID = bookmark.FolderID
+		resp.SavedAt = &bookmark.CreatedAt
+	}
+
+	response.Success(c, resp)
+}
+
+// ListSaved godoc
+// @Summary List saved anchors
+// @Description Get the current user's saved anchors, newest first, with cursor pagination. Anchors that were deleted, made private or hidden from the user stay in the list with status "unavailable" and no anchor details.
+// @Tags bookmarks
+// @Produce json
+// @Security BearerAuth
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Items per page (default 20, max 50)"
+// @Param folderId query string false "Folder ID, or 'none' for saves not in a folder"
+// @Success 200 {object} response.APIResponse{data=SavedListResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /users/me/saved [get]
+func (h *Handler) ListSaved(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	var query SavedListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, err.Error(), "INVALID_QUERY")
+		return
+	}
+
+	cursor, err := feed.DecodeCursor(query.Cursor)
+	if err != nil {
+		response.BadRequest(c, err.Error(), "INVALID_CURSOR")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var folderID *primitive.ObjectID
+	unfiled := query.FolderID == FolderNone
+	if query.FolderID != "" && !unfiled {
+		id, err := primitive.ObjectIDFromHex(query.FolderID)
+		if err != nil {
+			response.BadRequest(c, "Invalid folder ID", "INVALID_ID")
+			return
+		}
+		if _, err := h.repo.GetFolder(ctx, currentUser.ID, id); err != nil {
+			h.respondFolderError(c, err)
+			return
+		}
+		folderID = &id
+	}
+
+	bookmarks, err := h.repo.ListBookmarks(ctx, currentUser.ID, folderID, unfiled, cursor, query.Limit+1)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch saved anchors", "DATABASE_ERROR")
+		return
+	}
+
+	hasMore := len(bookmarks) > query.Limit
+	if hasMore {
+		bookmarks = bookmarks[:query.Limit]
+	}
+
+	items, err := h.buildSavedItems(ctx, currentUser, bookmarks)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch saved anchors", "DATABASE_ERROR")
+		return
+	}
+
+	var nextCursor *string
+	if hasMore {
+		last := bookmarks[len(bookmarks)-1]
+		encoded := feed.EncodeCursor(last.CreatedAt, last.AnchorID)
+		nextCursor = &encoded
+	}
+
+	response.Success(c, SavedListResponse{
+		Items: items,
+		Pagination: SavedPagination{
+			Limit:      query.Limit,
+			HasMore:    hasMore,
+			NextCursor: nextCursor,
+		},
+	})
+}
+
+// ListFolders godoc
+// @Summary List saved folders
+// @Description Get the current user's private folders for saved anchors, by name
+// @Tags bookmarks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=[]FolderResponse}
+// @Failure 401 {object} response.APIResponse
+// @Router /users/me/saved/folders [get]
+func (h *Handler) ListFolders(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	folders, err := h.repo.ListFolders(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch folders", "DATABASE_ERROR")
+		return
+	}
+
+	counts, err := h.repo.CountByFolder(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch folders", "DATABASE_ERROR")
+		return
+	}
+
+	resp := make([]FolderResponse, len(folders))
+	for i := range folders {
+		resp[i] = toFolderResponse(&folders[i], counts[folders[i].ID])
+	}
+
+	response.Success(c, resp)
+}
+
+// CreateFolder godoc
+// @Summary Create saved folder
+// @Description Create a private folder for saved anchors. Names are unique per user, ignoring case.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body FolderRequest true "Folder name"
+// @Success 201 {object} response.APIResponse{data=FolderResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /users/me/saved/folders [post]
+func (h *Handler) CreateFolder(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	var req FolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error(), "INVALID_REQUEST")
+		return
+	}
+
+	if err := ValidateFolderRequest(&req); err != nil {
+		response.BadRequest(c, err.Error(), "INVALID_NAME")
+		return
+	}
+
+	folder := &Folder{
+		UserID: currentUser.ID,
+		Name:   req.Name,
+	}
+	if err := h.repo.CreateFolder(c.Request.Context(), folder); err != nil {
+		h.respondFolderError(c, err)
+		return
+	}
+
+	response.Created(c, toFolderResponse(folder, 0))
+}
+
+// RenameFolder godoc
+// @Summary Rename saved folder
+// @Description Rename one of the current user's folders
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param folderId path string true "Folder ID"
+// @Param request body FolderRequest true "Folder name"
+// @Success 200 {object} response.APIResponse{data=FolderResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /users/me/saved/folders/{folderId} [patch]
+func (h *Handler) RenameFolder(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	folderID, err := primitive.ObjectIDFromHex(c.Param("folderId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid folder ID", "INVALID_ID")
+		return
+	}
+
+	var req FolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error(), "INVALID_REQUEST")
+		return
+	}
+
+	if err := ValidateFolderRequest(&req); err != nil {
+		response.BadRequest(c, err.Error(), "INVALID_NAME")
+		return
+	}
+
+	folder, err := h.repo.RenameFolder(c.Request.Context(), currentUser.ID, folderID, req.Name)
+	if err != nil {
+		h.respondFolderError(c, err)
+		return
+	}
+
+	counts, _ := h.repo.CountByFolder(c.Request.Context(), currentUser.ID)
+
+	response.Success(c, toFolderResponse(folder, counts[folder.ID]))
+}
+
+// DeleteFolder godoc
+// @Summary Delete saved folder
+// @Description Delete one of the current user's folders. The anchors in it stay saved, outside any folder.
+// @Tags bookmarks
+// @Produce json
+// @Security BearerAuth
+// @Param folderId path string true "Folder ID"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /users/me/saved/folders/{folderId} [delete]
+func (h *Handler) DeleteFolder(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	folderID, err := primitive.ObjectIDFromHex(c.Param("folderId"))
+	if err != nil {
+		response.BadRequest(c, "Invalid folder ID", "INVALID_ID")
+		return
+	}
+
+	if err := h.repo.DeleteFolder(c.Request.Context(), currentUser.ID, folderID); err != nil {
+		h.respondFolderError(c, err)
+		return
+	}
+
+	response.Success(c, nil, "Folder deleted")
+}
+
+// Helper methods
+
+// buildSavedItems resolves saves to anchors, marking the ones the user can no
+// longer see as unavailable
+func (h *Handler) buildSavedItems(ctx context.Context, currentUser *auth.User, bookmarks []Bookmark) ([]SavedItem, error) {
+	items := make([]SavedItem, 0, len(bookmarks))
+	if len(bookmarks) == 0 {
+		return items, nil
+	}
+
+	anchorIDs := make([]primitive.ObjectID, len(bookmarks))
+	for i, b := range bookmarks {
+		anchorIDs[i] = b.AnchorID
+	}
+
+	// Deleted anchors are left out, so they end up unavailable
+	anchorsList, err := h.anchorsRepo.GetAnchorsByIDs(ctx, anchorIDs)
+	if err != nil {
+		return nil, err
+	}
+	anchorsMap := make(map[primitive.ObjectID]*anchors.Anchor, len(anchorsList))
+	authorIDs := make([]primitive.ObjectID, 0, len(anchorsList))
+	for i := range anchorsList {
+		anchorsMap[anchorsList[i].ID] = &anchorsList[i]
+		authorIDs = append(authorIDs, anchorsList[i].UserID)
+	}
+
+	authors, err := h.authRepo.GetUsersByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, err
+	}
+	authorsMap := make(map[primitive.ObjectID]*auth.User, len(authors))
+	for i := range authors {
+		authorsMap[authors[i].ID] = &authors[i]
+	}
+
+	for _, b := range bookmarks {
+		item := SavedItem{
+			AnchorID: b.AnchorID,
+			FolderID: b.FolderID,
+			SavedAt:  b.CreatedAt,
+			Status:   StatusUnavailable,
+		}
+
+		anchor := anchorsMap[b.AnchorID]
+		if anchor != nil && anchor.CanBeViewed(currentUser.ID) {
+			author := authorsMap[anchor.UserID]
+			if author != nil && (anchor.IsOwnedBy(currentUser.ID) ||
+				(!containsID(currentUser.BlockedUsers, author.ID) && canSeeAuthor(currentUser, author))) {
+				item.Status = StatusAvailable
+				item.Anchor = toSavedAnchor(anchor, author)
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (h *Handler) respondFolderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrFolderNotFound):
+		response.NotFound(c, "Folder not found", "FOLDER_NOT_FOUND")
+	case errors.Is(err, ErrFolderExists):
+		response.Conflict(c, "A folder with this name already exists", "FOLDER_EXISTS")
+	case errors.Is(err, ErrTooManyFolders):
+		response.BadRequest(c, "Folder limit reached", "TOO_MANY_FOLDERS")
+	default:
+		response.InternalServerError(c, "Failed to update folders", "DATABASE_ERROR")
+	}
+}
+
+// canSeeAuthor reports whether the author's anchors are visible to the user:
+// the author hasn't blocked them and isn't deleted or shadow-banned
+func canSeeAuthor(user *auth.User, author *auth.User) bool {
+	if author.ID == user.ID {
+		return true
+	}
+	return !author.IsDeleted() && !author.IsShadowBanned() && !containsID(author.BlockedUsers, user.ID)
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func toSavedAnchor(anchor *anchors.Anchor, author *auth.User) *SavedAnchor {
+	var profilePic *string
+	if author.ProfilePictureURL != "" {
+		profilePic = &author.ProfilePictureURL
+	}
+
+	return &SavedAnchor{
+		ID:              anchor.ID,
+		Title:           anchor.Title,
+		Description:     anchor.Description,
+		CoverMediaType:  anchor.CoverMediaType,
+		CoverMediaValue: anchor.CoverMediaValue,
+		Visibility:      anchor.Visibility,
+		ItemCount:       anchor.ItemCount,
+		LikeCount:       anchor.LikeCount,
+		UpdatedAt:       anchor.UpdatedAt,
+		Author: SavedAnchorAuthor{
+			ID:             author.ID,
+			Username:       author.Username,
+			DisplayName:    author.DisplayName,
+			ProfilePicture: profilePic,
+		},
+	}
+}
+
+func toFolderResponse(folder *Folder, savedCount int) FolderResponse {
+	return FolderResponse{
+		ID:         folder.ID,
+		Name:       folder.Name,
+		SavedCount: savedCount,
+		CreatedAt:  folder.CreatedAt,
+		UpdatedAt:  folder.UpdatedAt,
+	}
+}
@@ -0,0 +1,116 @@
+package bookmarks
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Saved item status constants
+const (
+	StatusAvailable   = "available"
+	StatusUnavailable = "unavailable" // deleted, made private, or hidden from the user
+)
+
+// FolderNone lists only saves that are not in a folder
+const FolderNone = "none"
+
+// MaxFoldersPerUser limits how many folders a user can create
+const MaxFoldersPerUser = 100
+
+// Bookmark is an anchor a user saved for later. Saves are only visible to the user.
+type Bookmark struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID  `bson:"userId" json:"userId"`
+	AnchorID  primitive.ObjectID  `bson:"anchorId" json:"anchorId"`
+	FolderID  *primitive.ObjectID `bson:"folderId,omitempty" json:"folderId,omitempty"`
+	CreatedAt time.Time           `bson:"createdAt" json:"createdAt"`
+}
+
+// Folder is a private group of a user's saved anchors
+type Folder struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	Name      string             `bson:"name" json:"name"`
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Request DTOs
+
+// SaveAnchorRequest for POST /anchors/:id/save. The body is optional.
+type SaveAnchorRequest struct {
+	// Folder to save into. Omitted keeps the current folder (none for a new
+	// save); an empty string takes the save out of its folder.
+	FolderID *string `json:"folderId"`
+}
+
+// FolderRequest for creating and renaming folders
+type FolderRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=50"`
+}
+
+// SavedListQuery for GET /users/me/saved
+type SavedListQuery struct {
+	Cursor   string `form:"cursor"`
+	Limit    int    `form:"limit,default=20" binding:"min=1,max=50"`
+	FolderID string `form:"folderId"` // folder ID, "none" for unfiled saves, or empty for all
+}
+
+// Response DTOs
+
+// SaveStatusResponse after save/unsave
+type SaveStatusResponse struct {
+	IsSaved  bool                `json:"isSaved"`
+	FolderID *primitive.ObjectID `json:"folderId"`
+	SavedAt  *time.Time          `json:"savedAt,omitempty"`
+}
+
+type SavedAnchorAuthor struct {
+	ID             primitive.ObjectID `json:"id"`
+	Username       string             `json:"username"`
+	DisplayName    string             `json:"displayName"`
+	ProfilePicture *string            `json:"profilePicture"`
+}
+
+type SavedAnchor struct {
+	ID              primitive.ObjectID `json:"id"`
+	Title           string             `json:"title"`
+	Description     string             `json:"description"`
+	CoverMediaType  string             `json:"coverMediaType"`
+	CoverMediaValue string             `json:"coverMediaValue"`
+	Visibility      string             `json:"visibility"`
+	ItemCount       int                `json:"itemCount"`
+	LikeCount       int                `json:"likeCount"`
+	UpdatedAt       time.Time          `json:"updatedAt"`
+	Author          SavedAnchorAuthor  `json:"author"`
+}
+
+// SavedItem is one save; Anchor is null when the anchor is unavailable
+type SavedItem struct {
+	AnchorID primitive.ObjectID  `json:"anchorId"`
+	FolderID *primitive.ObjectID `json:"folderId"`
+	SavedAt  time.Time           `json:"savedAt"`
+	Status   string              `json:"status"`
+	Anchor   *SavedAnchor        `json:"anchor"`
+}
+
+type SavedPagination struct {
+	Limit      int     `json:"limit"`
+	HasMore    bool    `json:"hasMore"`
+	NextCursor *string `json:"nextCursor"`
+}
+
+type SavedListResponse struct {
+	Items      []SavedItem     `json:"items"`
+	Pagination SavedPagination `json:"pagination"`
+}
+
+// FolderResponse is a folder with how many saves it holds
+type FolderResponse struct {
+	ID         primitive.ObjectID `json:"id"`
+	Name       string             `json:"name"`
+	SavedCount int                `json:"savedCount"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	UpdatedAt  time.Time          `json:"updatedAt"`
+}
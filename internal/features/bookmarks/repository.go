@@ -0,0 +1,288 @@
+package bookmarks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/features/feed"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrFolderNotFound = errors.New("folder not found")
+	ErrFolderExists   = errors.New("folder already exists")
+	ErrTooManyFolders = errors.New("too many folders")
+)
+
+type Repository struct {
+	bookmarksCollection *mongo.Collection
+	foldersCollection   *mongo.Collection
+}
+
+func NewRepository(db *mongo.Database) *Repository {
+	bookmarksCollection := db.Collection("bookmarks")
+	foldersCollection := db.Collection("bookmark_folders")
+
+	// Create indexes
+	bookmarksCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			// One save per user per anchor
+			Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "anchorId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Saved list, newest first, anchorId breaking ties for the cursor
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "createdAt", Value: -1},
+				{Key: "anchorId", Value: -1},
+			},
+		},
+		{
+			// Saved list filtered by folder
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "folderId", Value: 1},
+				{Key: "createdAt", Value: -1},
+				{Key: "anchorId", Value: -1},
+			},
+		},
+	})
+
+	foldersCollection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		// Folder names are unique per user, ignoring case
+		Keys: bson.D{{Key: "userId", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetCollation(&options.Collation{Locale: "en", Strength: 2}),
+	})
+
+	return &Repository{
+		bookmarksCollection: bookmarksCollection,
+		foldersCollection:   foldersCollection,
+	}
+}
+
+// SaveAnchor saves the anchor for the user, or updates an existing save. When
+// setFolder is false an existing save keeps its folder; otherwise the save is
+// moved to folderID, or out of any folder if it is nil.
+func (r *Repository) SaveAnchor(ctx context.Context, userID, anchorID primitive.ObjectID, folderID *primitive.ObjectID, setFolder bool) (*Bookmark, error) {
+	filter := bson.M{"userId": userID, "anchorId": anchorID}
+	update := bson.M{
+		"$setOnInsert": bson.M{"createdAt": time.Now()},
+	}
+	if setFolder {
+		if folderID != nil {
+			update["$set"] = bson.M{"folderId": *folderID}
+		} else {
+			update["$unset"] = bson.M{"folderId": ""}
+		}
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var bookmark Bookmark
+	err := r.bookmarksCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&bookmark)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent save inserted first; now this one updates it
+		err = r.bookmarksCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&bookmark)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &bookmark, nil
+}
+
+// DeleteBookmark removes a save (idempotent)
+func (r *Repository) DeleteBookmark(ctx context.Context, userID, anchorID primitive.ObjectID) error {
+	_, err := r.bookmarksCollection.DeleteOne(ctx, bson.M{
+		"userId":   userID,
+		"anchorId": anchorID,
+	})
+	return err
+}
+
+// GetBookmark returns the user's save of the anchor, or nil if not saved
+func (r *Repository) GetBookmark(ctx context.Context, userID, anchorID primitive.ObjectID) (*Bookmark, error) {
+	var bookmark Bookmark
+	err := r.bookmarksCollection.FindOne(ctx, bson.M{
+		"userId":   userID,
+		"anchorId": anchorID,
+	}).Decode(&bookmark)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &bookmark, nil
+}
+
+// ListBookmarks returns a page of the user's saves, newest first. folderID
+// limits the list to one folder; unfiled to saves without a folder.
+func (r *Repository) ListBookmarks(ctx context.Context, userID primitive.ObjectID, folderID *primitive.ObjectID, unfiled bool, cursor *feed.FeedCursor, limit int) ([]Bookmark, error) {
+	filter := bson.M{"userId": userID}
+	if folderID != nil {
+		filter["folderId"] = *folderID
+	} else if unfiled {
+		filter["folderId"] = nil
+	}
+
+	if cursor != nil {
+		filter["$or"] = []bson.M{
+			{"createdAt": bson.M{"$lt": cursor.Timestamp}},
+			{"createdAt": cursor.Timestamp, "anchorId": bson.M{"$lt": cursor.AnchorID}},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "anchorId", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cur, err := r.bookmarksCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var bookmarks []Bookmark
+	if err = cur.All(ctx, &bookmarks); err != nil {
+		return nil, err
+	}
+
+	return bookmarks, nil
+}
+
+// CreateFolder creates a folder, failing with ErrFolderExists if the user
+// already has one with the same name, or ErrTooManyFolders past the limit
+func (r *Repository) CreateFolder(ctx context.Context, folder *Folder) error {
+	count, err := r.foldersCollection.CountDocuments(ctx, bson.M{"userId": folder.UserID})
+	if err != nil {
+		return err
+	}
+	if count >= MaxFoldersPerUser {
+		return ErrTooManyFolders
+	}
+
+	folder.ID = primitive.NewObjectID()
+	folder.CreatedAt = time.Now()
+	folder.UpdatedAt = folder.CreatedAt
+
+	if _, err := r.foldersCollection.InsertOne(ctx, folder); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrFolderExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// GetFolder returns one of the user's folders
+func (r *Repository) GetFolder(ctx context.Context, userID, folderID primitive.ObjectID) (*Folder, error) {
+	var folder Folder
+	err := r.foldersCollection.FindOne(ctx, bson.M{
+		"_id":    folderID,
+		"userId": userID,
+	}).Decode(&folder)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrFolderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &folder, nil
+}
+
+// ListFolders returns the user's folders by name
+func (r *Repository) ListFolders(ctx context.Context, userID primitive.ObjectID) ([]Folder, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "name", Value: 1}}).
+		SetCollation(&options.Collation{Locale: "en", Strength: 2})
+
+	cursor, err := r.foldersCollection.Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	folders := []Folder{}
+	if err = cursor.All(ctx, &folders); err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+// CountByFolder returns how many saves each of the user's folders holds
+func (r *Repository) CountByFolder(ctx context.Context, userID primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"userId": userID, "folderId": bson.M{"$ne": nil}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$folderId", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.bookmarksCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int                `bson:"count"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[primitive.ObjectID]int, len(groups))
+	for _, g := range groups {
+		counts[g.ID] = g.Count
+	}
+
+	return counts, nil
+}
+
+// RenameFolder renames one of the user's folders
+func (r *Repository) RenameFolder(ctx context.Context, userID, folderID primitive.ObjectID, name string) (*Folder, error) {
+	var folder Folder
+	err := r.foldersCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": folderID, "userId": userID},
+		bson.M{"$set": bson.M{"name": name, "updatedAt": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&folder)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, ErrFolderExists
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrFolderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &folder, nil
+}
+
+// DeleteFolder deletes one of the user's folders. Its saves are kept, unfiled.
+func (r *Repository) DeleteFolder(ctx context.Context, userID, folderID primitive.ObjectID) error {
+	result, err := r.foldersCollection.DeleteOne(ctx, bson.M{"_id": folderID, "userId": userID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrFolderNotFound
+	}
+
+	_, err = r.bookmarksCollection.UpdateMany(ctx,
+		bson.M{"userId": userID, "folderId": folderID},
+		bson.M{"$unset": bson.M{"folderId": ""}},
+	)
+	return err
+}
@@ -0,0 +1,40 @@
+package bookmarks
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/config"
+	"github.com/xyz-asif/gotodo/internal/features/anchors"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/middleware"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config) {
+	repo := NewRepository(db)
+	authRepo := auth.NewRepository(db)
+	anchorsRepo := anchors.NewRepository(db)
+
+	handler := NewHandler(repo, anchorsRepo, authRepo)
+
+	authMiddleware := middleware.NewAuthMiddleware(authRepo, cfg)
+
+	// Saving anchors
+	anchorRoutes := router.Group("/anchors/:id")
+	anchorRoutes.Use(authMiddleware)
+	{
+		anchorRoutes.GET("/save", handler.GetSaveStatus)
+		anchorRoutes.POST("/save", handler.SaveAnchor)
+		anchorRoutes.DELETE("/save", handler.UnsaveAnchor)
+	}
+
+	// The user's saved anchors and folders
+	saved := router.Group("/users/me/saved")
+	saved.Use(authMiddleware)
+	{
+		saved.GET("", handler.ListSaved)
+		saved.GET("/folders", handler.ListFolders)
+		saved.POST("/folders", handler.CreateFolder)
+		saved.PATCH("/folders/:folderId", handler.RenameFolder)
+		saved.DELETE("/folders/:folderId", handler.DeleteFolder)
+	}
+}
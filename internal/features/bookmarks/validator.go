@@ -0,0 +1,18 @@
+package bookmarks
+
+import (
+	"errors"
+	"strings"
+)
+
+// ValidateFolderRequest trims the folder name and checks it isn't blank
+func ValidateFolderRequest(req *FolderRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("folder name cannot be empty")
+	}
+	if len([]rune(req.Name)) > 50 {
+		return errors.New("folder name must be at most 50 characters")
+	}
+	return nil
+}
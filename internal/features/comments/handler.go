@@ -2,12 +2,15 @@ package comments
 
 import (
 	"context"
+	"log"
 	"math"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
 	"github.com/xyz-asif/gotodo/internal/features/anchors"
 	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/features/follows"
+	"github.com/xyz-asif/gotodo/internal/features/likes"
 	"github.com/xyz-asif/gotodo/internal/features/notifications"
 	"github.com/xyz-asif/gotodo/internal/pkg/response"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -17,15 +20,17 @@ type Handler struct {
 	repo                *Repository
 	authRepo            *auth.Repository
 	anchorsRepo         *anchors.Repository
+	followsRepo         *follows.Repository
 	notificationService *notifications.Service
 	config              *config.Config
 }
 
-func NewHandler(repo *Repository, authRepo *auth.Repository, anchorsRepo *anchors.Repository, notificationService *notifications.Service, cfg *config.Config) *Handler {
+func NewHandler(repo *Repository, authRepo *auth.Repository, anchorsRepo *anchors.Repository, followsRepo *follows.Repository, notificationService *notifications.Service, cfg *config.Config) *Handler {
 	return &Handler{
 		repo:                repo,
 		authRepo:            authRepo,
 		anchorsRepo:         anchorsRepo,
+		followsRepo:         followsRepo,
 		notificationService: notificationService,
 		config:              cfg,
 	}
@@ -33,7 +38,7 @@ func NewHandler(repo *Repository, authRepo *auth.Repository, anchorsRepo *anchor
 
 // AddComment godoc
 // @Summary Add comment to anchor
-// @Description Add a new comment with @mention support
+// @Description Add a new comment with @mention support. Comments matching the anchor owner's keyword filter are held for approval and returned with status "held".
 // @Tags comments
 // @Accept json
 // @Produce json
@@ -88,36 +93,34 @@ func (h *Handler) AddComment(c *gin.Context) {
 		return
 	}
 
-	// Check access - can comment if owner OR public/unlisted
-	if !anchor.IsOwnedBy(currentUser.ID) {
+	// Check access - can comment if a member OR public/unlisted
+	if !anchor.IsMember(currentUser.ID) {
 		if anchor.Visibility == anchors.VisibilityPrivate {
 			response.Forbidden(c, "ACCESS_DENIED", "Cannot comment on private anchor")
 			return
 		}
 	}
 
-	// Extract mentions
-	mentionUsernames := ExtractMentions(req.Content)
+	if !h.checkCommentPolicy(c, anchor, currentUser.ID) {
+		return
+	}
 
-	// Validate mentioned users and get their IDs
-	var mentionIDs []primitive.ObjectID
-	if len(mentionUsernames) > 0 {
-		userIDMap, err := h.authRepo.GetUserIDsByUsernames(c.Request.Context(), mentionUsernames)
-		if err == nil {
-			for _, username := range mentionUsernames {
-				if id, ok := userIDMap[username]; ok {
-					mentionIDs = append(mentionIDs, id)
-				}
-			}
-		}
+	status, err := h.moderationStatus(c.Request.Context(), anchor, currentUser.ID, req.Content)
+	if err != nil {
+		response.InternalServerError(c, "CREATE_FAILED", "Failed to create comment")
+		return
 	}
 
+	// Extract mentions and get the mentioned users' IDs
+	mentionIDs := h.resolveMentions(c.Request.Context(), req.Content)
+
 	// Create comment
 	comment := &Comment{
 		AnchorID: anchorID,
 		UserID:   currentUser.ID,
 		Content:  req.Content,
 		Mentions: mentionIDs,
+		Status:   status,
 	}
 
 	if err := h.repo.CreateComment(c.Request.Context(), comment); err != nil {
@@ -125,34 +128,20 @@ func (h *Handler) AddComment(c *gin.Context) {
 		return
 	}
 
-	// Increment anchor comment count
-	_ = h.anchorsRepo.IncrementCommentCount(c.Request.Context(), anchorID, 1)
-
-	// Update engagement score (async)
-	go func() {
-		_ = h.anchorsRepo.UpdateEngagementScore(context.Background(), anchorID)
-	}()
-
-	// Create notifications (async)
-	go func() {
-		commentData := &notifications.CommentData{
-			ID:       comment.ID,
-			AnchorID: comment.AnchorID,
-			Content:  comment.Content,
-			Mentions: comment.Mentions,
-		}
-		_ = h.notificationService.CreateCommentNotifications(context.Background(), commentData, anchor.ID, anchor.UserID, currentUser)
-	}()
+	// Held comments are counted and notified once approved
+	if comment.IsPublished() {
+		h.publishComment(c.Request.Context(), comment, nil, anchor, currentUser)
+	}
 
 	// Build response
-	commentResponse := h.buildCommentResponse(comment, currentUser, false)
+	commentResponse := h.buildCommentResponse(comment, currentUser, likes.NewReactionSummary(nil, ""))
 
 	response.Created(c, commentResponse)
 }
 
 // ListComments godoc
 // @Summary List comments for anchor
-// @Description Get paginated list of comments
+// @Description Get paginated list of top-level comments. The pinned comment is returned separately on the first page.
 // @Tags comments
 // @Produce json
 // @Param id path string true "Anchor ID"
@@ -195,7 +184,7 @@ func (h *Handler) ListComments(c *gin.Context) {
 
 	// Check access for private anchor
 	if anchor.Visibility == anchors.VisibilityPrivate {
-		if currentUserID == nil || !anchor.IsOwnedBy(*currentUserID) {
+		if currentUserID == nil || !anchor.IsMember(*currentUserID) {
 			response.Forbidden(c, "ACCESS_DENIED", "Cannot view comments on private anchor")
 			return
 		}
@@ -213,8 +202,31 @@ func (h *Handler) ListComments(c *gin.Context) {
 		return
 	}
 
-	// Get comments
-	comments, total, err := h.repo.GetCommentsByAnchor(c.Request.Context(), anchorID, query.Sort, query.Page, query.Limit)
+	// Get comments, leaving out hidden commenters
+	hiddenUserIDs, err := h.authRepo.GetHiddenUserIDs(c.Request.Context(), currentUserID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch comments")
+		return
+	}
+
+	listFilter := ListFilter{
+		ExcludeUserIDs: hiddenUserIDs,
+		ViewerID:       currentUserID,
+		ViewerIsOwner:  currentUserID != nil && anchor.IsOwnedBy(*currentUserID),
+	}
+
+	// The pinned comment leads the first page instead of taking its place in the list
+	var pinned *CommentResponse
+	if anchor.PinnedCommentID != nil && query.Page == 1 {
+		pinnedComment, err := h.repo.GetThreadComment(c.Request.Context(), *anchor.PinnedCommentID, ListFilter{ExcludeUserIDs: hiddenUserIDs})
+		if err == nil && !pinnedComment.Tombstone {
+			pinnedResponses := h.buildCommentListResponse(c.Request.Context(), []Comment{*pinnedComment}, currentUserID)
+			pinned = &pinnedResponses[0]
+			pinned.IsPinned = true
+		}
+	}
+
+	comments, total, err := h.repo.GetCommentsByAnchor(c.Request.Context(), anchorID, query.Sort, query.Page, query.Limit, listFilter, anchor.PinnedCommentID)
 	if err != nil {
 		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch comments")
 		return
@@ -226,6 +238,7 @@ func (h *Handler) ListComments(c *gin.Context) {
 	totalPages := int(math.Ceil(float64(total) / float64(query.Limit)))
 
 	resp := PaginatedCommentsResponse{
+		Pinned:   pinned,
 		Comments: commentResponses,
 		Meta: CommentListMeta{
 			Sort:     query.Sort,
@@ -241,6 +254,209 @@ func (h *Handler) ListComments(c *gin.Context) {
 	response.Success(c, resp)
 }
 
+// AddReply godoc
+// @Summary Reply to comment
+// @Description Reply to a comment or to another reply, with @mention support. Replies matching the anchor owner's keyword filter are held for approval.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Param request body CreateCommentRequest true "Reply content"
+// @Success 201 {object} response.APIResponse{data=CommentResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /comments/{id}/replies [post]
+func (h *Handler) AddReply(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	parentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "INVALID_ID", "Invalid comment ID")
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+
+	if err := ValidateCreateCommentRequest(&req); err != nil {
+		response.BadRequest(c, "VALIDATION_FAILED", err.Error())
+		return
+	}
+
+	// Deleted comments, tombstones included, can't be replied to, nor can held or hidden ones
+	parent, err := h.repo.GetCommentByID(c.Request.Context(), parentID)
+	if err != nil || !parent.IsPublished() {
+		response.NotFound(c, "COMMENT_NOT_FOUND", "Comment not found")
+		return
+	}
+
+	anchor, err := h.anchorsRepo.GetAnchorByID(c.Request.Context(), parent.AnchorID)
+	if err != nil || anchor.DeletedAt != nil {
+		response.NotFound(c, "ANCHOR_NOT_FOUND", "Anchor not found")
+		return
+	}
+
+	if anchor.Visibility == anchors.VisibilityPrivate && !anchor.IsMember(currentUser.ID) {
+		response.Forbidden(c, "ACCESS_DENIED", "Cannot comment on private anchor")
+		return
+	}
+
+	if !h.checkCommentPolicy(c, anchor, currentUser.ID) {
+		return
+	}
+
+	status, err := h.moderationStatus(c.Request.Context(), anchor, currentUser.ID, req.Content)
+	if err != nil {
+		response.InternalServerError(c, "CREATE_FAILED", "Failed to create reply")
+		return
+	}
+
+	// Count the reply first; this fails if the parent was deleted meanwhile.
+	// Held replies are counted once approved.
+	if status == "" {
+		if err := h.repo.AddReplyToParent(c.Request.Context(), parent.ID); err != nil {
+			response.NotFound(c, "COMMENT_NOT_FOUND", "Comment not found")
+			return
+		}
+	}
+
+	rootID := parent.ID
+	if parent.RootID != nil {
+		rootID = *parent.RootID
+	}
+
+	reply := &Comment{
+		AnchorID: parent.AnchorID,
+		UserID:   currentUser.ID,
+		ParentID: &parent.ID,
+		RootID:   &rootID,
+		Content:  req.Content,
+		Mentions: h.resolveMentions(c.Request.Context(), req.Content),
+		Status:   status,
+	}
+
+	if err := h.repo.CreateComment(c.Request.Context(), reply); err != nil {
+		if reply.IsPublished() {
+			_ = h.repo.RemoveReplyFromParent(context.Background(), parent.ID)
+		}
+		response.InternalServerError(c, "CREATE_FAILED", "Failed to create reply")
+		return
+	}
+
+	// Replies count towards the anchor's comments
+	if reply.IsPublished() {
+		h.publishComment(c.Request.Context(), reply, parent, anchor, currentUser)
+	}
+
+	response.Created(c, h.buildCommentResponse(reply, currentUser, likes.NewReactionSummary(nil, "")))
+}
+
+// ListReplies godoc
+// @Summary List replies to comment
+// @Description Get paginated direct replies to a comment, oldest first. Deleted replies that have replies of their own are shown as placeholders with isDeleted set.
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Items per page (default 20, max 50)"
+// @Success 200 {object} response.APIResponse{data=PaginatedCommentsResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /comments/{id}/replies [get]
+func (h *Handler) ListReplies(c *gin.Context) {
+	parentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "INVALID_ID", "Invalid comment ID")
+		return
+	}
+
+	// Replies of a deleted comment stay visible under its tombstone
+	parent, err := h.repo.GetThreadComment(c.Request.Context(), parentID, ListFilter{})
+	if err != nil {
+		response.NotFound(c, "COMMENT_NOT_FOUND", "Comment not found")
+		return
+	}
+
+	anchor, err := h.anchorsRepo.GetAnchorByID(c.Request.Context(), parent.AnchorID)
+	if err != nil || anchor.DeletedAt != nil {
+		response.NotFound(c, "ANCHOR_NOT_FOUND", "Anchor not found")
+		return
+	}
+
+	var currentUserID *primitive.ObjectID
+	if usr, exists := c.Get("user"); exists {
+		if user, ok := usr.(*auth.User); ok {
+			currentUserID = &user.ID
+		}
+	}
+
+	if anchor.Visibility == anchors.VisibilityPrivate {
+		if currentUserID == nil || !anchor.IsMember(*currentUserID) {
+			response.Forbidden(c, "ACCESS_DENIED", "Cannot view comments on private anchor")
+			return
+		}
+	}
+
+	var query ReplyListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "INVALID_QUERY", "Invalid query parameters")
+		return
+	}
+
+	if err := ValidateReplyListQuery(&query); err != nil {
+		response.BadRequest(c, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	hiddenUserIDs, err := h.authRepo.GetHiddenUserIDs(c.Request.Context(), currentUserID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch replies")
+		return
+	}
+
+	listFilter := ListFilter{
+		ExcludeUserIDs: hiddenUserIDs,
+		ViewerID:       currentUserID,
+		ViewerIsOwner:  currentUserID != nil && anchor.IsOwnedBy(*currentUserID),
+	}
+
+	replies, total, err := h.repo.GetReplies(c.Request.Context(), parentID, query.Page, query.Limit, listFilter)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch replies")
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(query.Limit)))
+
+	resp := PaginatedCommentsResponse{
+		Comments: h.buildCommentListResponse(c.Request.Context(), replies, currentUserID),
+		Meta: CommentListMeta{
+			Sort:     SortOldest,
+			AnchorID: anchor.ID,
+			ParentID: &parentID,
+		},
+	}
+	resp.Pagination.Page = query.Page
+	resp.Pagination.Limit = query.Limit
+	resp.Pagination.Total = total
+	resp.Pagination.TotalPages = totalPages
+	resp.Pagination.HasMore = query.Page < totalPages
+
+	response.Success(c, resp)
+}
+
 // GetComment godoc
 // @Summary Get single comment
 // @Description Get comment by ID
@@ -281,22 +497,25 @@ func (h *Handler) GetComment(c *gin.Context) {
 	}
 
 	if anchor.Visibility == anchors.VisibilityPrivate {
-		if currentUserID == nil || !anchor.IsOwnedBy(*currentUserID) {
+		if currentUserID == nil || !anchor.IsMember(*currentUserID) {
 			response.Forbidden(c, "ACCESS_DENIED", "Cannot view comment on private anchor")
 			return
 		}
 	}
 
+	// Held and hidden comments are only shown to their author and the anchor owner
+	if !comment.IsPublished() {
+		if currentUserID == nil || (comment.UserID != *currentUserID && !anchor.IsOwnedBy(*currentUserID)) {
+			response.NotFound(c, "COMMENT_NOT_FOUND", "Comment not found")
+			return
+		}
+	}
+
 	// Get author
 	author, _ := h.authRepo.GetUserByObjectID(c.Request.Context(), comment.UserID)
 
-	// Check if liked
-	hasLiked := false
-	if currentUserID != nil {
-		hasLiked, _ = h.repo.ExistsCommentLike(c.Request.Context(), commentID, *currentUserID)
-	}
-
-	commentResponse := h.buildCommentResponseWithAuthor(comment, author, hasLiked)
+	commentResponse := h.buildCommentResponseWithAuthor(comment, author, h.getReactions(c, commentID))
+	commentResponse.IsPinned = anchor.PinnedCommentID != nil && *anchor.PinnedCommentID == comment.ID
 
 	response.Success(c, commentResponse)
 }
@@ -355,56 +574,70 @@ func (h *Handler) EditComment(c *gin.Context) {
 		return
 	}
 
+	anchor, err := h.anchorsRepo.GetAnchorByID(c.Request.Context(), comment.AnchorID)
+	if err != nil {
+		response.NotFound(c, "ANCHOR_NOT_FOUND", "Anchor not found")
+		return
+	}
+
+	// Edits go through the owner's keyword filter like new comments do
+	status, err := h.moderationStatus(c.Request.Context(), anchor, currentUser.ID, req.Content)
+	if err != nil {
+		response.InternalServerError(c, "UPDATE_FAILED", "Failed to update comment")
+		return
+	}
+	held := status == StatusHeld && comment.IsPublished()
+
 	// Store old mentions for comparison
 	oldMentions := comment.Mentions
 
 	// Extract new mentions
-	mentionUsernames := ExtractMentions(req.Content)
-	var newMentionIDs []primitive.ObjectID
-	if len(mentionUsernames) > 0 {
-		userIDMap, err := h.authRepo.GetUserIDsByUsernames(c.Request.Context(), mentionUsernames)
-		if err == nil {
-			for _, username := range mentionUsernames {
-				if id, ok := userIDMap[username]; ok {
-					newMentionIDs = append(newMentionIDs, id)
-				}
-			}
-		}
-	}
+	newMentionIDs := h.resolveMentions(c.Request.Context(), req.Content)
 
 	// Update comment
-	err = h.repo.UpdateComment(c.Request.Context(), commentID, map[string]interface{}{
+	updates := map[string]interface{}{
 		"content":  req.Content,
 		"mentions": newMentionIDs,
 		"isEdited": true,
-	})
+	}
+	if held {
+		updates["status"] = StatusHeld
+	}
+	err = h.repo.UpdateComment(c.Request.Context(), commentID, updates)
 	if err != nil {
 		response.InternalServerError(c, "UPDATE_FAILED", "Failed to update comment")
 		return
 	}
 
-	// Create notifications for NEW mentions only (async)
-	go func() {
-		commentData := &notifications.CommentData{
-			ID:       commentID,
-			AnchorID: comment.AnchorID,
-			Content:  req.Content,
-			Mentions: newMentionIDs,
-		}
-		_ = h.notificationService.CreateEditCommentNotifications(context.Background(), commentData, oldMentions, currentUser)
-	}()
+	// A held comment no longer counts until it is approved again
+	if held {
+		h.unpublishComment(c.Request.Context(), comment, anchor, false)
+	}
+
+	// Create notifications for NEW mentions only (async); unpublished comments notify no one
+	if comment.IsPublished() && !held {
+		go func() {
+			commentData := &notifications.CommentData{
+				ID:       commentID,
+				AnchorID: comment.AnchorID,
+				Content:  req.Content,
+				Mentions: newMentionIDs,
+			}
+			_ = h.notificationService.CreateEditCommentNotifications(context.Background(), commentData, oldMentions, currentUser)
+		}()
+	}
 
 	// Get updated comment
 	updatedComment, _ := h.repo.GetCommentByID(c.Request.Context(), commentID)
 
-	commentResponse := h.buildCommentResponse(updatedComment, currentUser, false)
+	commentResponse := h.buildCommentResponse(updatedComment, currentUser, h.getReactions(c, commentID))
 
 	response.Success(c, commentResponse)
 }
 
 // DeleteComment godoc
 // @Summary Delete comment
-// @Description Delete own comment or any comment on own anchor
+// @Description Delete own comment or any comment on own anchor. A comment with replies is replaced by a placeholder so its replies stay visible.
 // @Tags comments
 // @Produce json
 // @Security BearerAuth
@@ -450,19 +683,33 @@ func (h *Handler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	// Soft delete
-	if err := h.repo.SoftDeleteComment(c.Request.Context(), commentID); err != nil {
+	// Soft delete; a comment with replies is left as a tombstone
+	tombstoned, err := h.repo.SoftDeleteComment(c.Request.Context(), commentID)
+	if err != nil {
 		response.InternalServerError(c, "DELETE_FAILED", "Failed to delete comment")
 		return
 	}
 
-	// Decrement comment count
-	_ = h.anchorsRepo.IncrementCommentCount(c.Request.Context(), comment.AnchorID, -1)
+	// A removed reply no longer counts on its parent; held replies never did
+	if !tombstoned && comment.ParentID != nil && comment.Status != StatusHeld {
+		if err := h.repo.RemoveReplyFromParent(c.Request.Context(), *comment.ParentID); err != nil {
+			log.Printf("Failed to update reply count of comment %s: %v", comment.ParentID.Hex(), err)
+		}
+	}
 
-	// Update engagement score (async)
-	go func() {
-		_ = h.anchorsRepo.UpdateEngagementScore(context.Background(), comment.AnchorID)
-	}()
+	// Only published comments count on the anchor
+	if comment.IsPublished() {
+		_ = h.anchorsRepo.IncrementCommentCount(c.Request.Context(), comment.AnchorID, -1)
+
+		// Update engagement score (async)
+		go func() {
+			_ = h.anchorsRepo.UpdateEngagementScore(context.Background(), comment.AnchorID)
+		}()
+	}
+
+	if anchor.PinnedCommentID != nil && *anchor.PinnedCommentID == comment.ID {
+		_ = h.anchorsRepo.UnpinComment(c.Request.Context(), anchor.ID, comment.ID)
+	}
 
 	response.Success(c, gin.H{"message": "Comment deleted successfully"})
 }
@@ -508,9 +755,9 @@ func (h *Handler) LikeComment(c *gin.Context) {
 		return
 	}
 
-	// Get comment
+	// Get comment; held and hidden comments can't be liked
 	comment, err := h.repo.GetCommentByID(c.Request.Context(), commentID)
-	if err != nil {
+	if err != nil || !comment.IsPublished() {
 		response.NotFound(c, "COMMENT_NOT_FOUND", "Comment not found")
 		return
 	}
@@ -522,7 +769,7 @@ func (h *Handler) LikeComment(c *gin.Context) {
 		return
 	}
 
-	if anchor.Visibility == anchors.VisibilityPrivate && !anchor.IsOwnedBy(currentUser.ID) {
+	if anchor.Visibility == anchors.VisibilityPrivate && !anchor.IsMember(currentUser.ID) {
 		response.Forbidden(c, "ACCESS_DENIED", "Cannot like comment on private anchor")
 		return
 	}
@@ -530,20 +777,17 @@ func (h *Handler) LikeComment(c *gin.Context) {
 	var hasLiked bool
 
 	if req.Action == "like" {
-		err = h.repo.CreateCommentLike(c.Request.Context(), commentID, currentUser.ID)
-		if err != nil {
+		// A like keeps any other reaction the user already has
+		if _, err := h.react(c.Request.Context(), commentID, currentUser.ID, likes.ReactionLike, false); err != nil {
 			response.InternalServerError(c, "LIKE_FAILED", "Failed to like comment")
 			return
 		}
-		_ = h.repo.IncrementCommentLikeCount(c.Request.Context(), commentID, 1)
 		hasLiked = true
 	} else {
-		err = h.repo.DeleteCommentLike(c.Request.Context(), commentID, currentUser.ID)
-		if err != nil {
+		if err := h.unreact(c.Request.Context(), commentID, currentUser.ID); err != nil {
 			response.InternalServerError(c, "UNLIKE_FAILED", "Failed to unlike comment")
 			return
 		}
-		_ = h.repo.IncrementCommentLikeCount(c.Request.Context(), commentID, -1)
 		hasLiked = false
 	}
 
@@ -597,36 +841,153 @@ func (h *Handler) GetCommentLikeStatus(c *gin.Context) {
 		return
 	}
 
-	if anchor.Visibility == anchors.VisibilityPrivate && !anchor.IsOwnedBy(currentUser.ID) {
+	if anchor.Visibility == anchors.VisibilityPrivate && !anchor.IsMember(currentUser.ID) {
 		response.Forbidden(c, "ACCESS_DENIED", "Cannot access comment on private anchor")
 		return
 	}
 
-	hasLiked, _ := h.repo.ExistsCommentLike(c.Request.Context(), commentID, currentUser.ID)
+	reaction, _ := h.repo.Reactions().GetReaction(c.Request.Context(), commentID, currentUser.ID)
 
-	response.Success(c, CommentLikeResponse{
-		HasLiked:  hasLiked,
+	resp := CommentLikeResponse{
+		HasLiked:  reaction != "",
 		LikeCount: comment.LikeCount,
-	})
+	}
+	if reaction != "" {
+		resp.Reaction = &reaction
+	}
+
+	response.Success(c, resp)
 }
 
 // Helper methods
 
-func (h *Handler) buildCommentResponse(comment *Comment, author *auth.User, hasLiked bool) CommentResponse {
+// checkCommentPolicy reports whether the user may comment on the anchor under
+// its comment policy, responding with the reason if not
+func (h *Handler) checkCommentPolicy(c *gin.Context, anchor *anchors.Anchor, userID primitive.ObjectID) bool {
+	switch anchor.GetCommentPolicy() {
+	case anchors.CommentPolicyDisabled:
+		response.Forbidden(c, "COMMENTS_DISABLED", "Comments are turned off for this anchor")
+		return false
+	case anchors.CommentPolicyFollowers:
+		if anchor.IsOwnedBy(userID) {
+			return true
+		}
+		following, err := h.followsRepo.ExistsFollow(c.Request.Context(), userID, anchor.UserID)
+		if err != nil {
+			response.InternalServerError(c, "FETCH_FAILED", "Failed to check comment permissions")
+			return false
+		}
+		if !following {
+			response.Forbidden(c, "FOLLOWERS_ONLY", "Only followers of the owner can comment on this anchor")
+			return false
+		}
+	}
+	return true
+}
+
+// moderationStatus returns StatusHeld if content matches the anchor owner's
+// keyword filter. The owner's own comments are never held.
+func (h *Handler) moderationStatus(ctx context.Context, anchor *anchors.Anchor, userID primitive.ObjectID, content string) (string, error) {
+	if anchor.IsOwnedBy(userID) {
+		return "", nil
+	}
+
+	filter, err := h.repo.GetCommentFilter(ctx, anchor.UserID)
+	if err != nil {
+		return "", err
+	}
+	if MatchesKeywords(content, filter.Keywords) {
+		return StatusHeld, nil
+	}
+	return "", nil
+}
+
+// publishComment counts a newly published comment on its anchor and notifies
+// the anchor owner, or the parent comment's author for replies. Replies must
+// already be counted on their parent.
+func (h *Handler) publishComment(ctx context.Context, comment *Comment, parent *Comment, anchor *anchors.Anchor, author *auth.User) {
+	_ = h.anchorsRepo.IncrementCommentCount(ctx, anchor.ID, 1)
+
+	// Update engagement score (async)
+	go func() {
+		_ = h.anchorsRepo.UpdateEngagementScore(context.Background(), anchor.ID)
+	}()
+
+	// Create notifications (async)
+	go func() {
+		commentData := &notifications.CommentData{
+			ID:       comment.ID,
+			AnchorID: comment.AnchorID,
+			Content:  comment.Content,
+			Mentions: comment.Mentions,
+		}
+		if parent != nil {
+			commentData.ParentID = &parent.ID
+			commentData.ParentUserID = parent.UserID
+		}
+		_ = h.notificationService.CreateCommentNotifications(context.Background(), commentData, anchor.ID, anchor.UserID, author)
+	}()
+}
+
+// unpublishComment uncounts a comment that was published and unpins it.
+// Hidden replies keep counting on their parent so the thread stays intact.
+func (h *Handler) unpublishComment(ctx context.Context, comment *Comment, anchor *anchors.Anchor, hidden bool) {
+	_ = h.anchorsRepo.IncrementCommentCount(ctx, anchor.ID, -1)
+
+	go func() {
+		_ = h.anchorsRepo.UpdateEngagementScore(context.Background(), anchor.ID)
+	}()
+
+	if !hidden && comment.ParentID != nil {
+		if err := h.repo.RemoveReplyFromParent(ctx, *comment.ParentID); err != nil {
+			log.Printf("Failed to update reply count of comment %s: %v", comment.ParentID.Hex(), err)
+		}
+	}
+
+	if anchor.PinnedCommentID != nil && *anchor.PinnedCommentID == comment.ID {
+		_ = h.anchorsRepo.UnpinComment(ctx, anchor.ID, comment.ID)
+	}
+}
+
+// resolveMentions returns the IDs of existing users @mentioned in content
+func (h *Handler) resolveMentions(ctx context.Context, content string) []primitive.ObjectID {
+	mentionUsernames := ExtractMentions(content)
+	if len(mentionUsernames) == 0 {
+		return nil
+	}
+
+	var mentionIDs []primitive.ObjectID
+	userIDMap, err := h.authRepo.GetUserIDsByUsernames(ctx, mentionUsernames)
+	if err == nil {
+		for _, username := range mentionUsernames {
+			if id, ok := userIDMap[username]; ok {
+				mentionIDs = append(mentionIDs, id)
+			}
+		}
+	}
+	return mentionIDs
+}
+
+func (h *Handler) buildCommentResponse(comment *Comment, author *auth.User, reactions likes.ReactionSummary) CommentResponse {
 	var profilePic *string
 	if author.ProfilePictureURL != "" {
 		profilePic = &author.ProfilePictureURL
 	}
 
 	return CommentResponse{
-		ID:        comment.ID,
-		AnchorID:  comment.AnchorID,
-		Content:   comment.Content,
-		Mentions:  comment.Mentions,
-		LikeCount: comment.LikeCount,
-		IsEdited:  comment.IsEdited,
-		CreatedAt: comment.CreatedAt,
-		UpdatedAt: comment.UpdatedAt,
+		ID:         comment.ID,
+		AnchorID:   comment.AnchorID,
+		ParentID:   comment.ParentID,
+		RootID:     comment.RootID,
+		Content:    comment.Content,
+		Mentions:   comment.Mentions,
+		LikeCount:  comment.LikeCount,
+		ReplyCount: comment.ReplyCount,
+		IsEdited:   comment.IsEdited,
+		IsDeleted:  comment.Tombstone,
+		Reactions:  reactions,
+		CreatedAt:  comment.CreatedAt,
+		UpdatedAt:  comment.UpdatedAt,
 		Author: CommentAuthor{
 			ID:             author.ID,
 			Username:       author.Username,
@@ -635,12 +996,19 @@ func (h *Handler) buildCommentResponse(comment *Comment, author *auth.User, hasL
 			IsVerified:     author.IsVerified,
 		},
 		Engagement: CommentEngagement{
-			HasLiked: hasLiked,
+			HasLiked: reactions.MyReaction != nil,
 		},
 	}
 }
 
-func (h *Handler) buildCommentResponseWithAuthor(comment *Comment, author *auth.User, hasLiked bool) CommentResponse {
+func (h *Handler) buildCommentResponseWithAuthor(comment *Comment, author *auth.User, reactions likes.ReactionSummary) CommentResponse {
+	// Tombstones don't reveal who wrote them
+	if comment.Tombstone {
+		author = &auth.User{
+			Username:    "deleted",
+			DisplayName: "Deleted Comment",
+		}
+	}
 	if author == nil {
 		author = &auth.User{
 			ID:          comment.UserID,
@@ -648,7 +1016,7 @@ func (h *Handler) buildCommentResponseWithAuthor(comment *Comment, author *auth.
 			DisplayName: "Deleted User",
 		}
 	}
-	return h.buildCommentResponse(comment, author, hasLiked)
+	return h.buildCommentResponse(comment, author, reactions)
 }
 
 func (h *Handler) buildCommentListResponse(ctx context.Context, comments []Comment, currentUserID *primitive.ObjectID) []CommentResponse {
@@ -671,21 +1039,18 @@ func (h *Handler) buildCommentListResponse(ctx context.Context, comments []Comme
 		authorMap[authors[i].ID] = &authors[i]
 	}
 
-	// Batch fetch like status
-	var likedMap map[primitive.ObjectID]bool
+	// Batch fetch the viewer's reactions; the counts are kept on the comments
+	var reactionMap map[primitive.ObjectID]string
 	if currentUserID != nil {
-		likedMap, _ = h.repo.GetUserLikedComments(ctx, *currentUserID, commentIDs)
+		reactionMap, _ = h.repo.Reactions().GetUserReactions(ctx, *currentUserID, commentIDs)
 	}
 
 	// Build responses
 	responses := make([]CommentResponse, len(comments))
 	for i, comment := range comments {
 		author := authorMap[comment.UserID]
-		hasLiked := false
-		if likedMap != nil {
-			hasLiked = likedMap[comment.ID]
-		}
-		responses[i] = h.buildCommentResponseWithAuthor(&comment, author, hasLiked)
+		reactions := likes.NewReactionSummary(comment.ReactionCounts, reactionMap[comment.ID])
+		responses[i] = h.buildCommentResponseWithAuthor(&comment, author, reactions)
 	}
 
 	return responses
@@ -0,0 +1,48 @@
+package comments
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeWords lowercases text and reduces it to its words separated by
+// single spaces, so "Buy NOW!!" becomes "buy now"
+func normalizeWords(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// NormalizeKeywords normalizes filter keywords, dropping empty and duplicate ones
+func NormalizeKeywords(keywords []string) []string {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(keywords))
+
+	for _, keyword := range keywords {
+		k := normalizeWords(keyword)
+		if k != "" && !seen[k] {
+			seen[k] = true
+			normalized = append(normalized, k)
+		}
+	}
+
+	return normalized
+}
+
+// MatchesKeywords reports whether content contains any of the normalized
+// keywords as whole words, so "ass" does not match "class"
+func MatchesKeywords(content string, keywords []string) bool {
+	if len(keywords) == 0 {
+		return false
+	}
+
+	padded := " " + normalizeWords(content) + " "
+	for _, keyword := range keywords {
+		if strings.Contains(padded, " "+keyword+" ") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,6 +3,7 @@ package comments
 import (
 	"time"
 
+	"github.com/xyz-asif/gotodo/internal/features/likes"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -13,25 +14,51 @@ const (
 	SortTop    = "top"
 )
 
-// Comment represents a comment on an anchor
+// Moderation status constants; published comments have no status
+const (
+	StatusHeld   = "held"   // matched the anchor owner's keyword filter; waiting for approval
+	StatusHidden = "hidden" // hidden by the anchor owner; only the owner and the author see it
+)
+
+// Comment represents a comment on an anchor, or a reply to another comment
 type Comment struct {
-	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
-	AnchorID  primitive.ObjectID   `bson:"anchorId" json:"anchorId"`
-	UserID    primitive.ObjectID   `bson:"userId" json:"userId"`
-	Content   string               `bson:"content" json:"content"`
-	Mentions  []primitive.ObjectID `bson:"mentions" json:"mentions"`
-	LikeCount int                  `bson:"likeCount" json:"likeCount"`
-	IsEdited  bool                 `bson:"isEdited" json:"isEdited"`
-	CreatedAt time.Time            `bson:"createdAt" json:"createdAt"`
-	UpdatedAt time.Time            `bson:"updatedAt" json:"updatedAt"`
-	DeletedAt *time.Time           `bson:"deletedAt,omitempty" json:"-"`
-}
-
-// CommentLike represents a like on a comment
+	ID             primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	AnchorID       primitive.ObjectID   `bson:"anchorId" json:"anchorId"`
+	UserID         primitive.ObjectID   `bson:"userId" json:"userId"`
+	ParentID       *primitive.ObjectID  `bson:"parentId,omitempty" json:"parentId,omitempty"` // comment replied to; nil for top-level comments
+	RootID         *primitive.ObjectID  `bson:"rootId,omitempty" json:"rootId,omitempty"`     // top-level comment of the thread
+	Content        string               `bson:"content" json:"content"`
+	Mentions       []primitive.ObjectID `bson:"mentions" json:"mentions"`
+	LikeCount      int                  `bson:"likeCount" json:"likeCount"`
+	ReactionCounts map[string]int       `bson:"reactionCounts,omitempty" json:"-"` // kept by likes.ReactionStore
+	ReplyCount     int                  `bson:"replyCount" json:"replyCount"`      // direct replies still shown
+	IsEdited       bool                 `bson:"isEdited" json:"isEdited"`
+	CreatedAt      time.Time            `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time            `bson:"updatedAt" json:"updatedAt"`
+	DeletedAt      *time.Time           `bson:"deletedAt,omitempty" json:"-"`
+	Tombstone      bool                 `bson:"tombstone,omitempty" json:"-"` // deleted, but still shown as a placeholder because it has replies
+	Status         string               `bson:"status,omitempty" json:"status,omitempty"`
+}
+
+// IsPublished reports whether the comment is shown to everyone and counted on its anchor
+func (c *Comment) IsPublished() bool {
+	return c.Status == ""
+}
+
+// CommentFilter holds the keywords that hold comments on a user's anchors for approval
+type CommentFilter struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	Keywords  []string           `bson:"keywords" json:"keywords"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// CommentLike represents a reaction on a comment; the binary like is the "like" reaction
 type CommentLike struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	CommentID primitive.ObjectID `bson:"commentId" json:"commentId"`
 	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	Reaction  string             `bson:"reaction,omitempty" json:"reaction"` // empty on likes stored before reactions
 	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
 }
 
@@ -55,6 +82,30 @@ type CommentListQuery struct {
 	Sort  string `form:"sort,default=newest"`
 }
 
+type CommentModerationRequest struct {
+	Action string `json:"action" binding:"required,oneof=hide unhide"`
+}
+
+type CommentPinRequest struct {
+	Action string `json:"action" binding:"required,oneof=pin unpin"`
+}
+
+type UpdateCommentFilterRequest struct {
+	Keywords []string `json:"keywords" binding:"max=100,dive,min=1,max=50"`
+}
+
+// HeldListQuery lists comments waiting for approval, oldest first
+type HeldListQuery struct {
+	Page  int `form:"page,default=1" binding:"min=1"`
+	Limit int `form:"limit,default=20" binding:"min=1,max=50"`
+}
+
+// ReplyListQuery lists a comment's replies, oldest first
+type ReplyListQuery struct {
+	Page  int `form:"page,default=1" binding:"min=1"`
+	Limit int `form:"limit,default=20" binding:"min=1,max=50"`
+}
+
 // Response DTOs
 
 type CommentAuthor struct {
@@ -66,33 +117,43 @@ type CommentAuthor struct {
 }
 
 type CommentEngagement struct {
-	HasLiked bool `json:"hasLiked"`
+	HasLiked bool `json:"hasLiked"` // true for any reaction
 }
 
 type CommentResponse struct {
-	ID         primitive.ObjectID   `json:"id"`
-	AnchorID   primitive.ObjectID   `json:"anchorId"`
-	Content    string               `json:"content"`
-	Mentions   []primitive.ObjectID `json:"mentions"`
-	LikeCount  int                  `json:"likeCount"`
-	IsEdited   bool                 `json:"isEdited"`
-	CreatedAt  time.Time            `json:"createdAt"`
-	UpdatedAt  time.Time            `json:"updatedAt"`
-	Author     CommentAuthor        `json:"author"`
-	Engagement CommentEngagement    `json:"engagement"`
+	ID         primitive.ObjectID    `json:"id"`
+	AnchorID   primitive.ObjectID    `json:"anchorId"`
+	ParentID   *primitive.ObjectID   `json:"parentId,omitempty"`
+	RootID     *primitive.ObjectID   `json:"rootId,omitempty"`
+	Content    string                `json:"content"`
+	Mentions   []primitive.ObjectID  `json:"mentions"`
+	LikeCount  int                   `json:"likeCount"`
+	ReplyCount int                   `json:"replyCount"`
+	IsEdited   bool                  `json:"isEdited"`
+	IsDeleted  bool                  `json:"isDeleted"` // tombstone kept for its replies; content and author are blank
+	IsPinned   bool                  `json:"isPinned"`
+	Reactions  likes.ReactionSummary `json:"reactions"`
+	Status     string                `json:"status,omitempty"` // "held" or "hidden"; only shown to the author and the anchor owner
+	CreatedAt  time.Time             `json:"createdAt"`
+	UpdatedAt  time.Time             `json:"updatedAt"`
+	Author     CommentAuthor         `json:"author"`
+	Engagement CommentEngagement     `json:"engagement"`
 }
 
 type CommentLikeResponse struct {
-	HasLiked  bool `json:"hasLiked"`
-	LikeCount int  `json:"likeCount"`
+	HasLiked  bool    `json:"hasLiked"`
+	Reaction  *string `json:"reaction,omitempty"`
+	LikeCount int     `json:"likeCount"`
 }
 
 type CommentListMeta struct {
-	Sort     string             `json:"sort"`
-	AnchorID primitive.ObjectID `json:"anchorId"`
+	Sort     string              `json:"sort"`
+	AnchorID primitive.ObjectID  `json:"anchorId"`
+	ParentID *primitive.ObjectID `json:"parentId,omitempty"` // set when listing replies
 }
 
 type PaginatedCommentsResponse struct {
+	Pinned     *CommentResponse  `json:"pinned,omitempty"` // first page only; not repeated in comments
 	Comments   []CommentResponse `json:"comments"`
 	Pagination struct {
 		Page       int   `json:"page"`
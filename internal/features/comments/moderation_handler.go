@@ -0,0 +1,393 @@
+package comments
+
+import (
+	"context"
+	"math"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/features/anchors"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ModerateComment godoc
+// @Summary Hide or unhide comment
+// @Description Hide a comment on own anchor from everyone but its author, or show it again
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Param request body CommentModerationRequest true "Moderation action"
+// @Success 200 {object} response.APIResponse{data=CommentResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /comments/{id}/hide [post]
+func (h *Handler) ModerateComment(c *gin.Context) {
+	var req CommentModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+
+	if err := ValidateCommentModerationRequest(&req); err != nil {
+		response.BadRequest(c, "INVALID_ACTION", err.Error())
+		return
+	}
+
+	comment, anchor, ok := h.getOwnedComment(c)
+	if !ok {
+		return
+	}
+
+	from, to := "", StatusHidden
+	if req.Action == "unhide" {
+		from, to = StatusHidden, ""
+	}
+
+	changed, err := h.repo.SetCommentStatus(c.Request.Context(), comment.ID, from, to)
+	if err != nil {
+		response.InternalServerError(c, "UPDATE_FAILED", "Failed to update comment")
+		return
+	}
+	if !changed && comment.Status != to {
+		response.Conflict(c, "COMMENT_HELD", "Approve the comment first")
+		return
+	}
+
+	if changed {
+		if to == StatusHidden {
+			h.unpublishComment(c.Request.Context(), comment, anchor, true)
+		} else {
+			_ = h.anchorsRepo.IncrementCommentCount(c.Request.Context(), anchor.ID, 1)
+
+			go func() {
+				_ = h.anchorsRepo.UpdateEngagementScore(context.Background(), anchor.ID)
+			}()
+		}
+	}
+
+	h.respondWithComment(c, comment.ID)
+}
+
+// PinComment godoc
+// @Summary Pin or unpin comment
+// @Description Pin a top-level comment on own anchor, replacing any pinned before, or unpin it
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Param request body CommentPinRequest true "Pin action"
+// @Success 200 {object} response.APIResponse{data=CommentResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /comments/{id}/pin [post]
+func (h *Handler) PinComment(c *gin.Context) {
+	var req CommentPinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+
+	if err := ValidateCommentPinRequest(&req); err != nil {
+		response.BadRequest(c, "INVALID_ACTION", err.Error())
+		return
+	}
+
+	comment, anchor, ok := h.getOwnedComment(c)
+	if !ok {
+		return
+	}
+
+	if req.Action == "pin" {
+		if comment.ParentID != nil {
+			response.BadRequest(c, "INVALID_COMMENT", "Replies cannot be pinned")
+			return
+		}
+		if !comment.IsPublished() {
+			response.BadRequest(c, "INVALID_COMMENT", "Only published comments can be pinned")
+			return
+		}
+		if err := h.anchorsRepo.SetPinnedComment(c.Request.Context(), anchor.ID, comment.ID); err != nil {
+			response.InternalServerError(c, "UPDATE_FAILED", "Failed to pin comment")
+			return
+		}
+	} else {
+		if err := h.anchorsRepo.UnpinComment(c.Request.Context(), anchor.ID, comment.ID); err != nil {
+			response.InternalServerError(c, "UPDATE_FAILED", "Failed to unpin comment")
+			return
+		}
+	}
+
+	commentResponse := h.buildCommentResponseWithAuthor(comment, h.getAuthor(c, comment.UserID), h.getReactions(c, comment.ID))
+	commentResponse.IsPinned = req.Action == "pin"
+
+	response.Success(c, commentResponse)
+}
+
+// ApproveComment godoc
+// @Summary Approve held comment
+// @Description Publish a comment on own anchor that was held by the keyword filter
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Success 200 {object} response.APIResponse{data=CommentResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /comments/{id}/approve [post]
+func (h *Handler) ApproveComment(c *gin.Context) {
+	comment, anchor, ok := h.getOwnedComment(c)
+	if !ok {
+		return
+	}
+
+	if comment.Status != StatusHeld {
+		response.Conflict(c, "NOT_HELD", "Comment is not waiting for approval")
+		return
+	}
+
+	author, err := h.authRepo.GetUserByObjectID(c.Request.Context(), comment.UserID)
+	if err != nil {
+		response.NotFound(c, "COMMENT_NOT_FOUND", "Comment not found")
+		return
+	}
+
+	// A held reply is counted on its parent now; this fails if the parent was deleted meanwhile
+	var parent *Comment
+	if comment.ParentID != nil {
+		parent, err = h.repo.GetCommentByID(c.Request.Context(), *comment.ParentID)
+		if err == nil {
+			err = h.repo.AddReplyToParent(c.Request.Context(), parent.ID)
+		}
+		if err != nil {
+			response.NotFound(c, "COMMENT_NOT_FOUND", "The comment this replies to was deleted")
+			return
+		}
+	}
+
+	changed, err := h.repo.SetCommentStatus(c.Request.Context(), comment.ID, StatusHeld, "")
+	if err != nil || !changed {
+		if parent != nil {
+			_ = h.repo.RemoveReplyFromParent(c.Request.Context(), parent.ID)
+		}
+		if err != nil {
+			response.InternalServerError(c, "UPDATE_FAILED", "Failed to approve comment")
+			return
+		}
+		response.Conflict(c, "NOT_HELD", "Comment is not waiting for approval")
+		return
+	}
+
+	comment.Status = ""
+	h.publishComment(c.Request.Context(), comment, parent, anchor, author)
+
+	h.respondWithComment(c, comment.ID)
+}
+
+// ListHeldComments godoc
+// @Summary List held comments
+// @Description Get paginated comments and replies on own anchor waiting for approval, oldest first
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Items per page (default 20, max 50)"
+// @Success 200 {object} response.APIResponse{data=PaginatedCommentsResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/comments/held [get]
+func (h *Handler) ListHeldComments(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	anchorID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "INVALID_ID", "Invalid anchor ID")
+		return
+	}
+
+	anchor, err := h.anchorsRepo.GetAnchorByID(c.Request.Context(), anchorID)
+	if err != nil || anchor.DeletedAt != nil {
+		response.NotFound(c, "ANCHOR_NOT_FOUND", "Anchor not found")
+		return
+	}
+
+	if !anchor.IsOwnedBy(currentUser.ID) {
+		response.Forbidden(c, "FORBIDDEN", "Only the anchor owner can review held comments")
+		return
+	}
+
+	var query HeldListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "INVALID_QUERY", "Invalid query parameters")
+		return
+	}
+
+	if err := ValidateHeldListQuery(&query); err != nil {
+		response.BadRequest(c, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	comments, total, err := h.repo.GetHeldComments(c.Request.Context(), anchorID, query.Page, query.Limit)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch held comments")
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(query.Limit)))
+
+	resp := PaginatedCommentsResponse{
+		Comments: h.buildCommentListResponse(c.Request.Context(), comments, &currentUser.ID),
+		Meta: CommentListMeta{
+			Sort:     SortOldest,
+			AnchorID: anchorID,
+		},
+	}
+	resp.Pagination.Page = query.Page
+	resp.Pagination.Limit = query.Limit
+	resp.Pagination.Total = total
+	resp.Pagination.TotalPages = totalPages
+	resp.Pagination.HasMore = query.Page < totalPages
+
+	response.Success(c, resp)
+}
+
+// GetCommentFilter godoc
+// @Summary Get comment keyword filter
+// @Description Get the keywords that hold comments on own anchors for approval
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=CommentFilter}
+// @Failure 401 {object} response.APIResponse
+// @Router /users/me/comment-filter [get]
+func (h *Handler) GetCommentFilter(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	filter, err := h.repo.GetCommentFilter(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch comment filter")
+		return
+	}
+
+	response.Success(c, filter)
+}
+
+// UpdateCommentFilter godoc
+// @Summary Update comment keyword filter
+// @Description Replace the keywords that hold comments on own anchors for approval. Keywords match whole words, case-insensitively. Existing comments are not re-checked.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateCommentFilterRequest true "Keywords"
+// @Success 200 {object} response.APIResponse{data=CommentFilter}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /users/me/comment-filter [put]
+func (h *Handler) UpdateCommentFilter(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	var req UpdateCommentFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+
+	if err := ValidateUpdateCommentFilterRequest(&req); err != nil {
+		response.BadRequest(c, "VALIDATION_FAILED", err.Error())
+		return
+	}
+
+	filter, err := h.repo.SetCommentFilter(c.Request.Context(), currentUser.ID, req.Keywords)
+	if err != nil {
+		response.InternalServerError(c, "UPDATE_FAILED", "Failed to update comment filter")
+		return
+	}
+
+	response.Success(c, filter)
+}
+
+// getOwnedComment loads the comment in the path and its anchor, responding
+// with an error unless the current user owns the anchor
+func (h *Handler) getOwnedComment(c *gin.Context) (*Comment, *anchors.Anchor, bool) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return nil, nil, false
+	}
+	currentUser := usr.(*auth.User)
+
+	commentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "INVALID_ID", "Invalid comment ID")
+		return nil, nil, false
+	}
+
+	comment, err := h.repo.GetCommentByID(c.Request.Context(), commentID)
+	if err != nil {
+		response.NotFound(c, "COMMENT_NOT_FOUND", "Comment not found")
+		return nil, nil, false
+	}
+
+	anchor, err := h.anchorsRepo.GetAnchorByID(c.Request.Context(), comment.AnchorID)
+	if err != nil || anchor.DeletedAt != nil {
+		response.NotFound(c, "ANCHOR_NOT_FOUND", "Anchor not found")
+		return nil, nil, false
+	}
+
+	if !anchor.IsOwnedBy(currentUser.ID) {
+		response.Forbidden(c, "FORBIDDEN", "Only the anchor owner can moderate comments")
+		return nil, nil, false
+	}
+
+	return comment, anchor, true
+}
+
+// getAuthor fetches a comment's author; nil if the account is gone
+func (h *Handler) getAuthor(c *gin.Context, userID primitive.ObjectID) *auth.User {
+	author, err := h.authRepo.GetUserByObjectID(c.Request.Context(), userID)
+	if err != nil {
+		return nil
+	}
+	return author
+}
+
+// respondWithComment responds with the comment as it is now stored
+func (h *Handler) respondWithComment(c *gin.Context, commentID primitive.ObjectID) {
+	comment, err := h.repo.GetCommentByID(c.Request.Context(), commentID)
+	if err != nil {
+		response.NotFound(c, "COMMENT_NOT_FOUND", "Comment not found")
+		return
+	}
+
+	response.Success(c, h.buildCommentResponseWithAuthor(comment, h.getAuthor(c, comment.UserID), h.getReactions(c, comment.ID)))
+}
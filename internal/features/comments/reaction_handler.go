@@ -0,0 +1,236 @@
+package comments
+
+import (
+	"context"
+	"math"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/features/anchors"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/features/likes"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SetCommentReaction godoc
+// @Summary React to comment
+// @Description Set the current user's reaction on a comment, replacing any previous one
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Param request body likes.ReactionRequest true "Reaction"
+// @Success 200 {object} response.APIResponse{data=likes.ReactionSummary}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /comments/{id}/reaction [put]
+func (h *Handler) SetCommentReaction(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	var req likes.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", "Invalid request format")
+		return
+	}
+
+	if err := likes.ValidateReactionRequest(&req); err != nil {
+		response.BadRequest(c, "INVALID_REACTION", err.Error())
+		return
+	}
+
+	comment, ok := h.getReactableComment(c, &currentUser.ID)
+	if !ok {
+		return
+	}
+
+	if _, err := h.react(c.Request.Context(), comment.ID, currentUser.ID, req.Reaction, true); err != nil {
+		response.InternalServerError(c, "REACTION_FAILED", "Failed to react to comment")
+		return
+	}
+
+	response.Success(c, h.getReactions(c, comment.ID))
+}
+
+// RemoveCommentReaction godoc
+// @Summary Remove reaction from comment
+// @Description Remove the current user's reaction from a comment (idempotent)
+// @Tags comments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Comment ID"
+// @Success 200 {object} response.APIResponse{data=likes.ReactionSummary}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /comments/{id}/reaction [delete]
+func (h *Handler) RemoveCommentReaction(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	comment, ok := h.getReactableComment(c, &currentUser.ID)
+	if !ok {
+		return
+	}
+
+	if err := h.unreact(c.Request.Context(), comment.ID, currentUser.ID); err != nil {
+		response.InternalServerError(c, "REACTION_FAILED", "Failed to remove reaction")
+		return
+	}
+
+	response.Success(c, h.getReactions(c, comment.ID))
+}
+
+// ListCommentReactions godoc
+// @Summary List reactions on comment
+// @Description Get reaction counts by type and a paginated list of who reacted, optionally only with one reaction
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param reaction query string false "Only users who reacted with this (like, love, haha, wow, sad, angry)"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Items per page (default 20, max 50)"
+// @Success 200 {object} response.APIResponse{data=likes.PaginatedReactorsResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /comments/{id}/reactions [get]
+func (h *Handler) ListCommentReactions(c *gin.Context) {
+	var currentUserID *primitive.ObjectID
+	if usr, exists := c.Get("user"); exists {
+		if user, ok := usr.(*auth.User); ok {
+			currentUserID = &user.ID
+		}
+	}
+
+	comment, ok := h.getReactableComment(c, currentUserID)
+	if !ok {
+		return
+	}
+
+	var query likes.ReactionListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	if err := likes.ValidateReactionListQuery(&query); err != nil {
+		response.BadRequest(c, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	summary, err := h.repo.Reactions().GetSummary(c.Request.Context(), comment.ID, currentUserID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch reactions")
+		return
+	}
+
+	// Leave out hidden reactors
+	hiddenUserIDs, err := h.authRepo.GetHiddenUserIDs(c.Request.Context(), currentUserID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch reactions")
+		return
+	}
+
+	entries, total, err := h.repo.Reactions().ListReactors(c.Request.Context(), comment.ID, query.Reaction, query.Page, query.Limit, hiddenUserIDs)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch reactions")
+		return
+	}
+
+	reactors, err := likes.BuildReactorList(c.Request.Context(), entries, currentUserID, h.authRepo, h.followsRepo.GetFollowingIDs)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_USERS_FAILED", "Failed to fetch user details")
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(query.Limit)))
+
+	resp := likes.PaginatedReactorsResponse{
+		Summary: summary,
+		Data:    reactors,
+	}
+	resp.Pagination.Page = query.Page
+	resp.Pagination.Limit = query.Limit
+	resp.Pagination.Total = total
+	resp.Pagination.TotalPages = totalPages
+	resp.Pagination.HasMore = query.Page < totalPages
+
+	response.Success(c, resp)
+}
+
+// react sets or adds the user's reaction, which also counts it on the comment.
+// replace=false keeps an existing reaction.
+func (h *Handler) react(ctx context.Context, commentID, userID primitive.ObjectID, reaction string, replace bool) (string, error) {
+	if replace {
+		return h.repo.Reactions().SetReaction(ctx, commentID, userID, reaction)
+	}
+	return h.repo.Reactions().AddReaction(ctx, commentID, userID, reaction)
+}
+
+// unreact removes the user's reaction, if any, which also uncounts it on the comment
+func (h *Handler) unreact(ctx context.Context, commentID, userID primitive.ObjectID) error {
+	_, err := h.repo.Reactions().RemoveReaction(ctx, commentID, userID)
+	return err
+}
+
+// getReactableComment loads the published comment in the path if the user may
+// see it, responding with the error otherwise
+func (h *Handler) getReactableComment(c *gin.Context, currentUserID *primitive.ObjectID) (*Comment, bool) {
+	commentID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "INVALID_ID", "Invalid comment ID")
+		return nil, false
+	}
+
+	// Held and hidden comments take no reactions
+	comment, err := h.repo.GetCommentByID(c.Request.Context(), commentID)
+	if err != nil || !comment.IsPublished() {
+		response.NotFound(c, "COMMENT_NOT_FOUND", "Comment not found")
+		return nil, false
+	}
+
+	anchor, err := h.anchorsRepo.GetAnchorByID(c.Request.Context(), comment.AnchorID)
+	if err != nil {
+		response.NotFound(c, "ANCHOR_NOT_FOUND", "Anchor not found")
+		return nil, false
+	}
+
+	if anchor.Visibility == anchors.VisibilityPrivate {
+		if currentUserID == nil || !anchor.IsMember(*currentUserID) {
+			response.Forbidden(c, "ACCESS_DENIED", "Cannot access comment on private anchor")
+			return nil, false
+		}
+	}
+
+	return comment, true
+}
+
+// getReactions returns the comment's reaction summary for the current user;
+// counts are left empty if they can't be fetched
+func (h *Handler) getReactions(c *gin.Context, commentID primitive.ObjectID) likes.ReactionSummary {
+	var currentUserID *primitive.ObjectID
+	if usr, exists := c.Get("user"); exists {
+		if user, ok := usr.(*auth.User); ok {
+			currentUserID = &user.ID
+		}
+	}
+
+	summary, err := h.repo.Reactions().GetSummary(c.Request.Context(), commentID, currentUserID)
+	if err != nil {
+		return likes.NewReactionSummary(nil, "")
+	}
+	return summary
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/xyz-asif/gotodo/internal/features/likes"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -12,13 +13,16 @@ import (
 )
 
 type Repository struct {
-	commentsCollection     *mongo.Collection
-	commentLikesCollection *mongo.Collection
+	commentsCollection       *mongo.Collection
+	commentLikesCollection   *mongo.Collection
+	commentFiltersCollection *mongo.Collection
+	reactions                *likes.ReactionStore
 }
 
 func NewRepository(db *mongo.Database) *Repository {
 	commentsCollection := db.Collection("comments")
 	commentLikesCollection := db.Collection("commentLikes")
+	commentFiltersCollection := db.Collection("comment_filters")
 
 	// Create indexes
 	commentsCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
@@ -43,6 +47,23 @@ func NewRepository(db *mongo.Database) *Repository {
 				{Key: "createdAt", Value: -1},
 			},
 		},
+		{
+			// Replies to a comment
+			Keys: bson.D{
+				{Key: "parentId", Value: 1},
+				{Key: "createdAt", Value: 1},
+			},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"parentId": bson.M{"$exists": true}}),
+		},
+		{
+			// Held and hidden comments on an anchor
+			Keys: bson.D{
+				{Key: "anchorId", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "createdAt", Value: 1},
+			},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"status": bson.M{"$exists": true}}),
+		},
 	})
 
 	commentLikesCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
@@ -55,12 +76,24 @@ func NewRepository(db *mongo.Database) *Repository {
 		},
 	})
 
+	commentFiltersCollection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
 	return &Repository{
-		commentsCollection:     commentsCollection,
-		commentLikesCollection: commentLikesCollection,
+		commentsCollection:       commentsCollection,
+		commentLikesCollection:   commentLikesCollection,
+		commentFiltersCollection: commentFiltersCollection,
+		reactions:                likes.NewReactionStore(commentLikesCollection, "commentId", commentsCollection),
 	}
 }
 
+// Reactions returns the reaction store over comment likes
+func (r *Repository) Reactions() *likes.ReactionStore {
+	return r.reactions
+}
+
 // CreateComment inserts a new comment
 func (r *Repository) CreateComment(ctx context.Context, comment *Comment) error {
 	comment.ID = primitive.NewObjectID()
@@ -112,30 +145,145 @@ func (r *Repository) UpdateComment(ctx context.Context, commentID primitive.Obje
 	return nil
 }
 
-// SoftDeleteComment soft deletes a comment
-func (r *Repository) SoftDeleteComment(ctx context.Context, commentID primitive.ObjectID) error {
+// SoftDeleteComment soft deletes a comment. A comment with replies becomes a
+// tombstone instead: its content is cleared but it stays in listings so the
+// replies keep their place. Reports whether the comment was tombstoned.
+func (r *Repository) SoftDeleteComment(ctx context.Context, commentID primitive.ObjectID) (bool, error) {
+	now := time.Now()
+
+	// Only without replies; checked in the same update so a reply can't slip in
 	result, err := r.commentsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": commentID, "deletedAt": nil, "replyCount": bson.M{"$not": bson.M{"$gt": 0}}},
+		bson.M{"$set": bson.M{"deletedAt": now, "updatedAt": now}},
+	)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount > 0 {
+		return false, nil
+	}
+
+	result, err = r.commentsCollection.UpdateOne(
 		ctx,
 		bson.M{"_id": commentID, "deletedAt": nil},
-		bson.M{"$set": bson.M{"deletedAt": time.Now(), "updatedAt": time.Now()}},
+		bson.M{"$set": bson.M{
+			"deletedAt": now,
+			"tombstone": true,
+			"content":   "",
+			"mentions":  []primitive.ObjectID{},
+			"updatedAt": now,
+		}},
 	)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount == 0 {
+		return false, errors.New("comment not found")
+	}
+	return true, nil
+}
 
+// AddReplyToParent counts a new reply on its parent. It fails if the parent
+// has been deleted, so call it before inserting the reply.
+func (r *Repository) AddReplyToParent(ctx context.Context, parentID primitive.ObjectID) error {
+	result, err := r.commentsCollection.UpdateOne(
+		ctx,
+		bson.M{"_id": parentID, "deletedAt": nil},
+		bson.M{"$inc": bson.M{"replyCount": 1}},
+	)
 	if err != nil {
 		return err
 	}
-
 	if result.MatchedCount == 0 {
 		return errors.New("comment not found")
 	}
-
 	return nil
 }
 
-// GetCommentsByAnchor retrieves comments for an anchor with pagination
-func (r *Repository) GetCommentsByAnchor(ctx context.Context, anchorID primitive.ObjectID, sort string, page, limit int) ([]Comment, int64, error) {
+// RemoveReplyFromParent uncounts a removed reply. A tombstone left without
+// replies is removed too, which in turn uncounts it on its own parent.
+func (r *Repository) RemoveReplyFromParent(ctx context.Context, parentID primitive.ObjectID) error {
+	for {
+		var parent Comment
+		err := r.commentsCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": parentID},
+			bson.M{"$inc": bson.M{"replyCount": -1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&parent)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil
+			}
+			return err
+		}
+		if !parent.Tombstone || parent.ReplyCount > 0 {
+			return nil
+		}
+
+		// No replies left to keep the tombstone for
+		result, err := r.commentsCollection.UpdateOne(ctx,
+			bson.M{"_id": parent.ID, "tombstone": true, "replyCount": bson.M{"$lte": 0}},
+			bson.M{"$unset": bson.M{"tombstone": ""}},
+		)
+		if err != nil {
+			return err
+		}
+		if result.ModifiedCount == 0 || parent.ParentID == nil {
+			return nil // replied to meanwhile, or a top-level comment
+		}
+		parentID = *parent.ParentID
+	}
+}
+
+// GetThreadComment retrieves a comment that is shown in threads: a live comment
+// or a tombstone, as selected by filter
+func (r *Repository) GetThreadComment(ctx context.Context, commentID primitive.ObjectID, filter ListFilter) (*Comment, error) {
+	var comment Comment
+	err := r.commentsCollection.FindOne(ctx, bson.M{"_id": commentID, "$or": filter.clauses()}).Decode(&comment)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("comment not found")
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListFilter selects the comments a viewer is shown. By default these are
+// published comments and tombstones, whose author is not shown anyway.
+type ListFilter struct {
+	ExcludeUserIDs []primitive.ObjectID // authors hidden from the viewer
+	ViewerID       *primitive.ObjectID  // also shows the viewer's own held and hidden comments
+	ViewerIsOwner  bool                 // also shows hidden comments on the viewer's anchor
+}
+
+func (f ListFilter) clauses() []bson.M {
+	published := bson.M{"deletedAt": nil, "status": nil}
+	if len(f.ExcludeUserIDs) > 0 {
+		published["userId"] = bson.M{"$nin": f.ExcludeUserIDs}
+	}
+	clauses := []bson.M{published, {"tombstone": true}}
+
+	if f.ViewerID != nil {
+		clauses = append(clauses, bson.M{"deletedAt": nil, "userId": *f.ViewerID, "status": bson.M{"$in": []string{StatusHeld, StatusHidden}}})
+	}
+	if f.ViewerIsOwner {
+		clauses = append(clauses, bson.M{"deletedAt": nil, "status": StatusHidden})
+	}
+	return clauses
+}
+
+// GetCommentsByAnchor retrieves top-level comments for an anchor with pagination
+func (r *Repository) GetCommentsByAnchor(ctx context.Context, anchorID primitive.ObjectID, sort string, page, limit int, listFilter ListFilter, excludeID *primitive.ObjectID) ([]Comment, int64, error) {
 	filter := bson.M{
-		"anchorId":  anchorID,
-		"deletedAt": nil,
+		"anchorId": anchorID,
+		"parentId": nil,
+		"$or":      listFilter.clauses(),
+	}
+	if excludeID != nil {
+		filter["_id"] = bson.M{"$ne": *excludeID}
 	}
 
 	// Determine sort order
@@ -173,91 +321,112 @@ func (r *Repository) GetCommentsByAnchor(ctx context.Context, anchorID primitive
 	return comments, total, nil
 }
 
-// CreateCommentLike creates a like (idempotent)
-func (r *Repository) CreateCommentLike(ctx context.Context, commentID, userID primitive.ObjectID) error {
-	like := CommentLike{
-		ID:        primitive.NewObjectID(),
-		CommentID: commentID,
-		UserID:    userID,
-		CreatedAt: time.Now(),
+// GetReplies retrieves a comment's direct replies, oldest first
+func (r *Repository) GetReplies(ctx context.Context, parentID primitive.ObjectID, page, limit int, listFilter ListFilter) ([]Comment, int64, error) {
+	filter := bson.M{
+		"parentId": parentID,
+		"$or":      listFilter.clauses(),
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.commentsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var replies []Comment
+	if err = cursor.All(ctx, &replies); err != nil {
+		return nil, 0, err
 	}
 
-	_, err := r.commentLikesCollection.InsertOne(ctx, like)
+	total, err := r.commentsCollection.CountDocuments(ctx, filter)
 	if err != nil {
-		if mongo.IsDuplicateKeyError(err) {
-			return nil // Already liked, idempotent
-		}
-		return err
+		return nil, 0, err
 	}
 
-	return nil
+	return replies, total, nil
 }
 
-// DeleteCommentLike removes a like (idempotent)
-func (r *Repository) DeleteCommentLike(ctx context.Context, commentID, userID primitive.ObjectID) error {
-	_, err := r.commentLikesCollection.DeleteOne(ctx, bson.M{
-		"commentId": commentID,
-		"userId":    userID,
-	})
-	return err
-}
+// SetCommentStatus moves a live comment from one moderation status to another;
+// "" is published. Reports false if the comment was not in the from status.
+func (r *Repository) SetCommentStatus(ctx context.Context, commentID primitive.ObjectID, from, to string) (bool, error) {
+	filter := bson.M{"_id": commentID, "deletedAt": nil, "status": nil}
+	if from != "" {
+		filter["status"] = from
+	}
 
-// ExistsCommentLike checks if a like exists
-func (r *Repository) ExistsCommentLike(ctx context.Context, commentID, userID primitive.ObjectID) (bool, error) {
-	count, err := r.commentLikesCollection.CountDocuments(ctx, bson.M{
-		"commentId": commentID,
-		"userId":    userID,
-	})
-	return count > 0, err
+	update := bson.M{"$set": bson.M{"status": to, "updatedAt": time.Now()}}
+	if to == "" {
+		update = bson.M{"$unset": bson.M{"status": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	}
+
+	result, err := r.commentsCollection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
 }
 
-// GetUserLikedComments batch checks which comments a user has liked
-func (r *Repository) GetUserLikedComments(ctx context.Context, userID primitive.ObjectID, commentIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
-	if len(commentIDs) == 0 {
-		return make(map[primitive.ObjectID]bool), nil
+// GetHeldComments retrieves an anchor's comments waiting for approval, oldest first
+func (r *Repository) GetHeldComments(ctx context.Context, anchorID primitive.ObjectID, page, limit int) ([]Comment, int64, error) {
+	filter := bson.M{
+		"anchorId":  anchorID,
+		"status":    StatusHeld,
+		"deletedAt": nil,
 	}
 
-	cursor, err := r.commentLikesCollection.Find(ctx, bson.M{
-		"userId":    userID,
-		"commentId": bson.M{"$in": commentIDs},
-	})
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.commentsCollection.Find(ctx, filter, opts)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
-	result := make(map[primitive.ObjectID]bool)
-	for cursor.Next(ctx) {
-		var like CommentLike
-		if err := cursor.Decode(&like); err == nil {
-			result[like.CommentID] = true
-		}
+	var comments []Comment
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.commentsCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	return result, nil
+	return comments, total, nil
 }
 
-// IncrementCommentLikeCount increments/decrements like count
-func (r *Repository) IncrementCommentLikeCount(ctx context.Context, commentID primitive.ObjectID, delta int) error {
-	_, err := r.commentsCollection.UpdateOne(
-		ctx,
-		bson.M{"_id": commentID},
-		bson.M{
-			"$inc": bson.M{"likeCount": delta},
-			"$set": bson.M{"updatedAt": time.Now()},
-		},
-	)
+// GetCommentFilter retrieves a user's keyword filter; users without one get an empty filter
+func (r *Repository) GetCommentFilter(ctx context.Context, userID primitive.ObjectID) (*CommentFilter, error) {
+	var filter CommentFilter
+	err := r.commentFiltersCollection.FindOne(ctx, bson.M{"userId": userID}).Decode(&filter)
 	if err != nil {
-		return err
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return &CommentFilter{UserID: userID, Keywords: []string{}}, nil
+		}
+		return nil, err
 	}
+	return &filter, nil
+}
 
-	// Ensure count doesn't go negative
-	if delta < 0 {
-		_, _ = r.commentsCollection.UpdateOne(ctx,
-			bson.M{"_id": commentID, "likeCount": bson.M{"$lt": 0}},
-			bson.M{"$set": bson.M{"likeCount": 0}},
-		)
+// SetCommentFilter replaces a user's keyword filter
+func (r *Repository) SetCommentFilter(ctx context.Context, userID primitive.ObjectID, keywords []string) (*CommentFilter, error) {
+	var filter CommentFilter
+	err := r.commentFiltersCollection.FindOneAndUpdate(ctx,
+		bson.M{"userId": userID},
+		bson.M{"$set": bson.M{"keywords": keywords, "updatedAt": time.Now()}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&filter)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	return &filter, nil
 }
@@ -1,10 +1,14 @@
 package comments
 
 import (
+	"context"
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
 	"github.com/xyz-asif/gotodo/internal/features/anchors"
 	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/features/follows"
 	"github.com/xyz-asif/gotodo/internal/features/notifications"
 	"github.com/xyz-asif/gotodo/internal/middleware"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -15,22 +19,42 @@ func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Con
 	repo := NewRepository(db)
 	authRepo := auth.NewRepository(db)
 	anchorsRepo := anchors.NewRepository(db)
+	followsRepo := follows.NewRepository(db)
 
 	// Initialize notification service
 	notificationService := notifications.GetService(db)
 
 	// Initialize handler with notification service
-	handler := NewHandler(repo, authRepo, anchorsRepo, notificationService, cfg)
+	handler := NewHandler(repo, authRepo, anchorsRepo, followsRepo, notificationService, cfg)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(authRepo, cfg)
 	optionalAuth := middleware.OptionalAuthMiddleware(authRepo, cfg)
 
+	// Mark comment likes stored before reactions existed as the "like" reaction,
+	// then count them by type on comments that predate the per-type counts
+	go func() {
+		migrated, err := repo.Reactions().MigrateLegacyLikes(context.Background())
+		if err != nil {
+			log.Printf("comments: failed to migrate legacy comment likes: %v", err)
+		} else if migrated > 0 {
+			log.Printf("comments: migrated %d legacy comment likes to reactions", migrated)
+		}
+
+		backfilled, err := repo.Reactions().BackfillCounts(context.Background())
+		if err != nil {
+			log.Printf("comments: failed to backfill reaction counts: %v", err)
+		} else if backfilled > 0 {
+			log.Printf("comments: backfilled reaction counts on %d comments", backfilled)
+		}
+	}()
+
 	// Anchor comment routes
 	anchorComments := router.Group("/anchors/:id/comments")
 	{
 		anchorComments.POST("", authMiddleware, handler.AddComment)
 		anchorComments.GET("", optionalAuth, handler.ListComments)
+		anchorComments.GET("/held", authMiddleware, handler.ListHeldComments)
 	}
 
 	// Direct comment routes
@@ -39,7 +63,23 @@ func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Con
 		comments.GET("/:id", optionalAuth, handler.GetComment)
 		comments.PATCH("/:id", authMiddleware, handler.EditComment)
 		comments.DELETE("/:id", authMiddleware, handler.DeleteComment)
+		comments.POST("/:id/replies", authMiddleware, handler.AddReply)
+		comments.GET("/:id/replies", optionalAuth, handler.ListReplies)
 		comments.POST("/:id/like", authMiddleware, handler.LikeComment)
 		comments.GET("/:id/like/status", authMiddleware, handler.GetCommentLikeStatus)
+		comments.PUT("/:id/reaction", authMiddleware, handler.SetCommentReaction)
+		comments.DELETE("/:id/reaction", authMiddleware, handler.RemoveCommentReaction)
+		comments.GET("/:id/reactions", optionalAuth, handler.ListCommentReactions)
+		comments.POST("/:id/hide", authMiddleware, handler.ModerateComment)
+		comments.POST("/:id/pin", authMiddleware, handler.PinComment)
+		comments.POST("/:id/approve", authMiddleware, handler.ApproveComment)
+	}
+
+	// Keyword filter for comments on the user's own anchors
+	me := router.Group("/users/me")
+	me.Use(authMiddleware)
+	{
+		me.GET("/comment-filter", handler.GetCommentFilter)
+		me.PUT("/comment-filter", handler.UpdateCommentFilter)
 	}
 }
@@ -62,3 +62,62 @@ func ValidateCommentListQuery(query *CommentListQuery) error {
 
 	return nil
 }
+
+func ValidateCommentModerationRequest(req *CommentModerationRequest) error {
+	if req.Action != "hide" && req.Action != "unhide" {
+		return errors.New("action must be 'hide' or 'unhide'")
+	}
+	return nil
+}
+
+func ValidateCommentPinRequest(req *CommentPinRequest) error {
+	if req.Action != "pin" && req.Action != "unpin" {
+		return errors.New("action must be 'pin' or 'unpin'")
+	}
+	return nil
+}
+
+func ValidateUpdateCommentFilterRequest(req *UpdateCommentFilterRequest) error {
+	if len(req.Keywords) > 100 {
+		return errors.New("at most 100 keywords are allowed")
+	}
+
+	req.Keywords = NormalizeKeywords(req.Keywords)
+	for _, keyword := range req.Keywords {
+		if len(keyword) > 50 {
+			return errors.New("keywords must be 50 characters or less")
+		}
+	}
+
+	return nil
+}
+
+func ValidateHeldListQuery(query *HeldListQuery) error {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+
+	if query.Limit < 1 {
+		query.Limit = 20
+	}
+	if query.Limit > 50 {
+		query.Limit = 50
+	}
+
+	return nil
+}
+
+func ValidateReplyListQuery(query *ReplyListQuery) error {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+
+	if query.Limit < 1 {
+		query.Limit = 20
+	}
+	if query.Limit > 50 {
+		query.Limit = 50
+	}
+
+	return nil
+}
@@ -0,0 +1,340 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/features/anchor_follows"
+	"github.com/xyz-asif/gotodo/internal/features/anchors"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/features/bookmarks"
+	"github.com/xyz-asif/gotodo/internal/features/comments"
+	"github.com/xyz-asif/gotodo/internal/features/follows"
+	"github.com/xyz-asif/gotodo/internal/features/likes"
+	"github.com/xyz-asif/gotodo/internal/features/notifications"
+	"github.com/xyz-asif/gotodo/internal/pkg/safehttp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxMediaBytes caps a single downloaded media file
+const maxMediaBytes = 200 << 20
+
+// archive writes one user's data into a ZIP:
+//
+//	manifest.json
+//	profile.json, blocks.json
+//	anchors/<anchorId>/anchor.json, items.json, media/<itemId>.<ext>
+//	comments.json, likes.json, comment_likes.json
+//	following.json, followers.json, anchor_follows.json
+//	notifications.json
+//	media/profile-picture.<ext>, media/cover-image.<ext>
+type archive struct {
+	db       *mongo.Database
+	client   *http.Client
+	zw       *zip.Writer
+	userID   primitive.ObjectID
+	manifest *Manifest
+}
+
+func newArchive(db *mongo.Database, client *http.Client, w io.Writer, userID primitive.ObjectID) *archive {
+	return &archive{
+		db:     db,
+		client: client,
+		zw:     zip.NewWriter(w),
+		userID: userID,
+		manifest: &Manifest{
+			UserID:      userID,
+			GeneratedAt: time.Now(),
+			Counts:      make(map[string]int),
+		},
+	}
+}
+
+// build writes every section and closes the ZIP
+func (a *archive) build(ctx context.Context) error {
+	sections := []func(context.Context) error{
+		a.writeProfile,
+		a.writeAnchors,
+		a.writeEngagement,
+		a.writeNotifications,
+	}
+	for _, section := range sections {
+		if err := section(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := a.writeJSON("manifest.json", a.manifest); err != nil {
+		return err
+	}
+	return a.zw.Close()
+}
+
+func (a *archive) writeProfile(ctx context.Context) error {
+	var user auth.User
+	if err := a.db.Collection("users").FindOne(ctx, bson.M{"_id": a.userID}).Decode(&user); err != nil {
+		return fmt.Errorf("load user: %w", err)
+	}
+	if err := a.writeJSON("profile.json", user); err != nil {
+		return err
+	}
+
+	if user.ProfilePictureURL != "" {
+		a.writeMedia(ctx, "media/profile-picture", user.ProfilePictureURL)
+	}
+	if user.CoverImageURL != "" {
+		a.writeMedia(ctx, "media/cover-image", user.CoverImageURL)
+	}
+
+	// Blocked users, with usernames so the list is readable on its own
+	type blockedUser struct {
+		ID       primitive.ObjectID `json:"id"`
+		Username string             `json:"username,omitempty"`
+	}
+	blocks := make([]blockedUser, 0, len(user.BlockedUsers))
+	usernames := make(map[primitive.ObjectID]string)
+	if len(user.BlockedUsers) > 0 {
+		var blocked []auth.User
+		if err := a.findAll(ctx, "users", bson.M{"_id": bson.M{"$in": user.BlockedUsers}}, &blocked); err != nil {
+			return err
+		}
+		for _, u := range blocked {
+			usernames[u.ID] = u.Username
+		}
+	}
+	for _, id := range user.BlockedUsers {
+		blocks = append(blocks, blockedUser{ID: id, Username: usernames[id]})
+	}
+	a.manifest.Counts["blocks"] = len(blocks)
+	return a.writeJSON("blocks.json", blocks)
+}
+
+func (a *archive) writeAnchors(ctx context.Context) error {
+	var anchorList []anchors.Anchor
+	if err := a.findAll(ctx, "anchors", bson.M{"userId": a.userID, "deletedAt": nil}, &anchorList); err != nil {
+		return err
+	}
+	a.manifest.Counts["anchors"] = len(anchorList)
+
+	for _, anchor := range anchorList {
+		dir := "anchors/" + anchor.ID.Hex() + "/"
+		if err := a.writeJSON(dir+"anchor.json", anchor); err != nil {
+			return err
+		}
+
+		var items []anchors.Item
+		err := a.findAll(ctx, "items", bson.M{"anchorId": anchor.ID, "deletedAt": nil}, &items,
+			options.Find().SetSort(bson.D{{Key: "position", Value: 1}}))
+		if err != nil {
+			return err
+		}
+		if err := a.writeJSON(dir+"items.json", items); err != nil {
+			return err
+		}
+		a.manifest.Counts["items"] += len(items)
+
+		for _, item := range items {
+			if mediaURL, name := itemMedia(item); mediaURL != "" {
+				a.writeMedia(ctx, dir+"media/"+name, mediaURL)
+			}
+		}
+	}
+	return nil
+}
+
+// itemMedia returns the uploaded file behind an item and its name in the archive, without extension
+func itemMedia(item anchors.Item) (string, string) {
+	name := item.ID.Hex()
+	switch {
+	case item.ImageData != nil:
+		return item.ImageData.CloudinaryURL, name
+	case item.AudioData != nil:
+		return item.AudioData.CloudinaryURL, name
+	case item.FileData != nil:
+		return item.FileData.CloudinaryURL, name + "-" + sanitizeFilename(strings.TrimSuffix(item.FileData.Filename, path.Ext(item.FileData.Filename)))
+	}
+	return "", ""
+}
+
+func (a *archive) writeEngagement(ctx context.Context) error {
+	sections := []struct {
+		name       string
+		collection string
+		filter     bson.M
+		into       interface{}
+	}{
+		{"comments", "comments", bson.M{"userId": a.userID, "deletedAt": nil}, &[]comments.Comment{}},
+		{"likes", "likes", bson.M{"userId": a.userID}, &[]likes.Like{}},
+		{"comment_likes", "commentLikes", bson.M{"userId": a.userID}, &[]comments.CommentLike{}},
+		{"following", "follows", bson.M{"followerId": a.userID}, &[]follows.Follow{}},
+		{"followers", "follows", bson.M{"followingId": a.userID}, &[]follows.Follow{}},
+		{"anchor_follows", "anchor_follows", bson.M{"userId": a.userID}, &[]anchor_follows.AnchorFollow{}},
+		{"saved", "bookmarks", bson.M{"userId": a.userID}, &[]bookmarks.Bookmark{}},
+		{"saved_folders", "bookmark_folders", bson.M{"userId": a.userID}, &[]bookmarks.Folder{}},
+	}
+
+	for _, s := range sections {
+		if err := a.findAll(ctx, s.collection, s.filter, s.into,
+			options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})); err != nil {
+			return err
+		}
+		if err := a.writeJSON(s.name+".json", s.into); err != nil {
+			return err
+		}
+		a.manifest.Counts[s.name] = reflect.ValueOf(s.into).Elem().Len()
+	}
+	return nil
+}
+
+func (a *archive) writeNotifications(ctx context.Context) error {
+	var list []notifications.Notification
+	if err := a.findAll(ctx, "notifications", bson.M{"recipientId": a.userID}, &list,
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})); err != nil {
+		return err
+	}
+	a.manifest.Counts["notifications"] = len(list)
+	return a.writeJSON("notifications.json", list)
+}
+
+// findAll decodes every matching document into out, a pointer to a slice
+func (a *archive) findAll(ctx context.Context, collection string, filter bson.M, out interface{}, opts ...*options.FindOptions) error {
+	cursor, err := a.db.Collection(collection).Find(ctx, filter, opts...)
+	if err != nil {
+		return fmt.Errorf("load %s: %w", collection, err)
+	}
+	if err := cursor.All(ctx, out); err != nil {
+		return fmt.Errorf("load %s: %w", collection, err)
+	}
+	return nil
+}
+
+// writeJSON adds an indented JSON file; nil slices are written as []
+func (a *archive) writeJSON(name string, v interface{}) error {
+	w, err := a.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+	if string(data) == "null" {
+		data = []byte("[]")
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+// writeMedia downloads an uploaded file into the archive. Failures are listed
+// in the manifest instead of failing the export.
+func (a *archive) writeMedia(ctx context.Context, name, mediaURL string) {
+	name += mediaExtension(mediaURL)
+	if err := a.copyMedia(ctx, name, mediaURL); err != nil {
+		a.manifest.MissingMedia = append(a.manifest.MissingMedia, MissingMedia{
+			Path:  name,
+			URL:   mediaURL,
+			Error: err.Error(),
+		})
+		return
+	}
+	a.manifest.Counts["media"]++
+}
+
+// copyMedia downloads to a temporary file first, so a download that fails
+// partway does not leave a truncated entry in the archive
+func (a *archive) copyMedia(ctx context.Context, name, mediaURL string) error {
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return fmt.Errorf("unsupported media URL")
+	}
+	if err := safehttp.CheckURL(u); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed: %s", resp.Status)
+	}
+	if resp.ContentLength > maxMediaBytes {
+		return fmt.Errorf("file too large (%d bytes)", resp.ContentLength)
+	}
+
+	tmp, err := os.CreateTemp("", "export-media-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxMediaBytes+1))
+	if err != nil {
+		return err
+	}
+	if n > maxMediaBytes {
+		return fmt.Errorf("file too large (over %d bytes)", maxMediaBytes)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// Stored, not deflated: media is already compressed
+	w, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, tmp)
+	return err
+}
+
+// mediaExtension returns the file extension from a media URL's path
+func mediaExtension(mediaURL string) string {
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return ""
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	if len(ext) > 10 {
+		return ""
+	}
+	return ext
+}
+
+// sanitizeFilename keeps letters, digits, dots, dashes and underscores
+func sanitizeFilename(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() > 80 {
+		return b.String()[:80]
+	}
+	return b.String()
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Handler struct {
+	repo *Repository
+}
+
+func NewHandler(repo *Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// RequestExport godoc
+// @Summary Request a data export
+// @Description Start building a ZIP of the current user's data in the background. If an export is already in progress it is returned instead of starting another.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} response.APIResponse{data=Export}
+// @Failure 401 {object} response.APIResponse
+// @Router /users/me/export [post]
+func (h *Handler) RequestExport(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	export, err := h.repo.Create(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to start export", "DATABASE_ERROR")
+		return
+	}
+
+	response.Respond(c, http.StatusAccepted, true, "Export started", export)
+}
+
+// GetExport godoc
+// @Summary Get a data export
+// @Description Get an export's status. Once it is ready, the ZIP archive is streamed instead; expired exports must be requested again.
+// @Tags users
+// @Produce json,application/zip
+// @Security BearerAuth
+// @Param id path string true "Export ID"
+// @Success 200 {object} response.APIResponse{data=Export}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /users/me/export/{id} [get]
+func (h *Handler) GetExport(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	exportID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid export ID", "INVALID_ID")
+		return
+	}
+
+	export, err := h.repo.GetForUser(c.Request.Context(), exportID, currentUser.ID)
+	if err != nil {
+		if errors.Is(err, ErrExportNotFound) {
+			response.NotFound(c, "Export not found", "NOT_FOUND")
+			return
+		}
+		response.InternalServerError(c, "Failed to fetch export", "DATABASE_ERROR")
+		return
+	}
+
+	if export.Status != StatusReady || export.FileID == nil {
+		response.Success(c, export)
+		return
+	}
+
+	file, err := h.repo.OpenArchive(*export.FileID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to open export archive", "STORAGE_ERROR")
+		return
+	}
+	defer file.Close()
+
+	filename := fmt.Sprintf("export-%s.zip", export.CreatedAt.Format("2006-01-02"))
+	c.DataFromReader(http.StatusOK, export.Size, "application/zip", file, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, filename),
+		"Cache-Control":       "private, no-store",
+	})
+}
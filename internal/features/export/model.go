@@ -0,0 +1,47 @@
+package export
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Export status constants
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusReady   = "ready"
+	StatusFailed  = "failed"
+	StatusExpired = "expired" // archive deleted after its TTL
+)
+
+// Export is a request for a ZIP archive of everything a user owns.
+// The archive is stored in GridFS so any instance can serve it.
+type Export struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID  `bson:"userId" json:"-"`
+	Status      string              `bson:"status" json:"status"`
+	FileID      *primitive.ObjectID `bson:"fileId,omitempty" json:"-"`
+	Size        int64               `bson:"size,omitempty" json:"size,omitempty"`
+	Attempts    int                 `bson:"attempts" json:"-"`
+	LeaseUntil  *time.Time          `bson:"leaseUntil,omitempty" json:"-"`
+	LastError   string              `bson:"lastError,omitempty" json:"-"`
+	CreatedAt   time.Time           `bson:"createdAt" json:"createdAt"`
+	CompletedAt *time.Time          `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+	ExpiresAt   *time.Time          `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"` // set once ready
+}
+
+// Manifest is written to the archive root and describes its contents
+type Manifest struct {
+	UserID       primitive.ObjectID `json:"userId"`
+	GeneratedAt  time.Time          `json:"generatedAt"`
+	Counts       map[string]int     `json:"counts"`
+	MissingMedia []MissingMedia     `json:"missingMedia,omitempty"`
+}
+
+// MissingMedia records a file that could not be downloaded into the archive
+type MissingMedia struct {
+	Path  string `json:"path"`
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
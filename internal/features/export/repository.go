@@ -0,0 +1,236 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BucketName is the GridFS bucket holding export archives
+const BucketName = "exports"
+
+var ErrExportNotFound = errors.New("export not found")
+
+type Repository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+	bucket     *gridfs.Bucket
+}
+
+func NewRepository(db *mongo.Database) *Repository {
+	collection := db.Collection("exports")
+
+	_, _ = collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "createdAt", Value: -1},
+			},
+		},
+		{
+			// Worker queue
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "leaseUntil", Value: 1},
+			},
+		},
+		{
+			// Expiry sweep
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"status": StatusReady}),
+		},
+	})
+
+	// Only fails on invalid bucket options
+	bucket, _ := gridfs.NewBucket(db, options.GridFSBucket().SetName(BucketName))
+
+	return &Repository{
+		db:         db,
+		collection: collection,
+		bucket:     bucket,
+	}
+}
+
+// Create queues an export for the user. If one is already queued or running,
+// that one is returned instead.
+func (r *Repository) Create(ctx context.Context, userID primitive.ObjectID) (*Export, error) {
+	var export Export
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"userId": userID,
+			"status": bson.M{"$in": []string{StatusPending, StatusRunning}},
+		},
+		bson.M{
+			"$setOnInsert": bson.M{
+				"status":    StatusPending,
+				"attempts":  0,
+				"createdAt": time.Now(),
+			},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&export)
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// GetForUser returns one of the user's exports
+func (r *Repository) GetForUser(ctx context.Context, exportID, userID primitive.ObjectID) (*Export, error) {
+	var export Export
+	err := r.collection.FindOne(ctx, bson.M{"_id": exportID, "userId": userID}).Decode(&export)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrExportNotFound
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+// Claim leases the oldest export waiting to be built, including ones whose
+// worker died mid-build. Returns nil when there is nothing to do.
+func (r *Repository) Claim(ctx context.Context, now time.Time, lease time.Duration) (*Export, error) {
+	var export Export
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"status": bson.M{"$in": []string{StatusPending, StatusRunning}},
+			"$or": []bson.M{
+				{"leaseUntil": nil},
+				{"leaseUntil": bson.M{"$lte": now}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{"status": StatusRunning, "leaseUntil": now.Add(lease)},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&export)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+// OpenUpload starts a GridFS upload for the export's archive
+func (r *Repository) OpenUpload(export *Export) (*gridfs.UploadStream, error) {
+	return r.bucket.OpenUploadStream(
+		"export-"+export.ID.Hex()+".zip",
+		options.GridFSUpload().SetMetadata(bson.M{
+			"userId":   export.UserID,
+			"exportId": export.ID,
+		}),
+	)
+}
+
+// MarkReady records the finished archive. If the lease was lost meanwhile,
+// the archive is discarded and false is returned.
+func (r *Repository) MarkReady(ctx context.Context, export *Export, fileID primitive.ObjectID, size int64, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": export.ID, "leaseUntil": export.LeaseUntil},
+		bson.M{
+			"$set": bson.M{
+				"status":      StatusReady,
+				"fileId":      fileID,
+				"size":        size,
+				"completedAt": now,
+				"expiresAt":   expiresAt,
+			},
+			"$unset": bson.M{"leaseUntil": "", "lastError": ""},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount == 0 {
+		_ = r.DeleteFile(fileID)
+		return false, nil
+	}
+
+	export.Status = StatusReady
+	export.FileID = &fileID
+	export.Size = size
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	return true, nil
+}
+
+// RecordFailure stores the error and schedules another attempt at retryAt,
+// or marks the export failed when giveUp is set
+func (r *Repository) RecordFailure(ctx context.Context, export *Export, cause error, retryAt time.Time, giveUp bool) error {
+	set := bson.M{
+		"lastError":  cause.Error(),
+		"leaseUntil": retryAt,
+	}
+	if giveUp {
+		set["status"] = StatusFailed
+	}
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": export.ID, "leaseUntil": export.LeaseUntil},
+		bson.M{"$set": set},
+	)
+	return err
+}
+
+// ExpireDue deletes archives past their expiry and marks their exports expired
+func (r *Repository) ExpireDue(ctx context.Context, now time.Time) (int, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"status":    StatusReady,
+		"expiresAt": bson.M{"$lte": now},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var due []Export
+	if err := cursor.All(ctx, &due); err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, export := range due {
+		if export.FileID != nil {
+			if err := r.DeleteFile(*export.FileID); err != nil {
+				return expired, err
+			}
+		}
+		_, err := r.collection.UpdateOne(ctx,
+			bson.M{"_id": export.ID, "status": StatusReady},
+			bson.M{
+				"$set":   bson.M{"status": StatusExpired},
+				"$unset": bson.M{"fileId": ""},
+			},
+		)
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// OpenArchive opens a finished archive for reading
+func (r *Repository) OpenArchive(fileID primitive.ObjectID) (*gridfs.DownloadStream, error) {
+	return r.bucket.OpenDownloadStream(fileID)
+}
+
+// DeleteFile removes an archive; a file that is already gone is not an error
+func (r *Repository) DeleteFile(fileID primitive.ObjectID) error {
+	if err := r.bucket.Delete(fileID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/config"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/middleware"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RegisterRoutes starts the export worker and registers the export routes
+func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config) {
+	repo := NewRepository(db)
+	authRepo := auth.NewRepository(db)
+
+	// Every instance runs a worker; leases keep an export from being built twice
+	ttl := time.Duration(cfg.ExportTTLHours) * time.Hour
+	NewWorker(db, repo, ttl).Start(context.Background())
+
+	handler := NewHandler(repo)
+	authMiddleware := middleware.NewAuthMiddleware(authRepo, cfg)
+
+	me := router.Group("/users/me")
+	me.Use(authMiddleware)
+	{
+		me.POST("/export", handler.RequestExport)
+		me.GET("/export/:id", handler.GetExport)
+	}
+}
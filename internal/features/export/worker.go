@@ -0,0 +1,135 @@
+package export
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/pkg/safehttp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Worker settings
+const (
+	pollInterval  = 10 * time.Second
+	leaseDuration = 15 * time.Minute // an archive is built in one go; a crashed worker's export is retried after this
+	maxAttempts   = 3
+	retryBackoff  = time.Minute // doubled after each failed attempt
+	mediaTimeout  = 2 * time.Minute
+)
+
+// Worker builds queued exports and deletes expired archives
+type Worker struct {
+	db     *mongo.Database
+	repo   *Repository
+	client *http.Client
+	ttl    time.Duration
+}
+
+func NewWorker(db *mongo.Database, repo *Repository, ttl time.Duration) *Worker {
+	return &Worker{
+		db:     db,
+		repo:   repo,
+		client: safehttp.NewClient(mediaTimeout), // media URLs such as profile pictures can point anywhere
+		ttl:    ttl,
+	}
+}
+
+// Start processes exports until ctx is cancelled
+func (w *Worker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			if n, err := w.repo.ExpireDue(ctx, time.Now()); err != nil {
+				log.Printf("Export expiry error: %v", err)
+			} else if n > 0 {
+				log.Printf("Expired %d data exports", n)
+			}
+
+			for {
+				processed, err := w.ProcessNext(ctx)
+				if err != nil {
+					log.Printf("Export worker error: %v", err)
+					break
+				}
+				if !processed {
+					break
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// ProcessNext claims one export and builds its archive.
+// It reports false when no export was waiting.
+func (w *Worker) ProcessNext(ctx context.Context) (bool, error) {
+	export, err := w.repo.Claim(ctx, time.Now(), leaseDuration)
+	if err != nil {
+		return false, err
+	}
+	if export == nil {
+		return false, nil
+	}
+
+	if err := w.build(ctx, export); err != nil {
+		giveUp := export.Attempts >= maxAttempts
+		retryAt := time.Now().Add(retryBackoff << (export.Attempts - 1))
+		log.Printf("Export %s failed (attempt %d): %v", export.ID.Hex(), export.Attempts, err)
+		return true, w.repo.RecordFailure(ctx, export, err, retryAt, giveUp)
+	}
+	return true, nil
+}
+
+// build streams the archive into GridFS and marks the export ready
+func (w *Worker) build(ctx context.Context, export *Export) error {
+	upload, err := w.repo.OpenUpload(export)
+	if err != nil {
+		return err
+	}
+
+	counter := &countingWriter{w: upload}
+	if err := newArchive(w.db, w.client, counter, export.UserID).build(ctx); err != nil {
+		_ = upload.Abort()
+		return err
+	}
+	if err := upload.Close(); err != nil {
+		return err
+	}
+
+	fileID := upload.FileID.(primitive.ObjectID)
+	ok, err := w.repo.MarkReady(ctx, export, fileID, counter.n, w.ttl)
+	if err != nil {
+		_ = w.repo.DeleteFile(fileID)
+		return err
+	}
+	if !ok {
+		_ = w.repo.DeleteFile(fileID)
+		log.Printf("Export %s was taken over by another worker; discarded archive", export.ID.Hex())
+		return nil
+	}
+	log.Printf("Export %s for user %s ready (%d bytes)", export.ID.Hex(), export.UserID.Hex(), counter.n)
+	return nil
+}
+
+// countingWriter records how many bytes pass through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -3,6 +3,7 @@ package feed
 import (
 	"time"
 
+	"github.com/xyz-asif/gotodo/internal/features/likes"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -51,9 +52,10 @@ type FeedLikeSummary struct {
 
 // FeedEngagement represents the current user's engagement with an anchor
 type FeedEngagement struct {
-	HasLiked    bool            `json:"hasLiked"`
-	HasCloned   bool            `json:"hasCloned"`
-	LikeSummary FeedLikeSummary `json:"likeSummary"`
+	HasLiked    bool                  `json:"hasLiked"` // true for any reaction
+	HasCloned   bool                  `json:"hasCloned"`
+	LikeSummary FeedLikeSummary       `json:"likeSummary"`
+	Reactions   likes.ReactionSummary `json:"reactions"`
 }
 
 // FeedPreviewItem represents a single item preview in the feed
@@ -2,6 +2,7 @@ package feed
 
 import (
 	"context"
+	"log"
 
 	"github.com/xyz-asif/gotodo/internal/features/anchor_follows"
 	"github.com/xyz-asif/gotodo/internal/features/anchors"
@@ -103,6 +104,10 @@ func (s *Service) GetHomeFeed(
 		blockedUserIDs = currentUser.BlockedUsers
 	}
 
+	// Shadow-banned authors are dropped silently, like blocked ones
+	hiddenUserIDs := s.getHiddenUserIDs(ctx, &userID)
+	blockedUserIDs = append(blockedUserIDs, hiddenUserIDs...)
+
 	anchorsList, err := s.feedRepo.GetFeedAnchors(ctx, feedUserIDs, blockedUserIDs, cursor, query.Limit+1)
 	if err != nil {
 		return nil, err
@@ -158,7 +163,7 @@ func (s *Service) GetHomeFeed(
 	}
 
 	// 9. Enrich Engagement
-	engagementMap, err := s.enrichWithEngagement(ctx, anchorsList, userID, followingIDs)
+	engagementMap, err := s.enrichWithEngagement(ctx, anchorsList, userID, followingIDs, hiddenUserIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -237,6 +242,17 @@ func (s *Service) GetHomeFeed(
 	}, nil
 }
 
+// getHiddenUserIDs returns shadow-banned and deleted users to exclude for the viewer.
+// On error nothing is hidden, matching how blocked users are handled.
+func (s *Service) getHiddenUserIDs(ctx context.Context, viewerID *primitive.ObjectID) []primitive.ObjectID {
+	ids, err := s.authRepo.GetHiddenUserIDs(ctx, viewerID)
+	if err != nil {
+		log.Printf("Failed to load hidden users: %v", err)
+		return nil
+	}
+	return ids
+}
+
 func (s *Service) getFollowingAnchorsForFeed(ctx context.Context, userID primitive.ObjectID) (FollowingAnchorsSection, error) {
 	// 1. Get user follows (limit 10 for feed section)
 	follows, total, err := s.anchorFollowsRepo.GetUserFollowingAnchors(ctx, userID, 1, 10)
@@ -342,14 +358,20 @@ func (s *Service) enrichWithEngagement(
 	anchorsList []anchors.Anchor,
 	userID primitive.ObjectID,
 	followingIDs []primitive.ObjectID,
+	hiddenUserIDs []primitive.ObjectID,
 ) (map[primitive.ObjectID]*FeedEngagement, error) {
 	anchorIDs := make([]primitive.ObjectID, len(anchorsList))
 	for i, a := range anchorsList {
 		anchorIDs[i] = a.ID
 	}
 
-	// Batch get liked status
-	likedMap, err := s.likesRepo.GetUserLikedAnchors(ctx, userID, anchorIDs)
+	// Batch get reaction counts and the user's own reactions
+	reactionCounts, err := s.likesRepo.Reactions().CountReactions(ctx, anchorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	reactionMap, err := s.likesRepo.Reactions().GetUserReactions(ctx, userID, anchorIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -369,16 +391,17 @@ func (s *Service) enrichWithEngagement(
 	// We'll proceed with sequential for simplicity as specified in algorithm step 7g.
 
 	for _, a := range anchorsList {
-		likeSummary, err := s.getLikeSummary(ctx, a.ID, a.LikeCount, followingIDs)
+		likeSummary, err := s.getLikeSummary(ctx, a.ID, a.LikeCount, followingIDs, hiddenUserIDs)
 		if err != nil {
 			// Log error but continue? For now return error
 			return nil, err
 		}
 
 		engagementMap[a.ID] = &FeedEngagement{
-			HasLiked:    likedMap[a.ID],
+			HasLiked:    reactionMap[a.ID] != "",
 			HasCloned:   clonedMap[a.ID],
 			LikeSummary: *likeSummary,
+			Reactions:   likes.NewReactionSummary(reactionCounts[a.ID], reactionMap[a.ID]),
 		}
 	}
 
@@ -440,9 +463,10 @@ func (s *Service) getLikeSummary(
 	anchorID primitive.ObjectID,
 	totalCount int,
 	followingIDs []primitive.ObjectID,
+	hiddenUserIDs []primitive.ObjectID,
 ) (*FeedLikeSummary, error) {
 	// Get recent likers (limit 20 to find matches)
-	likers, err := s.likesRepo.GetRecentLikers(ctx, anchorID, 20)
+	likers, err := s.likesRepo.GetRecentLikers(ctx, anchorID, 20, hiddenUserIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -547,6 +571,8 @@ func (s *Service) GetDiscoverFeed(
 			blockedUserIDs = currentUser.BlockedUsers
 		}
 	}
+	hiddenUserIDs := s.getHiddenUserIDs(ctx, userID)
+	blockedUserIDs = append(blockedUserIDs, hiddenUserIDs...)
 
 	anchorsList, err := s.feedRepo.GetDiscoverAnchors(
 		ctx, excludeUserIDs, blockedUserIDs, query.Category, tagPtr, cursor, query.Limit+1,
@@ -603,19 +629,26 @@ func (s *Service) GetDiscoverFeed(
 	// 8. Enrich with engagement (if authenticated)
 	var engagementMap map[primitive.ObjectID]*FeedEngagement
 	if isAuthenticated {
-		engagementMap, err = s.enrichWithEngagement(ctx, anchorsList, *userID, followingIDs)
+		engagementMap, err = s.enrichWithEngagement(ctx, anchorsList, *userID, followingIDs, hiddenUserIDs)
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		// Build empty engagement for unauthenticated
+		anchorIDs := make([]primitive.ObjectID, len(anchorsList))
+		for i, a := range anchorsList {
+			anchorIDs[i] = a.ID
+		}
+		reactionCounts, _ := s.likesRepo.Reactions().CountReactions(ctx, anchorIDs)
+
 		engagementMap = make(map[primitive.ObjectID]*FeedEngagement)
 		for _, a := range anchorsList {
-			likeSummary, _ := s.getLikeSummary(ctx, a.ID, a.LikeCount, nil)
+			likeSummary, _ := s.getLikeSummary(ctx, a.ID, a.LikeCount, nil, hiddenUserIDs)
 			engagementMap[a.ID] = &FeedEngagement{
 				HasLiked:    false,
 				HasCloned:   false,
 				LikeSummary: *likeSummary,
+				Reactions:   likes.NewReactionSummary(reactionCounts[a.ID], ""),
 			}
 		}
 	}
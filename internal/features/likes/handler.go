@@ -1,7 +1,6 @@
 package likes
 
 import (
-	"context"
 	"math"
 
 	"github.com/gin-gonic/gin"
@@ -96,8 +95,8 @@ func (h *Handler) LikeAction(c *gin.Context) {
 		return
 	}
 
-	// Check access - user must be owner OR anchor must be public/unlisted
-	if !anchor.IsOwnedBy(currentUser.ID) {
+	// Check access - user must be a member OR anchor must be public/unlisted
+	if !anchor.IsMember(currentUser.ID) {
 		if anchor.Visibility != anchors.VisibilityPublic && anchor.Visibility != anchors.VisibilityUnlisted {
 			response.Forbidden(c, "ACCESS_DENIED", "Cannot like private anchor")
 			return
@@ -107,55 +106,18 @@ func (h *Handler) LikeAction(c *gin.Context) {
 	var hasLiked bool
 
 	if req.Action == "like" {
-		// Check if already liked (to prevent duplicate notifications)
-		wasAlreadyLiked, _ := h.repo.ExistsLike(c.Request.Context(), anchorID, currentUser.ID)
-
-		err = h.repo.CreateLike(c.Request.Context(), anchorID, currentUser.ID)
-		if err != nil {
+		// A like keeps any other reaction the user already has
+		if _, err := h.react(c.Request.Context(), anchor, currentUser.ID, ReactionLike, false); err != nil {
 			response.InternalServerError(c, "LIKE_FAILED", "Failed to like anchor")
 			return
 		}
-
-		// Only increment if this is a new like (CreateLike is idempotent)
-		if !wasAlreadyLiked {
-			_ = h.anchorsRepo.IncrementLikeCount(c.Request.Context(), anchorID, 1)
-
-			// Create notification (async) - only for NEW likes
-			if anchor.UserID != currentUser.ID {
-				go func() {
-					_ = h.notificationService.CreateLikeNotification(
-						context.Background(),
-						anchorID,
-						anchor.Title,
-						currentUser.ID,
-						anchor.UserID,
-					)
-				}()
-			}
-		}
-
-		// Update engagement score asynchronously
-		go func() {
-			_ = h.anchorsRepo.UpdateEngagementScore(context.Background(), anchorID)
-		}()
-
 		hasLiked = true
 	} else {
-		// Unlike (idempotent)
-		err = h.repo.DeleteLike(c.Request.Context(), anchorID, currentUser.ID)
-		if err != nil {
+		// Unlike removes whatever reaction the user has (idempotent)
+		if err := h.unreact(c.Request.Context(), anchor, currentUser.ID); err != nil {
 			response.InternalServerError(c, "UNLIKE_FAILED", "Failed to unlike anchor")
 			return
 		}
-
-		// Decrement anchor's like count
-		_ = h.anchorsRepo.IncrementLikeCount(c.Request.Context(), anchorID, -1)
-
-		// Update engagement score asynchronously
-		go func() {
-			_ = h.anchorsRepo.UpdateEngagementScore(context.Background(), anchorID)
-		}()
-
 		hasLiked = false
 	}
 
@@ -214,24 +176,27 @@ func (h *Handler) GetLikeStatus(c *gin.Context) {
 	}
 
 	// Check access
-	if !anchor.IsOwnedBy(currentUser.ID) {
+	if !anchor.IsMember(currentUser.ID) {
 		if anchor.Visibility != anchors.VisibilityPublic && anchor.Visibility != anchors.VisibilityUnlisted {
 			response.Forbidden(c, "ACCESS_DENIED", "Cannot access private anchor")
 			return
 		}
 	}
 
-	// Check if user has liked
-	hasLiked, err := h.repo.ExistsLike(c.Request.Context(), anchorID, currentUser.ID)
+	// Check if user has reacted
+	reaction, err := h.repo.Reactions().GetReaction(c.Request.Context(), anchorID, currentUser.ID)
 	if err != nil {
 		response.InternalServerError(c, "STATUS_CHECK_FAILED", "Failed to check like status")
 		return
 	}
 
 	resp := LikeStatusResponse{
-		HasLiked:  hasLiked,
+		HasLiked:  reaction != "",
 		LikeCount: anchor.LikeCount,
 	}
+	if reaction != "" {
+		resp.Reaction = &reaction
+	}
 
 	response.Success(c, resp)
 }
@@ -279,7 +244,7 @@ func (h *Handler) ListLikers(c *gin.Context) {
 	}
 
 	// Check access
-	if currentUserID == nil || !anchor.IsOwnedBy(*currentUserID) {
+	if currentUserID == nil || !anchor.IsMember(*currentUserID) {
 		if anchor.Visibility != anchors.VisibilityPublic && anchor.Visibility != anchors.VisibilityUnlisted {
 			response.Forbidden(c, "ACCESS_DENIED", "Cannot access private anchor")
 			return
@@ -298,8 +263,14 @@ func (h *Handler) ListLikers(c *gin.Context) {
 		return
 	}
 
-	// Get likes
-	likes, total, err := h.repo.GetLikers(c.Request.Context(), anchorID, query.Page, query.Limit)
+	// Get likes, leaving out hidden likers
+	hiddenUserIDs, err := h.authRepo.GetHiddenUserIDs(c.Request.Context(), currentUserID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch likers")
+		return
+	}
+
+	likes, total, err := h.repo.GetLikers(c.Request.Context(), anchorID, query.Page, query.Limit, hiddenUserIDs)
 	if err != nil {
 		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch likers")
 		return
@@ -349,6 +320,7 @@ func (h *Handler) ListLikers(c *gin.Context) {
 			DisplayName:    user.DisplayName,
 			ProfilePicture: &user.ProfilePictureURL,
 			IsFollowing:    isFollowing,
+			Reaction:       like.GetReaction(),
 			LikedAt:        like.CreatedAt,
 		})
 	}
@@ -392,8 +364,13 @@ func (h *Handler) GetLikeSummary(c *gin.Context, anchorID primitive.ObjectID, cu
 		hasLiked, _ = h.repo.ExistsLike(c.Request.Context(), anchorID, *currentUserID)
 	}
 
-	// Get recent likers (limit 20 for processing)
-	recentLikes, err := h.repo.GetRecentLikers(c.Request.Context(), anchorID, 20)
+	// Get recent likers (limit 20 for processing), leaving out hidden likers
+	hiddenUserIDs, err := h.authRepo.GetHiddenUserIDs(c.Request.Context(), currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	recentLikes, err := h.repo.GetRecentLikers(c.Request.Context(), anchorID, 20, hiddenUserIDs)
 	if err != nil {
 		return nil, err
 	}
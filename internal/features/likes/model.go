@@ -6,19 +6,37 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Like represents a like on an anchor
+// Like represents a reaction on an anchor; the binary like is the "like" reaction
 type Like struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	AnchorID  primitive.ObjectID `bson:"anchorId" json:"anchorId"`
 	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
+	Reaction  string             `bson:"reaction,omitempty" json:"reaction"` // empty on likes stored before reactions
 	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
 }
 
+// GetReaction returns the reaction this like stands for
+func (l *Like) GetReaction() string {
+	return reactionOf(l.Reaction)
+}
+
 // LikeActionRequest for POST /anchors/:id/like
 type LikeActionRequest struct {
 	Action string `json:"action" binding:"required,oneof=like unlike"`
 }
 
+// ReactionRequest for PUT /anchors/:id/reaction
+type ReactionRequest struct {
+	Reaction string `json:"reaction" binding:"required"`
+}
+
+// ReactionListQuery for GET /anchors/:id/reactions
+type ReactionListQuery struct {
+	Reaction string `form:"reaction"` // only users who reacted with this; all reactions if empty
+	Page     int    `form:"page,default=1" binding:"min=1"`
+	Limit    int    `form:"limit,default=20" binding:"min=1,max=50"`
+}
+
 // LikeListQuery for GET /anchors/:id/likes
 type LikeListQuery struct {
 	Page  int `form:"page,default=1" binding:"min=1"`
@@ -33,8 +51,9 @@ type LikeActionResponse struct {
 
 // LikeStatusResponse for GET /anchors/:id/like/status
 type LikeStatusResponse struct {
-	HasLiked  bool `json:"hasLiked"`
-	LikeCount int  `json:"likeCount"`
+	HasLiked  bool    `json:"hasLiked"` // true for any reaction
+	Reaction  *string `json:"reaction,omitempty"`
+	LikeCount int     `json:"likeCount"`
 }
 
 // LikerUserResponse for items in likers list
@@ -44,6 +63,7 @@ type LikerUserResponse struct {
 	DisplayName    string             `json:"displayName"`
 	ProfilePicture *string            `json:"profilePicture"`
 	IsFollowing    bool               `json:"isFollowing"`
+	Reaction       string             `json:"reaction"`
 	LikedAt        time.Time          `json:"likedAt"`
 }
 
@@ -74,3 +94,16 @@ type LikeSummaryResponse struct {
 	LikedByFollowing []LikeSummaryUser `json:"likedByFollowing"`
 	OtherLikersCount int               `json:"otherLikersCount"`
 }
+
+// PaginatedReactorsResponse for GET /anchors/:id/reactions
+type PaginatedReactorsResponse struct {
+	Summary    ReactionSummary     `json:"summary"`
+	Data       []LikerUserResponse `json:"data"`
+	Pagination struct {
+		Page       int   `json:"page"`
+		Limit      int   `json:"limit"`
+		Total      int64 `json:"total"`
+		TotalPages int   `json:"totalPages"`
+		HasMore    bool  `json:"hasMore"`
+	} `json:"pagination"`
+}
@@ -0,0 +1,325 @@
+package likes
+
+import (
+	"context"
+	"math"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/features/anchors"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListReactionTypes godoc
+// @Summary List reaction types
+// @Description Get the fixed reaction palette usable on anchors and comments
+// @Tags likes
+// @Produce json
+// @Success 200 {object} response.APIResponse{data=[]ReactionType}
+// @Router /reactions [get]
+func (h *Handler) ListReactionTypes(c *gin.Context) {
+	response.Success(c, Palette)
+}
+
+// SetReaction godoc
+// @Summary React to anchor
+// @Description Set the current user's reaction on an anchor, replacing any previous one
+// @Tags likes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Param request body ReactionRequest true "Reaction"
+// @Success 200 {object} response.APIResponse{data=ReactionSummary}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/reaction [put]
+func (h *Handler) SetReaction(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := user.(*auth.User)
+
+	var req ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	if err := ValidateReactionRequest(&req); err != nil {
+		response.BadRequest(c, "INVALID_REACTION", err.Error())
+		return
+	}
+
+	anchor, ok := h.getReactableAnchor(c, &currentUser.ID)
+	if !ok {
+		return
+	}
+
+	if _, err := h.react(c.Request.Context(), anchor, currentUser.ID, req.Reaction, true); err != nil {
+		response.InternalServerError(c, "REACTION_FAILED", "Failed to react to anchor")
+		return
+	}
+
+	h.respondWithSummary(c, anchor.ID, currentUser.ID)
+}
+
+// RemoveReaction godoc
+// @Summary Remove reaction from anchor
+// @Description Remove the current user's reaction from an anchor (idempotent)
+// @Tags likes
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Success 200 {object} response.APIResponse{data=ReactionSummary}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/reaction [delete]
+func (h *Handler) RemoveReaction(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := user.(*auth.User)
+
+	anchor, ok := h.getReactableAnchor(c, &currentUser.ID)
+	if !ok {
+		return
+	}
+
+	if err := h.unreact(c.Request.Context(), anchor, currentUser.ID); err != nil {
+		response.InternalServerError(c, "REACTION_FAILED", "Failed to remove reaction")
+		return
+	}
+
+	h.respondWithSummary(c, anchor.ID, currentUser.ID)
+}
+
+// ListReactions godoc
+// @Summary List reactions on anchor
+// @Description Get reaction counts by type and a paginated list of who reacted, optionally only with one reaction
+// @Tags likes
+// @Produce json
+// @Param id path string true "Anchor ID"
+// @Param reaction query string false "Only users who reacted with this (like, love, haha, wow, sad, angry)"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Items per page (default 20, max 50)"
+// @Success 200 {object} response.APIResponse{data=PaginatedReactorsResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /anchors/{id}/reactions [get]
+func (h *Handler) ListReactions(c *gin.Context) {
+	var currentUserID *primitive.ObjectID
+	if user, exists := c.Get("user"); exists {
+		currentUser := user.(*auth.User)
+		currentUserID = &currentUser.ID
+	}
+
+	anchor, ok := h.getReactableAnchor(c, currentUserID)
+	if !ok {
+		return
+	}
+
+	var query ReactionListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	if err := ValidateReactionListQuery(&query); err != nil {
+		response.BadRequest(c, "INVALID_QUERY", err.Error())
+		return
+	}
+
+	summary, err := h.repo.Reactions().GetSummary(c.Request.Context(), anchor.ID, currentUserID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch reactions")
+		return
+	}
+
+	// Leave out hidden reactors
+	hiddenUserIDs, err := h.authRepo.GetHiddenUserIDs(c.Request.Context(), currentUserID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch reactions")
+		return
+	}
+
+	entries, total, err := h.repo.Reactions().ListReactors(c.Request.Context(), anchor.ID, query.Reaction, query.Page, query.Limit, hiddenUserIDs)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch reactions")
+		return
+	}
+
+	reactors, err := BuildReactorList(c.Request.Context(), entries, currentUserID, h.authRepo, h.followsRepo.GetFollowingIDs)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_USERS_FAILED", "Failed to fetch user details")
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(query.Limit)))
+
+	resp := PaginatedReactorsResponse{
+		Summary: summary,
+		Data:    reactors,
+	}
+	resp.Pagination.Page = query.Page
+	resp.Pagination.Limit = query.Limit
+	resp.Pagination.Total = total
+	resp.Pagination.TotalPages = totalPages
+	resp.Pagination.HasMore = query.Page < totalPages
+
+	response.Success(c, resp)
+}
+
+// BuildReactorList resolves reaction entries to users, dropping accounts that
+// no longer exist. getFollowingIDs is only called when viewerID is set.
+func BuildReactorList(
+	ctx context.Context,
+	entries []ReactionEntry,
+	viewerID *primitive.ObjectID,
+	authRepo *auth.Repository,
+	getFollowingIDs func(context.Context, primitive.ObjectID, []primitive.ObjectID) (map[primitive.ObjectID]bool, error),
+) ([]LikerUserResponse, error) {
+	reactors := []LikerUserResponse{}
+	if len(entries) == 0 {
+		return reactors, nil
+	}
+
+	userIDs := make([]primitive.ObjectID, len(entries))
+	for i, entry := range entries {
+		userIDs[i] = entry.UserID
+	}
+
+	users, err := authRepo.GetUsersByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	userMap := make(map[primitive.ObjectID]*auth.User)
+	for i := range users {
+		userMap[users[i].ID] = &users[i]
+	}
+
+	var followingMap map[primitive.ObjectID]bool
+	if viewerID != nil {
+		followingMap, _ = getFollowingIDs(ctx, *viewerID, userIDs)
+	}
+
+	for _, entry := range entries {
+		user, exists := userMap[entry.UserID]
+		if !exists {
+			continue
+		}
+
+		reactors = append(reactors, LikerUserResponse{
+			ID:             user.ID,
+			Username:       user.Username,
+			DisplayName:    user.DisplayName,
+			ProfilePicture: &user.ProfilePictureURL,
+			IsFollowing:    followingMap[entry.UserID],
+			Reaction:       entry.Reaction,
+			LikedAt:        entry.CreatedAt,
+		})
+	}
+
+	return reactors, nil
+}
+
+// react sets or adds the user's reaction, which also counts it on the anchor.
+// replace=false keeps an existing reaction.
+func (h *Handler) react(ctx context.Context, anchor *anchors.Anchor, userID primitive.ObjectID, reaction string, replace bool) (string, error) {
+	var previous string
+	var err error
+	if replace {
+		previous, err = h.repo.Reactions().SetReaction(ctx, anchor.ID, userID, reaction)
+	} else {
+		previous, err = h.repo.Reactions().AddReaction(ctx, anchor.ID, userID, reaction)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Changing one reaction for another doesn't notify again
+	if previous != "" {
+		return previous, nil
+	}
+
+	if anchor.UserID != userID {
+		go func() {
+			_ = h.notificationService.CreateLikeNotification(
+				context.Background(),
+				anchor.ID,
+				anchor.Title,
+				userID,
+				anchor.UserID,
+			)
+		}()
+	}
+
+	go func() {
+		_ = h.anchorsRepo.UpdateEngagementScore(context.Background(), anchor.ID)
+	}()
+
+	return previous, nil
+}
+
+// unreact removes the user's reaction, if any, which also uncounts it on the anchor
+func (h *Handler) unreact(ctx context.Context, anchor *anchors.Anchor, userID primitive.ObjectID) error {
+	removed, err := h.repo.Reactions().RemoveReaction(ctx, anchor.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	if removed != "" {
+		go func() {
+			_ = h.anchorsRepo.UpdateEngagementScore(context.Background(), anchor.ID)
+		}()
+	}
+
+	return nil
+}
+
+// getReactableAnchor loads the anchor in the path if the user may see it,
+// responding with the error otherwise
+func (h *Handler) getReactableAnchor(c *gin.Context, currentUserID *primitive.ObjectID) (*anchors.Anchor, bool) {
+	anchorID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "INVALID_ID", "Invalid anchor ID format")
+		return nil, false
+	}
+
+	anchor, err := h.anchorsRepo.GetAnchorByID(c.Request.Context(), anchorID)
+	if err != nil || anchor.DeletedAt != nil {
+		response.NotFound(c, "ANCHOR_NOT_FOUND", "Anchor not found")
+		return nil, false
+	}
+
+	if currentUserID == nil || !anchor.IsMember(*currentUserID) {
+		if anchor.Visibility != anchors.VisibilityPublic && anchor.Visibility != anchors.VisibilityUnlisted {
+			response.Forbidden(c, "ACCESS_DENIED", "Cannot access private anchor")
+			return nil, false
+		}
+	}
+
+	return anchor, true
+}
+
+// respondWithSummary responds with the anchor's reaction summary for the user
+func (h *Handler) respondWithSummary(c *gin.Context, anchorID, userID primitive.ObjectID) {
+	summary, err := h.repo.Reactions().GetSummary(c.Request.Context(), anchorID, &userID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch reactions")
+		return
+	}
+
+	response.Success(c, summary)
+}
@@ -0,0 +1,483 @@
+package likes
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Reaction types. Likes stored before reactions existed have no reaction
+// field and count as ReactionLike.
+const (
+	ReactionLike  = "like"
+	ReactionLove  = "love"
+	ReactionHaha  = "haha"
+	ReactionWow   = "wow"
+	ReactionSad   = "sad"
+	ReactionAngry = "angry"
+)
+
+// ReactionType is one entry of the reaction palette
+type ReactionType struct {
+	Type  string `json:"type"`
+	Emoji string `json:"emoji"`
+}
+
+// Palette is the fixed set of reactions, in display order
+var Palette = []ReactionType{
+	{Type: ReactionLike, Emoji: "👍"},
+	{Type: ReactionLove, Emoji: "❤️"},
+	{Type: ReactionHaha, Emoji: "😂"},
+	{Type: ReactionWow, Emoji: "😮"},
+	{Type: ReactionSad, Emoji: "😢"},
+	{Type: ReactionAngry, Emoji: "😠"},
+}
+
+// IsValidReaction reports whether reaction is in the palette
+func IsValidReaction(reaction string) bool {
+	for _, r := range Palette {
+		if r.Type == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// reactionOf returns the reaction a stored document stands for
+func reactionOf(stored string) string {
+	if stored == "" {
+		return ReactionLike
+	}
+	return stored
+}
+
+// ReactionSummary is the reaction counts on a target and the viewer's own reaction.
+// The counts include reactions from hidden accounts; see ReactionStore.
+type ReactionSummary struct {
+	TotalCount int            `json:"totalCount"`
+	Counts     map[string]int `json:"counts"`               // only reactions with a non-zero count
+	MyReaction *string        `json:"myReaction,omitempty"` // nil when the viewer hasn't reacted
+}
+
+// NewReactionSummary builds a summary from counts by reaction type
+func NewReactionSummary(counts map[string]int, myReaction string) ReactionSummary {
+	summary := ReactionSummary{Counts: make(map[string]int)}
+	for reaction, count := range counts {
+		if count > 0 {
+			summary.Counts[reaction] = count
+			summary.TotalCount += count
+		}
+	}
+	if myReaction != "" {
+		summary.MyReaction = &myReaction
+	}
+	return summary
+}
+
+// reactionDoc is the part of a like or comment like the store reads back
+type reactionDoc struct {
+	UserID    primitive.ObjectID `bson:"userId"`
+	Reaction  string             `bson:"reaction"`
+	CreatedAt time.Time          `bson:"createdAt"`
+}
+
+// ReactionStore keeps at most one reaction per user per target in a collection
+// such as likes (targetField "anchorId") or commentLikes ("commentId"). It also
+// keeps the counts on the target documents in step: likeCount counts every
+// reaction and reactionCounts counts them by type.
+//
+// Reactions from shadow-banned and deleted accounts stay counted, although
+// ListReactors leaves out who made them. The counts are the same for every
+// viewer, and a shadow-banned user must see their reactions counted as usual
+// or the ban shows. A deleted account's reactions are removed, and the counts
+// recounted, when the account is purged.
+type ReactionStore struct {
+	collection  *mongo.Collection
+	targetField string
+	targets     *mongo.Collection
+}
+
+// NewReactionStore creates a store over collection, counting reactions on the
+// documents in targets, and ensures its reaction index. The collection must
+// already have a unique (targetField, userId) index.
+func NewReactionStore(collection *mongo.Collection, targetField string, targets *mongo.Collection) *ReactionStore {
+	_, _ = collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		// "Who reacted with X", most recent first
+		Keys: bson.D{
+			{Key: targetField, Value: 1},
+			{Key: "reaction", Value: 1},
+			{Key: "createdAt", Value: -1},
+		},
+	})
+
+	return &ReactionStore{
+		collection:  collection,
+		targetField: targetField,
+		targets:     targets,
+	}
+}
+
+// SetReaction sets the user's reaction on the target, replacing any other one.
+// It returns the previous reaction, or "" if the user hadn't reacted.
+func (s *ReactionStore) SetReaction(ctx context.Context, targetID, userID primitive.ObjectID, reaction string) (string, error) {
+	previous, err := s.upsert(ctx, targetID, userID, bson.M{
+		"$set":         bson.M{"reaction": reaction},
+		"$setOnInsert": bson.M{"createdAt": time.Now()},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if previous != reaction {
+		s.updateCounts(ctx, targetID, reaction, previous)
+	}
+	return previous, nil
+}
+
+// AddReaction reacts with reaction only if the user hasn't reacted yet, so a
+// plain like doesn't overwrite another reaction. It returns the previous
+// reaction, or "" if this one was added.
+func (s *ReactionStore) AddReaction(ctx context.Context, targetID, userID primitive.ObjectID, reaction string) (string, error) {
+	previous, err := s.upsert(ctx, targetID, userID, bson.M{
+		"$setOnInsert": bson.M{"reaction": reaction, "createdAt": time.Now()},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if previous == "" {
+		s.updateCounts(ctx, targetID, reaction, "")
+	}
+	return previous, nil
+}
+
+func (s *ReactionStore) upsert(ctx context.Context, targetID, userID primitive.ObjectID, update bson.M) (string, error) {
+	filter := bson.M{s.targetField: targetID, "userId": userID}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var previous reactionDoc
+	err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent upsert inserted first; now this one updates it
+		err = s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return reactionOf(previous.Reaction), nil
+}
+
+// RemoveReaction removes the user's reaction from the target. It returns the
+// removed reaction, or "" if there was none.
+func (s *ReactionStore) RemoveReaction(ctx context.Context, targetID, userID primitive.ObjectID) (string, error) {
+	var removed reactionDoc
+	err := s.collection.FindOneAndDelete(ctx, bson.M{s.targetField: targetID, "userId": userID}).Decode(&removed)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	s.updateCounts(ctx, targetID, "", reactionOf(removed.Reaction))
+	return reactionOf(removed.Reaction), nil
+}
+
+// updateCounts moves one reaction on the target from removed to added, either
+// of which may be "". The reaction itself is already stored, so a failure is
+// logged rather than returned.
+func (s *ReactionStore) updateCounts(ctx context.Context, targetID primitive.ObjectID, added, removed string) {
+	inc := bson.M{}
+	if added != "" {
+		inc["reactionCounts."+added] = 1
+	}
+	if removed != "" {
+		inc["reactionCounts."+removed] = -1
+	}
+	if added == "" {
+		inc["likeCount"] = -1
+	} else if removed == "" {
+		inc["likeCount"] = 1
+	}
+
+	_, err := s.targets.UpdateOne(ctx, bson.M{"_id": targetID}, bson.M{
+		"$inc": inc,
+		"$set": bson.M{"updatedAt": time.Now()},
+	})
+	if err != nil {
+		log.Printf("Failed to update reaction counts on %s: %v", targetID.Hex(), err)
+		return
+	}
+
+	// Ensure counts don't go negative
+	if removed != "" {
+		_, _ = s.targets.UpdateOne(ctx, bson.M{"_id": targetID}, bson.M{
+			"$max": bson.M{"likeCount": 0, "reactionCounts." + removed: 0},
+		})
+	}
+}
+
+// GetReaction returns the user's reaction on the target, or "" if none
+func (s *ReactionStore) GetReaction(ctx context.Context, targetID, userID primitive.ObjectID) (string, error) {
+	var doc reactionDoc
+	err := s.collection.FindOne(ctx, bson.M{s.targetField: targetID, "userId": userID}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return reactionOf(doc.Reaction), nil
+}
+
+// GetUserReactions batch gets the user's reactions on the targets
+func (s *ReactionStore) GetUserReactions(ctx context.Context, userID primitive.ObjectID, targetIDs []primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	result := make(map[primitive.ObjectID]string)
+	if len(targetIDs) == 0 {
+		return result, nil
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{
+		"userId":      userID,
+		s.targetField: bson.M{"$in": targetIDs},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []bson.M
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	for _, doc := range docs {
+		targetID, ok := doc[s.targetField].(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		reaction, _ := doc["reaction"].(string)
+		result[targetID] = reactionOf(reaction)
+	}
+
+	return result, nil
+}
+
+// CountReactions batch gets the reaction counts kept on the targets, by type
+func (s *ReactionStore) CountReactions(ctx context.Context, targetIDs []primitive.ObjectID) (map[primitive.ObjectID]map[string]int, error) {
+	result := make(map[primitive.ObjectID]map[string]int)
+	if len(targetIDs) == 0 {
+		return result, nil
+	}
+
+	cursor, err := s.targets.Find(ctx,
+		bson.M{"_id": bson.M{"$in": targetIDs}},
+		options.Find().SetProjection(bson.M{"reactionCounts": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		ID             primitive.ObjectID `bson:"_id"`
+		ReactionCounts map[string]int     `bson:"reactionCounts"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	for _, doc := range docs {
+		result[doc.ID] = doc.ReactionCounts
+	}
+
+	return result, nil
+}
+
+// GetSummary returns the reaction summary of one target for a viewer, who may be nil
+func (s *ReactionStore) GetSummary(ctx context.Context, targetID primitive.ObjectID, viewerID *primitive.ObjectID) (ReactionSummary, error) {
+	counts, err := s.CountReactions(ctx, []primitive.ObjectID{targetID})
+	if err != nil {
+		return ReactionSummary{}, err
+	}
+
+	myReaction := ""
+	if viewerID != nil {
+		if myReaction, err = s.GetReaction(ctx, targetID, *viewerID); err != nil {
+			return ReactionSummary{}, err
+		}
+	}
+
+	return NewReactionSummary(counts[targetID], myReaction), nil
+}
+
+// ReactionEntry is one user's reaction in a reactor listing
+type ReactionEntry struct {
+	UserID    primitive.ObjectID
+	Reaction  string
+	CreatedAt time.Time
+}
+
+// ListReactors lists who reacted to the target, most recent first, optionally
+// only with one reaction type, skipping excluded users
+func (s *ReactionStore) ListReactors(ctx context.Context, targetID primitive.ObjectID, reaction string, page, limit int, excludeUserIDs []primitive.ObjectID) ([]ReactionEntry, int64, error) {
+	filter := bson.M{s.targetField: targetID}
+	if reaction == ReactionLike {
+		// Includes likes not yet migrated
+		filter["reaction"] = bson.M{"$in": bson.A{ReactionLike, nil}}
+	} else if reaction != "" {
+		filter["reaction"] = reaction
+	}
+	if len(excludeUserIDs) > 0 {
+		filter["userId"] = bson.M{"$nin": excludeUserIDs}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []reactionDoc
+	if err = cursor.All(ctx, &docs); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entries := make([]ReactionEntry, len(docs))
+	for i, doc := range docs {
+		entries[i] = ReactionEntry{
+			UserID:    doc.UserID,
+			Reaction:  reactionOf(doc.Reaction),
+			CreatedAt: doc.CreatedAt,
+		}
+	}
+
+	return entries, total, nil
+}
+
+// MigrateLegacyLikes marks likes stored before reactions existed as the "like"
+// reaction. Reads already treat them as likes, so this can run in the background.
+func (s *ReactionStore) MigrateLegacyLikes(ctx context.Context) (int64, error) {
+	result, err := s.collection.UpdateMany(ctx,
+		bson.M{"reaction": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"reaction": ReactionLike}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// BackfillCounts sets reactionCounts on targets that have likes counted from
+// before the counts were kept. It aggregates only those targets, so once they
+// are done it is cheap to run on every start.
+func (s *ReactionStore) BackfillCounts(ctx context.Context) (int64, error) {
+	cursor, err := s.targets.Find(ctx,
+		bson.M{"reactionCounts": bson.M{"$exists": false}, "likeCount": bson.M{"$gt": 0}},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+	var docs []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return 0, err
+	}
+
+	var updated int64
+	for start := 0; start < len(docs); start += backfillBatchSize {
+		end := start + backfillBatchSize
+		if end > len(docs) {
+			end = len(docs)
+		}
+		ids := make([]primitive.ObjectID, 0, end-start)
+		for _, doc := range docs[start:end] {
+			ids = append(ids, doc.ID)
+		}
+
+		counts, err := s.aggregateCounts(ctx, ids)
+		if err != nil {
+			return updated, err
+		}
+		for _, id := range ids {
+			result, err := s.targets.UpdateOne(ctx,
+				bson.M{"_id": id, "reactionCounts": bson.M{"$exists": false}},
+				bson.M{"$set": bson.M{"reactionCounts": counts[id]}},
+			)
+			if err != nil {
+				return updated, err
+			}
+			updated += result.ModifiedCount
+		}
+	}
+
+	return updated, nil
+}
+
+// backfillBatchSize is how many targets BackfillCounts aggregates at once
+const backfillBatchSize = 500
+
+// aggregateCounts counts the stored reactions on the targets by type. Targets
+// without reactions get an empty map.
+func (s *ReactionStore) aggregateCounts(ctx context.Context, targetIDs []primitive.ObjectID) (map[primitive.ObjectID]map[string]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{s.targetField: bson.M{"$in": targetIDs}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"target":   "$" + s.targetField,
+				"reaction": bson.M{"$ifNull": bson.A{"$reaction", ReactionLike}},
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		ID struct {
+			Target   primitive.ObjectID `bson:"target"`
+			Reaction string             `bson:"reaction"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	result := make(map[primitive.ObjectID]map[string]int, len(targetIDs))
+	for _, id := range targetIDs {
+		result[id] = make(map[string]int)
+	}
+	for _, g := range groups {
+		result[g.ID.Target][g.ID.Reaction] += g.Count
+	}
+
+	return result, nil
+}
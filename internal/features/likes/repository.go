@@ -2,7 +2,6 @@ package likes
 
 import (
 	"context"
-	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -13,6 +12,7 @@ import (
 // Repository handles database interactions for the likes feature
 type Repository struct {
 	collection *mongo.Collection
+	reactions  *ReactionStore
 }
 
 // NewRepository creates repository and ensures indexes
@@ -47,48 +47,13 @@ func NewRepository(db *mongo.Database) *Repository {
 
 	return &Repository{
 		collection: collection,
+		reactions:  NewReactionStore(collection, "anchorId", db.Collection("anchors")),
 	}
 }
 
-// CreateLike creates a new like relationship
-func (r *Repository) CreateLike(ctx context.Context, anchorID, userID primitive.ObjectID) error {
-	like := &Like{
-		ID:        primitive.NewObjectID(),
-		AnchorID:  anchorID,
-		UserID:    userID,
-		CreatedAt: time.Now(),
-	}
-
-	_, err := r.collection.InsertOne(ctx, like)
-	if err != nil {
-		// Check if it's a duplicate key error (already liked)
-		if mongo.IsDuplicateKeyError(err) {
-			return nil // Idempotent - already liked
-		}
-		return err
-	}
-
-	return nil
-}
-
-// DeleteLike removes a like relationship
-func (r *Repository) DeleteLike(ctx context.Context, anchorID, userID primitive.ObjectID) error {
-	filter := bson.M{
-		"anchorId": anchorID,
-		"userId":   userID,
-	}
-
-	result, err := r.collection.DeleteOne(ctx, filter)
-	if err != nil {
-		return err
-	}
-
-	// Idempotent - if not found, still return success
-	if result.DeletedCount == 0 {
-		return nil
-	}
-
-	return nil
+// Reactions returns the reaction store over anchor likes
+func (r *Repository) Reactions() *ReactionStore {
+	return r.reactions
 }
 
 // ExistsLike checks if a like relationship exists
@@ -106,9 +71,12 @@ func (r *Repository) ExistsLike(ctx context.Context, anchorID, userID primitive.
 	return count > 0, nil
 }
 
-// GetLikers retrieves users who liked an anchor with pagination
-func (r *Repository) GetLikers(ctx context.Context, anchorID primitive.ObjectID, page, limit int) ([]Like, int64, error) {
+// GetLikers retrieves users who liked an anchor with pagination, skipping excluded users
+func (r *Repository) GetLikers(ctx context.Context, anchorID primitive.ObjectID, page, limit int, excludeUserIDs []primitive.ObjectID) ([]Like, int64, error) {
 	filter := bson.M{"anchorId": anchorID}
+	if len(excludeUserIDs) > 0 {
+		filter["userId"] = bson.M{"$nin": excludeUserIDs}
+	}
 
 	opts := options.Find().
 		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
@@ -134,9 +102,12 @@ func (r *Repository) GetLikers(ctx context.Context, anchorID primitive.ObjectID,
 	return likes, total, nil
 }
 
-// GetRecentLikers retrieves recent likers (for like summary)
-func (r *Repository) GetRecentLikers(ctx context.Context, anchorID primitive.ObjectID, limit int) ([]Like, error) {
+// GetRecentLikers retrieves recent likers (for like summary), skipping excluded users
+func (r *Repository) GetRecentLikers(ctx context.Context, anchorID primitive.ObjectID, limit int, excludeUserIDs []primitive.ObjectID) ([]Like, error) {
 	filter := bson.M{"anchorId": anchorID}
+	if len(excludeUserIDs) > 0 {
+		filter["userId"] = bson.M{"$nin": excludeUserIDs}
+	}
 
 	opts := options.Find().
 		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
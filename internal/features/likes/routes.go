@@ -1,6 +1,9 @@
 package likes
 
 import (
+	"context"
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
 	"github.com/xyz-asif/gotodo/internal/features/anchors"
@@ -27,15 +30,38 @@ func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Con
 	authMiddleware := middleware.NewAuthMiddleware(authRepo, cfg)
 	optionalAuth := middleware.OptionalAuthMiddleware(authRepo, cfg)
 
+	// Mark likes stored before reactions existed as the "like" reaction,
+	// then count them by type on anchors that predate the per-type counts
+	go func() {
+		migrated, err := repo.Reactions().MigrateLegacyLikes(context.Background())
+		if err != nil {
+			log.Printf("likes: failed to migrate legacy likes: %v", err)
+		} else if migrated > 0 {
+			log.Printf("likes: migrated %d legacy likes to reactions", migrated)
+		}
+
+		backfilled, err := repo.Reactions().BackfillCounts(context.Background())
+		if err != nil {
+			log.Printf("likes: failed to backfill reaction counts: %v", err)
+		} else if backfilled > 0 {
+			log.Printf("likes: backfilled reaction counts on %d anchors", backfilled)
+		}
+	}()
+
+	router.GET("/reactions", handler.ListReactionTypes)
+
 	// Like routes under /anchors
 	anchorsGroup := router.Group("/anchors")
 	{
 		// Protected routes
 		anchorsGroup.POST("/:id/like", authMiddleware, handler.LikeAction)
 		anchorsGroup.GET("/:id/like/status", authMiddleware, handler.GetLikeStatus)
+		anchorsGroup.PUT("/:id/reaction", authMiddleware, handler.SetReaction)
+		anchorsGroup.DELETE("/:id/reaction", authMiddleware, handler.RemoveReaction)
 
 		// Public routes with optional auth
 		anchorsGroup.GET("/:id/likes", optionalAuth, handler.ListLikers)
 		anchorsGroup.GET("/:id/like/summary", optionalAuth, handler.GetLikeSummaryEndpoint)
+		anchorsGroup.GET("/:id/reactions", optionalAuth, handler.ListReactions)
 	}
 }
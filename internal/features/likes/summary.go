@@ -11,8 +11,9 @@ import (
 func GetLikeSummaryStandalone(
 	ctx context.Context,
 	anchorID primitive.ObjectID,
-	anchorLikeCount int,
+	anchorLikeCount int, // includes likes from excluded users; see ReactionStore
 	currentUserID *primitive.ObjectID,
+	excludeUserIDs []primitive.ObjectID, // shadow-banned and deleted likers hidden from this viewer
 	likesRepo *Repository,
 	authRepoGetter func([]primitive.ObjectID) ([]interface{}, error),
 	followsRepoGetter func(primitive.ObjectID, []primitive.ObjectID) (map[primitive.ObjectID]bool, error),
@@ -35,7 +36,7 @@ func GetLikeSummaryStandalone(
 	}
 
 	// Get recent likers (limit 20 for processing)
-	recentLikes, err := likesRepo.GetRecentLikers(ctx, anchorID, 20)
+	recentLikes, err := likesRepo.GetRecentLikers(ctx, anchorID, 20, excludeUserIDs)
 	if err != nil {
 		return nil, err
 	}
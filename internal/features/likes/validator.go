@@ -26,3 +26,30 @@ func ValidateLikeListQuery(query *LikeListQuery) error {
 
 	return nil
 }
+
+// ValidateReactionRequest validates the reaction request
+func ValidateReactionRequest(req *ReactionRequest) error {
+	if !IsValidReaction(req.Reaction) {
+		return errors.New("reaction must be one of: like, love, haha, wow, sad, angry")
+	}
+	return nil
+}
+
+// ValidateReactionListQuery validates the reaction list query parameters
+func ValidateReactionListQuery(query *ReactionListQuery) error {
+	if query.Reaction != "" && !IsValidReaction(query.Reaction) {
+		return errors.New("reaction must be one of: like, love, haha, wow, sad, angry")
+	}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+
+	if query.Limit < 1 {
+		query.Limit = 20
+	} else if query.Limit > 50 {
+		query.Limit = 50
+	}
+
+	return nil
+}
@@ -1,31 +1,50 @@
 package media
 
 import (
+	"errors"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
 	"github.com/gin-gonic/gin"
-	"github.com/xyz-asif/gotodo/internal/features/anchors" // Imported for Scraper
-	"github.com/xyz-asif/gotodo/internal/pkg/cloudinary"
+	"github.com/xyz-asif/gotodo/internal/features/anchors" // Imported for the link previewer
 	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
 )
 
 type Handler struct {
-	cloudinary *cloudinary.Service
+	storage storage.Storage
+	local   *storage.LocalStorage // set when files are served by DownloadFile
+	tracker storage.Tracker
+
+	previewer *anchors.Previewer
 }
 
-func NewHandler(cld *cloudinary.Service) *Handler {
-	return &Handler{
-		cloudinary: cld,
+func NewHandler(store storage.Storage, tracker storage.Tracker, previewer *anchors.Previewer) *Handler {
+	h := &Handler{storage: store, tracker: tracker, previewer: previewer}
+	if local, ok := store.(*storage.LocalStorage); ok {
+		h.local = local
 	}
+	return h
 }
 
 // @Summary Upload media
-// @Description Upload a file to Cloudinary (image, audio, or raw)
+// @Description Upload a file to media storage (image, audio, or raw)
 // @Tags media
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "File to upload"
-// @Success 200 {object} response.APIResponse{data=cloudinary.UploadResult}
+// @Success 200 {object} response.APIResponse{data=storage.UploadResult}
+// @Failure 422 {object} response.APIResponse
 // @Router /media/upload [post]
 func (h *Handler) UploadMedia(c *gin.Context) {
+	if h.storage == nil {
+		response.ServiceUnavailable(c, "File uploads are not available", "UPLOAD_UNAVAILABLE")
+		return
+	}
+
 	file, header, err := c.Request.FormFile("file")
 	if err != nil {
 		response.BadRequest(c, "File is required", "MISSING_FILE")
@@ -33,52 +52,48 @@ func (h *Handler) UploadMedia(c *gin.Context) {
 	}
 	defer file.Close()
 
-	// Detect content type
+	// The declared content type picks the kind; the contents must then match it
 	contentType := header.Header.Get("Content-Type")
-	var result *cloudinary.UploadResult
-
-	// Simple logic: if audio -> audio, if image -> image, else -> file
-	// Using header.Header.Get("Content-Type") might be empty, relying on extension validation in Service
-	// But Service Upload methods take `multipart.File`.
-	// I need to decide which method to call.
-
-	// Check extension logic or mime type
-	// For simplicity, checking Content-Type start
-	if len(contentType) >= 5 && contentType[:5] == "image" {
-		if err := cloudinary.ValidateImageFile(header); err != nil {
-			response.BadRequest(c, err.Error(), "INVALID_FILE")
-			return
-		}
-		result, err = h.cloudinary.UploadImage(c.Request.Context(), file, header.Filename)
-	} else if len(contentType) >= 5 && contentType[:5] == "audio" {
-		if err := cloudinary.ValidateAudioFile(header); err != nil {
-			response.BadRequest(c, err.Error(), "INVALID_FILE")
-			return
-		}
-		result, err = h.cloudinary.UploadAudio(c.Request.Context(), file, header.Filename)
-	} else {
-		// Default to generic file
-		if err := cloudinary.ValidateFile(header); err != nil {
-			response.BadRequest(c, err.Error(), "INVALID_FILE")
+	kind := storage.KindFile
+	if strings.HasPrefix(contentType, "image/") {
+		kind = storage.KindImage
+	} else if strings.HasPrefix(contentType, "audio/") {
+		kind = storage.KindAudio
+	}
+
+	content, info, err := storage.Prepare(kind, file, header.Filename, header.Size)
+	if err != nil {
+		var invalid *storage.ValidationError
+		if errors.As(err, &invalid) {
+			response.ValidationError(c, invalid.Message, invalid.Code)
 			return
 		}
-		result, err = h.cloudinary.UploadFile(c.Request.Context(), file, header.Filename)
+		response.InternalServerError(c, "Failed to read file", "FILE_ERROR")
+		return
 	}
 
+	result, err := h.storage.Upload(c.Request.Context(), kind, content, header.Filename)
 	if err != nil {
 		response.InternalServerError(c, "Failed to upload file", "UPLOAD_FAILED")
 		return
 	}
+	// Deleted by the media reconciler if nothing uses it within the grace period
+	h.tracker.Track(c.Request.Context(), kind, result.PublicID)
+	if result.Duration == 0 {
+		result.Duration = info.Duration
+	}
 
 	response.Success(c, result)
 }
 
 // @Summary Preview link
-// @Description Get metadata for a URL
+// @Description Get metadata for a URL. Previews are cached for 24 hours, and failed fetches for 15 minutes. Only public http(s) addresses on ports 80 and 443 are fetched.
 // @Tags media
 // @Produce json
 // @Param url query string true "URL to preview"
 // @Success 200 {object} response.APIResponse{data=anchors.URLData}
+// @Failure 400 {object} response.APIResponse
+// @Failure 502 {object} response.APIResponse
 // @Router /media/preview [get]
 func (h *Handler) GetLinkPreview(c *gin.Context) {
 	targetURL := c.Query("url")
@@ -87,12 +102,104 @@ func (h *Handler) GetLinkPreview(c *gin.Context) {
 		return
 	}
 
-	// Use anchors scraper
-	metadata, err := anchors.FetchURLMetadata(c.Request.Context(), targetURL)
+	metadata, err := h.previewer.Preview(c.Request.Context(), targetURL)
 	if err != nil {
-		response.InternalServerError(c, "Failed to fetch metadata", "SCRAPE_FAILED")
+		if errors.Is(err, anchors.ErrURLNotAllowed) {
+			response.BadRequest(c, "URL cannot be previewed", "URL_NOT_ALLOWED")
+			return
+		}
+		response.Error(c, http.StatusBadGateway, "Failed to fetch metadata", "SCRAPE_FAILED")
 		return
 	}
 
 	response.Success(c, metadata)
 }
+
+// @Summary Download a stored file
+// @Description Serve a file from local media storage. Only available with STORAGE_DRIVER=local; upload responses and item URLs carry the signature.
+// @Tags media
+// @Produce octet-stream
+// @Param publicId path string true "Public ID of the file"
+// @Param sig query string true "Signature from the file's URL"
+// @Success 200 {file} file
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /media/files/{publicId} [get]
+func (h *Handler) DownloadFile(c *gin.Context) {
+	publicID := strings.TrimPrefix(c.Param("publicId"), "/")
+	if !h.local.Verify(publicID, c.Query("sig")) {
+		response.Forbidden(c, "Invalid file signature", "INVALID_SIGNATURE")
+		return
+	}
+
+	f, err := h.local.Open(publicID)
+	if err != nil {
+		response.NotFound(c, "File not found", "FILE_NOT_FOUND")
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		response.NotFound(c, "File not found", "FILE_NOT_FOUND")
+		return
+	}
+
+	// Public IDs are never reused, so a signed URL always serves the same bytes
+	c.Header("Cache-Control", "private, max-age=31536000, immutable")
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, path.Base(publicID), info.ModTime(), f)
+}
+
+// @Summary Upload a file to local storage
+// @Description Receive the body of a direct upload. Only available with STORAGE_DRIVER=local; the URL, with its signature, comes from POST /media/upload-intents and each one can be used once. The contents are checked like any other upload, and images are stored without their metadata.
+// @Tags media
+// @Accept octet-stream
+// @Produce json
+// @Param publicId path string true "Public ID from the upload intent"
+// @Param kind query string true "Kind of media"
+// @Param expires query int true "Expiry of the signature"
+// @Param sig query string true "Signature from the upload URL"
+// @Success 201 {object} response.APIResponse{data=storage.UploadResult}
+// @Failure 403 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 413 {object} response.APIResponse
+// @Failure 422 {object} response.APIResponse
+// @Router /media/files/{publicId} [put]
+func (h *Handler) ReceiveFile(c *gin.Context) {
+	publicID := strings.TrimPrefix(c.Param("publicId"), "/")
+	kind := c.Query("kind")
+	if !h.local.VerifyUpload(publicID, kind, c.Query("expires"), c.Query("sig"), time.Now()) {
+		response.Forbidden(c, "Invalid or expired upload signature", "INVALID_SIGNATURE")
+		return
+	}
+
+	maxSize := storage.MaxSize(storage.Kind(kind))
+	if c.Request.ContentLength > maxSize {
+		response.Error(c, http.StatusRequestEntityTooLarge, "File is too large", "FILE_TOO_LARGE")
+		return
+	}
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+
+	result, err := h.local.Receive(storage.Kind(kind), publicID, body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(c, http.StatusRequestEntityTooLarge, "File is too large", "FILE_TOO_LARGE")
+			return
+		}
+		if errors.Is(err, os.ErrExist) {
+			response.Conflict(c, "File has already been uploaded", "ALREADY_UPLOADED")
+			return
+		}
+		var invalid *storage.ValidationError
+		if errors.As(err, &invalid) {
+			response.ValidationError(c, invalid.Message, invalid.Code)
+			return
+		}
+		response.InternalServerError(c, "Failed to upload file", "UPLOAD_FAILED")
+		return
+	}
+
+	response.Created(c, result)
+}
@@ -5,27 +5,29 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
-	"github.com/xyz-asif/gotodo/internal/pkg/cloudinary"
+	"github.com/xyz-asif/gotodo/internal/features/anchors"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config) {
-	// Initialize Cloudinary service (reusing config)
-	// Note: Ideally pass the existing service from main, but creating new instance is acceptable if config is same
-	// However, `anchors` follows passed Cld service pattern.
-	// But `RegisterRoutes` signature doesn't take services.
-	// I'll create a new instance here.
-
-	cld, err := cloudinary.NewService(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret, "media")
+func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config, tracker storage.Tracker, previewer *anchors.Previewer) {
+	// Initialize media storage (Cloudinary or local disk, per STORAGE_DRIVER)
+	store, err := storage.New(cfg, "media")
 	if err != nil {
-		log.Printf("Failed to initialize cloudinary service for media: %v", err)
+		log.Printf("Failed to initialize media storage: %v", err)
 	}
 
-	handler := NewHandler(cld)
+	handler := NewHandler(store, tracker, previewer)
 
 	media := router.Group("/media")
 	{
 		media.POST("/upload", handler.UploadMedia)
 		media.GET("/preview", handler.GetLinkPreview)
+
+		// Files in local storage are served and received by the API; the signature is the access check
+		if handler.local != nil {
+			media.GET("/files/*publicId", handler.DownloadFile)
+			media.PUT("/files/*publicId", handler.ReceiveFile)
+		}
 	}
 }
@@ -1,8 +1,16 @@
 package notifications
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"log"
 	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
@@ -20,14 +28,16 @@ type Handler struct {
 	repo            *Repository
 	authRepo        *auth.Repository
 	contentProvider ContentProvider
+	hub             *Hub
 	config          *config.Config
 }
 
-func NewHandler(repo *Repository, authRepo *auth.Repository, contentProvider ContentProvider, cfg *config.Config) *Handler {
+func NewHandler(repo *Repository, authRepo *auth.Repository, contentProvider ContentProvider, hub *Hub, cfg *config.Config) *Handler {
 	return &Handler{
 		repo:            repo,
 		authRepo:        authRepo,
 		contentProvider: contentProvider,
+		hub:             hub,
 		config:          cfg,
 	}
 }
@@ -63,12 +73,20 @@ func (h *Handler) ListNotifications(c *gin.Context) {
 		return
 	}
 
+	// Notifications from actors shadow-banned or deleted since are hidden too
+	hiddenUserIDs, err := h.authRepo.GetHiddenUserIDs(c.Request.Context(), &currentUser.ID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch notifications")
+		return
+	}
+
 	notifications, total, err := h.repo.GetUserNotifications(
 		c.Request.Context(),
 		currentUser.ID,
 		query.UnreadOnly,
 		query.Page,
 		query.Limit,
+		hiddenUserIDs,
 	)
 	if err != nil {
 		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch notifications")
@@ -109,7 +127,13 @@ func (h *Handler) GetUnreadCount(c *gin.Context) {
 	}
 	currentUser := usr.(*auth.User)
 
-	count, err := h.repo.CountUnread(c.Request.Context(), currentUser.ID)
+	hiddenUserIDs, err := h.authRepo.GetHiddenUserIDs(c.Request.Context(), &currentUser.ID)
+	if err != nil {
+		response.InternalServerError(c, "COUNT_FAILED", "Failed to count notifications")
+		return
+	}
+
+	count, err := h.repo.CountUnread(c.Request.Context(), currentUser.ID, hiddenUserIDs)
 	if err != nil {
 		response.InternalServerError(c, "COUNT_FAILED", "Failed to count notifications")
 		return
@@ -213,6 +237,12 @@ func (h *Handler) enrichNotifications(ctx context.Context, notifications []Notif
 			actorIDSet[n.ActorID] = true
 			actorIDs = append(actorIDs, n.ActorID)
 		}
+		for _, id := range n.ActorIDs {
+			if !actorIDSet[id] {
+				actorIDSet[id] = true
+				actorIDs = append(actorIDs, id)
+			}
+		}
 		if n.AnchorID != nil && !anchorIDSet[*n.AnchorID] {
 			anchorIDSet[*n.AnchorID] = true
 			anchorIDs = append(anchorIDs, *n.AnchorID)
@@ -247,27 +277,24 @@ func (h *Handler) enrichNotifications(ctx context.Context, notifications []Notif
 			Preview:      n.Preview,
 			IsRead:       n.IsRead,
 			CreatedAt:    n.CreatedAt,
+			UpdatedAt:    n.LastActivity(),
+			ActorCount:   n.ActorCount,
+			EventCount:   n.EventCount,
 		}
 
-		// Add actor
-		if actor, ok := actorMap[n.ActorID]; ok {
-			var profilePic *string
-			if actor.ProfilePictureURL != "" {
-				profilePic = &actor.ProfilePictureURL
-			}
-			resp.Actor = NotificationActor{
-				ID:             actor.ID,
-				Username:       actor.Username,
-				DisplayName:    actor.DisplayName,
-				ProfilePicture: profilePic,
-			}
-		} else {
-			resp.Actor = NotificationActor{
-				ID:          n.ActorID,
-				Username:    "deleted",
-				DisplayName: "Deleted User",
+		// Add actor (the most recent one for groups)
+		resp.Actor = toNotificationActor(n.ActorID, actorMap)
+
+		// Add recent actors for grouped notifications
+		if len(n.ActorIDs) > 0 {
+			resp.Actors = make([]NotificationActor, len(n.ActorIDs))
+			for j, id := range n.ActorIDs {
+				resp.Actors[j] = toNotificationActor(id, actorMap)
 			}
 		}
+		if resp.ActorCount == 0 {
+			resp.ActorCount = 1
+		}
 
 		// Add anchor
 		if n.AnchorID != nil {
@@ -284,3 +311,414 @@ func (h *Handler) enrichNotifications(ctx context.Context, notifications []Notif
 
 	return responses
 }
+
+const (
+	// streamHeartbeatInterval keeps proxies from closing idle SSE connections
+	streamHeartbeatInterval = 25 * time.Second
+	// streamReplayLimit caps how many missed notifications are replayed on resume
+	streamReplayLimit = 100
+)
+
+// StreamNotifications godoc
+// @Summary Stream notifications
+// @Description Server-Sent Events stream of new notifications and unread-count changes.
+// @Description Events: "notification" (NotificationResponse; grouped notifications are re-sent with the same
+// @Description notification ID when they grow, so clients should upsert by ID), "unread-count" (UnreadCountResponse)
+// @Description and "resync" (too many missed events; refetch GET /notifications).
+// @Description Reconnect with the Last-Event-ID header (or lastEventId query param) to replay missed notifications.
+// @Tags notifications
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param Last-Event-ID header string false "ID of the last event received (an opaque stream position)"
+// @Param lastEventId query string false "Same as Last-Event-ID, for clients that cannot set headers"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 401 {object} response.APIResponse
+// @Router /notifications/stream [get]
+func (h *Handler) StreamNotifications(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	ctx := c.Request.Context()
+
+	// Subscribe before replaying so nothing inserted in between is missed
+	events, unsubscribe := h.hub.Subscribe(currentUser.ID)
+	defer unsubscribe()
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable nginx buffering
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	w.Flush()
+
+	// Replay anything created or regrouped since the client's last event.
+	// Event IDs are the notification's last-activity time and ID, so grouped
+	// notifications that grew while the client was away are replayed too.
+	var lastSent streamPosition
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	if pos, ok := parseStreamEventID(lastEventID); ok {
+		lastSent = pos
+
+		hiddenUserIDs, _ := h.authRepo.GetHiddenUserIDs(ctx, &currentUser.ID)
+		missed, err := h.repo.GetNotificationsUpdatedSince(ctx, currentUser.ID, pos.at, pos.id, streamReplayLimit+1, hiddenUserIDs)
+		if err != nil {
+			log.Printf("Failed to replay notifications for user %s: %v", currentUser.ID.Hex(), err)
+		}
+
+		if len(missed) > streamReplayLimit {
+			if writeSSE(w, "", "resync", gin.H{"reason": "TOO_MANY_MISSED"}) != nil {
+				return
+			}
+		} else if len(missed) > 0 {
+			for i, resp := range h.enrichNotifications(ctx, missed) {
+				if writeSSE(w, streamEventID(&missed[i]), "notification", resp) != nil {
+					return
+				}
+			}
+			lastSent = positionOf(&missed[len(missed)-1])
+		}
+	}
+
+	if h.writeUnreadCount(ctx, w, currentUser.ID) != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	unreadDirty := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+
+		case event := <-events:
+			// Skip anything already delivered by the replay, quiet-hours
+			// notifications, which only show up in the list and unread count,
+			// and anything from shadow-banned or deleted actors, as the replay does
+			if n := event.Notification; n != nil && !n.Silent && lastSent.before(n) && !h.isHiddenActor(ctx, currentUser.ID, n.ActorID) {
+				resp := h.enrichNotifications(ctx, []Notification{*n})[0]
+				if writeSSE(w, streamEventID(n), "notification", resp) != nil {
+					return
+				}
+				lastSent = positionOf(n)
+			}
+			if event.UnreadChanged {
+				unreadDirty = true
+			}
+
+			// Coalesce bursts (e.g. mark-all-read) into a single count update
+			if unreadDirty && len(events) == 0 {
+				if h.writeUnreadCount(ctx, w, currentUser.ID) != nil {
+					return
+				}
+				unreadDirty = false
+			}
+		}
+	}
+}
+
+// toNotificationActor builds the actor summary, falling back to a placeholder for deleted users
+func toNotificationActor(actorID primitive.ObjectID, actorMap map[primitive.ObjectID]*auth.User) NotificationActor {
+	actor, ok := actorMap[actorID]
+	if !ok {
+		return NotificationActor{
+			ID:          actorID,
+			Username:    "deleted",
+			DisplayName: "Deleted User",
+		}
+	}
+
+	var profilePic *string
+	if actor.ProfilePictureURL != "" {
+		profilePic = &actor.ProfilePictureURL
+	}
+	return NotificationActor{
+		ID:             actor.ID,
+		Username:       actor.Username,
+		DisplayName:    actor.DisplayName,
+		ProfilePicture: profilePic,
+	}
+}
+
+// writeUnreadCount sends the recipient's current unread count as an SSE event
+func (h *Handler) writeUnreadCount(ctx context.Context, w gin.ResponseWriter, userID primitive.ObjectID) error {
+	hiddenUserIDs, _ := h.authRepo.GetHiddenUserIDs(ctx, &userID)
+	count, err := h.repo.CountUnread(ctx, userID, hiddenUserIDs)
+	if err != nil {
+		log.Printf("Failed to count unread notifications for user %s: %v", userID.Hex(), err)
+		return nil
+	}
+	return writeSSE(w, "", "unread-count", UnreadCountResponse{UnreadCount: count})
+}
+
+// streamEventID encodes the stream position after n as "<unix milliseconds>-<notification ID>"
+func streamEventID(n *Notification) string {
+	return strconv.FormatInt(n.LastActivity().UnixMilli(), 10) + "-" + n.ID.Hex()
+}
+
+// streamPosition is the last notification sent on a stream: its last-activity
+// time, to the millisecond as stored, and its ID to break ties
+type streamPosition struct {
+	at time.Time
+	id primitive.ObjectID
+}
+
+func positionOf(n *Notification) streamPosition {
+	return streamPosition{at: time.UnixMilli(n.LastActivity().UnixMilli()), id: n.ID}
+}
+
+// before reports whether n comes after the position, so it hasn't been sent yet
+func (p streamPosition) before(n *Notification) bool {
+	at := n.LastActivity().UnixMilli()
+	if at != p.at.UnixMilli() {
+		return at > p.at.UnixMilli()
+	}
+	return bytes.Compare(n.ID[:], p.id[:]) > 0
+}
+
+// parseStreamEventID parses an event ID written by streamEventID. IDs without
+// the notification ID, from before ties were broken on it, resume at the
+// start of their millisecond.
+func parseStreamEventID(eventID string) (streamPosition, bool) {
+	msPart, idPart, hasID := strings.Cut(eventID, "-")
+	ms, err := strconv.ParseInt(msPart, 10, 64)
+	if err != nil || ms <= 0 {
+		return streamPosition{}, false
+	}
+
+	pos := streamPosition{at: time.UnixMilli(ms)}
+	if hasID {
+		if pos.id, err = primitive.ObjectIDFromHex(idPart); err != nil {
+			return streamPosition{}, false
+		}
+	}
+	return pos, true
+}
+
+// isHiddenActor reports whether the actor is shadow-banned or deleted, and so
+// hidden from the viewer. The set is cached, so this is cheap per event.
+func (h *Handler) isHiddenActor(ctx context.Context, viewerID, actorID primitive.ObjectID) bool {
+	hiddenUserIDs, err := h.authRepo.GetHiddenUserIDs(ctx, &viewerID)
+	if err != nil {
+		log.Printf("Failed to check hidden actor for stream of user %s: %v", viewerID.Hex(), err)
+		return false
+	}
+	for _, id := range hiddenUserIDs {
+		if id == actorID {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSSE writes a single Server-Sent Event and flushes it to the client
+func writeSSE(w gin.ResponseWriter, id, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	w.Flush()
+	return nil
+}
+
+// GetNotificationSettings godoc
+// @Summary Get notification settings
+// @Description Get the current user's notification preferences and quiet hours
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse{data=Preferences}
+// @Failure 401 {object} response.APIResponse
+// @Router /users/me/notification-settings [get]
+func (h *Handler) GetNotificationSettings(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	prefs, err := h.repo.GetPreferences(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch notification settings")
+		return
+	}
+
+	response.Success(c, prefs)
+}
+
+// UpdateNotificationSettings godoc
+// @Summary Update notification settings
+// @Description Update per-type toggles, "only from people I follow" and quiet hours. Omitted fields are unchanged.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdatePreferencesRequest true "Settings to change"
+// @Success 200 {object} response.APIResponse{data=Preferences}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /users/me/notification-settings [patch]
+func (h *Handler) UpdateNotificationSettings(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", "Invalid request body")
+		return
+	}
+
+	if err := ValidateUpdatePreferencesRequest(&req); err != nil {
+		response.BadRequest(c, "VALIDATION_FAILED", err.Error())
+		return
+	}
+
+	prefs, err := h.repo.GetPreferences(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		response.InternalServerError(c, "FETCH_FAILED", "Failed to fetch notification settings")
+		return
+	}
+
+	applyBool(&prefs.Mention, req.Mention)
+	applyBool(&prefs.Comment, req.Comment)
+	applyBool(&prefs.Like, req.Like)
+	applyBool(&prefs.Follow, req.Follow)
+	applyBool(&prefs.Clone, req.Clone)
+	applyBool(&prefs.AnchorUpdate, req.AnchorUpdate)
+	applyBool(&prefs.OnlyFromFollowing, req.OnlyFromFollowing)
+	if qh := req.QuietHours; qh != nil {
+		applyBool(&prefs.QuietHours.Enabled, qh.Enabled)
+		if qh.Start != nil {
+			prefs.QuietHours.Start = *qh.Start
+		}
+		if qh.End != nil {
+			prefs.QuietHours.End = *qh.End
+		}
+		if qh.Timezone != nil {
+			prefs.QuietHours.Timezone = *qh.Timezone
+		}
+	}
+
+	if prefs.QuietHours.Enabled && prefs.QuietHours.Start == prefs.QuietHours.End {
+		response.BadRequest(c, "VALIDATION_FAILED", "quiet hours start and end must differ")
+		return
+	}
+
+	if err := h.repo.SavePreferences(c.Request.Context(), prefs); err != nil {
+		response.InternalServerError(c, "UPDATE_FAILED", "Failed to update notification settings")
+		return
+	}
+
+	response.Success(c, prefs)
+}
+
+func applyBool(dst *bool, value *bool) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// RegisterDevice godoc
+// @Summary Register a push device
+// @Description Register a device token for push notifications. Registering a token again refreshes it; a token registered by another account moves to the current user.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RegisterDeviceRequest true "Device token"
+// @Success 200 {object} response.APIResponse{data=Device}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Router /users/me/devices [post]
+func (h *Handler) RegisterDevice(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	var req RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	device, err := h.repo.RegisterDevice(c.Request.Context(), currentUser.ID, req.Token, req.Platform)
+	if err != nil {
+		response.InternalServerError(c, "UPDATE_FAILED", "Failed to register device")
+		return
+	}
+
+	response.Success(c, device)
+}
+
+// RemoveDevice godoc
+// @Summary Remove a push device
+// @Description Stop sending push notifications to a device token, e.g. on logout
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RemoveDeviceRequest true "Device token"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /users/me/devices [delete]
+func (h *Handler) RemoveDevice(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "UNAUTHORIZED", "Authentication required")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	var req RemoveDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	removed, err := h.repo.RemoveDevice(c.Request.Context(), currentUser.ID, req.Token)
+	if err != nil {
+		response.InternalServerError(c, "DELETE_FAILED", "Failed to remove device")
+		return
+	}
+	if !removed {
+		response.NotFound(c, "NOT_FOUND", "Device not found")
+		return
+	}
+
+	response.Success(c, nil, "Device removed")
+}
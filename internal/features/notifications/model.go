@@ -14,6 +14,17 @@ const (
 	TypeFollow       = "follow"
 	TypeClone        = "clone"
 	TypeAnchorUpdate = "anchor_update" // When a followed anchor gets new content
+	TypeWarning      = "moderation_warning"
+
+	TypeCollaboratorInvite = "collaborator_invite" // When invited to collaborate on an anchor
+	TypeCollaboratorItem   = "collaborator_item"   // When a collaborator adds to a shared anchor
+)
+
+// Grouping settings. Likes, follows and anchor updates that land within
+// GroupWindow of a group's last activity are merged into that group.
+const (
+	GroupWindow    = 24 * time.Hour
+	GroupMaxActors = 3 // most recent actors kept on a group for display
 )
 
 // Notification represents a user notification
@@ -28,6 +39,47 @@ type Notification struct {
 	Preview      string              `bson:"preview" json:"preview"`
 	IsRead       bool                `bson:"isRead" json:"isRead"`
 	CreatedAt    time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time           `bson:"updatedAt" json:"updatedAt"`
+	Silent       bool                `bson:"silent,omitempty" json:"-"` // created during quiet hours: no real-time or push alert
+
+	// Grouping: set on aggregated notifications only
+	GroupKey   string               `bson:"groupKey,omitempty" json:"-"`
+	GroupOpen  bool                 `bson:"groupOpen,omitempty" json:"-"`                 // unique per recipient and key; cleared once the group goes stale
+	ActorIDs   []primitive.ObjectID `bson:"actorIds,omitempty" json:"actorIds,omitempty"` // most recent first, capped at GroupMaxActors
+	ActorCount int                  `bson:"actorCount,omitempty" json:"actorCount,omitempty"`
+	EventCount int                  `bson:"eventCount,omitempty" json:"eventCount,omitempty"`
+
+	// Push delivery: pending notifications are claimed by the push worker
+	PushPending       bool       `bson:"pushPending,omitempty" json:"-"`
+	PushAttempts      int        `bson:"pushAttempts,omitempty" json:"-"`
+	PushNextAttemptAt *time.Time `bson:"pushNextAttemptAt,omitempty" json:"-"`
+	PushRetryTokens   []string   `bson:"pushRetryTokens,omitempty" json:"-"` // only these devices on the next attempt
+}
+
+// LastActivity returns when the notification last changed, for ordering and stream resume
+func (n *Notification) LastActivity() time.Time {
+	if n.UpdatedAt.IsZero() {
+		return n.CreatedAt
+	}
+	return n.UpdatedAt
+}
+
+// Device platforms
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWeb     = "web"
+)
+
+// Device is a push token registered by one of the user's devices.
+// A token belongs to at most one user; registering it again moves it.
+type Device struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID `bson:"userId" json:"-"`
+	Token      string             `bson:"token" json:"token"`
+	Platform   string             `bson:"platform" json:"platform"`
+	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
+	LastSeenAt time.Time          `bson:"lastSeenAt" json:"lastSeenAt"`
 }
 
 // Request DTOs
@@ -38,6 +90,34 @@ type NotificationListQuery struct {
 	UnreadOnly bool `form:"unreadOnly"`
 }
 
+// UpdatePreferencesRequest is a partial update; omitted fields keep their value
+type UpdatePreferencesRequest struct {
+	Mention           *bool                    `json:"mention"`
+	Comment           *bool                    `json:"comment"`
+	Like              *bool                    `json:"like"`
+	Follow            *bool                    `json:"follow"`
+	Clone             *bool                    `json:"clone"`
+	AnchorUpdate      *bool                    `json:"anchorUpdate"`
+	OnlyFromFollowing *bool                    `json:"onlyFromFollowing"`
+	QuietHours        *UpdateQuietHoursRequest `json:"quietHours"`
+}
+
+type UpdateQuietHoursRequest struct {
+	Enabled  *bool   `json:"enabled"`
+	Start    *string `json:"start" example:"22:00"`
+	End      *string `json:"end" example:"07:00"`
+	Timezone *string `json:"timezone" example:"Europe/Berlin"`
+}
+
+type RegisterDeviceRequest struct {
+	Token    string `json:"token" binding:"required,max=4096"`
+	Platform string `json:"platform" binding:"required,oneof=ios android web"`
+}
+
+type RemoveDeviceRequest struct {
+	Token string `json:"token" binding:"required,max=4096"`
+}
+
 // Response DTOs
 
 type NotificationActor struct {
@@ -61,7 +141,11 @@ type NotificationResponse struct {
 	Preview      string              `json:"preview"`
 	IsRead       bool                `json:"isRead"`
 	CreatedAt    time.Time           `json:"createdAt"`
-	Actor        NotificationActor   `json:"actor"`
+	UpdatedAt    time.Time           `json:"updatedAt"`
+	Actor        NotificationActor   `json:"actor"` // most recent actor
+	Actors       []NotificationActor `json:"actors,omitempty"`
+	ActorCount   int                 `json:"actorCount"`           // distinct actors; "Alice and {actorCount-1} others"
+	EventCount   int                 `json:"eventCount,omitempty"` // e.g. number of items added for anchor updates
 	Anchor       *NotificationAnchor `json:"anchor,omitempty"`
 }
 
@@ -0,0 +1,119 @@
+package notifications
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Preferences controls which notifications a user receives.
+// Users without a stored document get DefaultPreferences.
+type Preferences struct {
+	UserID            primitive.ObjectID `bson:"_id" json:"-"`
+	Mention           bool               `bson:"mention" json:"mention"`
+	Comment           bool               `bson:"comment" json:"comment"`
+	Like              bool               `bson:"like" json:"like"`
+	Follow            bool               `bson:"follow" json:"follow"`
+	Clone             bool               `bson:"clone" json:"clone"`
+	AnchorUpdate      bool               `bson:"anchorUpdate" json:"anchorUpdate"`
+	OnlyFromFollowing bool               `bson:"onlyFromFollowing" json:"onlyFromFollowing"`
+	QuietHours        QuietHours         `bson:"quietHours" json:"quietHours"`
+	UpdatedAt         time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// QuietHours is a daily window, in the user's timezone, during which notifications
+// are still recorded but delivered silently (no real-time or push alerts).
+// Start after End means the window spans midnight (e.g. 22:00-07:00).
+type QuietHours struct {
+	Enabled  bool   `bson:"enabled" json:"enabled"`
+	Start    string `bson:"start" json:"start"`       // "HH:MM"
+	End      string `bson:"end" json:"end"`           // "HH:MM"
+	Timezone string `bson:"timezone" json:"timezone"` // IANA name, e.g. "Europe/Berlin"
+}
+
+// DefaultPreferences enables every notification type with quiet hours off
+func DefaultPreferences(userID primitive.ObjectID) *Preferences {
+	return &Preferences{
+		UserID:       userID,
+		Mention:      true,
+		Comment:      true,
+		Like:         true,
+		Follow:       true,
+		Clone:        true,
+		AnchorUpdate: true,
+		QuietHours: QuietHours{
+			Start:    "22:00",
+			End:      "07:00",
+			Timezone: "UTC",
+		},
+	}
+}
+
+// AllowsType reports whether the user wants notifications of the given type.
+// Types without a toggle (e.g. moderation warnings) are always allowed.
+func (p *Preferences) AllowsType(notificationType string) bool {
+	switch notificationType {
+	case TypeMention:
+		return p.Mention
+	case TypeComment:
+		return p.Comment
+	case TypeLike:
+		return p.Like
+	case TypeFollow:
+		return p.Follow
+	case TypeClone:
+		return p.Clone
+	case TypeAnchorUpdate:
+		return p.AnchorUpdate
+	}
+	return true
+}
+
+// isPreferenceControlled reports whether a notification type is subject to the
+// per-type toggles and the "only from people I follow" filter
+func isPreferenceControlled(notificationType string) bool {
+	switch notificationType {
+	case TypeMention, TypeComment, TypeLike, TypeFollow, TypeClone, TypeAnchorUpdate:
+		return true
+	}
+	return false
+}
+
+// InQuietHours reports whether now falls inside the user's quiet hours
+func (q QuietHours) InQuietHours(now time.Time) bool {
+	if !q.Enabled {
+		return false
+	}
+
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(q.End)
+	if err != nil || start == end {
+		return false
+	}
+
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return minute >= start && minute < end
+	}
+	// Window spans midnight
+	return minute >= start || minute < end
+}
+
+// parseClock converts "HH:MM" to minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
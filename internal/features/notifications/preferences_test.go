@@ -0,0 +1,59 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // zones for the non-UTC cases, whatever the host has installed
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietHours_InQuietHours(t *testing.T) {
+	utc := func(hour, minute int) time.Time {
+		return time.Date(2024, time.March, 12, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		quiet QuietHours
+		now   time.Time
+		want  bool
+	}{
+		// Same-day window
+		{"same day: inside", QuietHours{Enabled: true, Start: "09:00", End: "17:00"}, utc(12, 0), true},
+		{"same day: before", QuietHours{Enabled: true, Start: "09:00", End: "17:00"}, utc(8, 59), false},
+		{"same day: after", QuietHours{Enabled: true, Start: "09:00", End: "17:00"}, utc(17, 1), false},
+		{"same day: start is inside", QuietHours{Enabled: true, Start: "09:00", End: "17:00"}, utc(9, 0), true},
+		{"same day: end is outside", QuietHours{Enabled: true, Start: "09:00", End: "17:00"}, utc(17, 0), false},
+		{"same day: last minute", QuietHours{Enabled: true, Start: "09:00", End: "17:00"}, utc(16, 59), true},
+
+		// Window spanning midnight
+		{"overnight: late evening", QuietHours{Enabled: true, Start: "22:00", End: "07:00"}, utc(23, 30), true},
+		{"overnight: midnight", QuietHours{Enabled: true, Start: "22:00", End: "07:00"}, utc(0, 0), true},
+		{"overnight: early morning", QuietHours{Enabled: true, Start: "22:00", End: "07:00"}, utc(6, 59), true},
+		{"overnight: daytime", QuietHours{Enabled: true, Start: "22:00", End: "07:00"}, utc(12, 0), false},
+		{"overnight: start is inside", QuietHours{Enabled: true, Start: "22:00", End: "07:00"}, utc(22, 0), true},
+		{"overnight: end is outside", QuietHours{Enabled: true, Start: "22:00", End: "07:00"}, utc(7, 0), false},
+		{"overnight: just before start", QuietHours{Enabled: true, Start: "22:00", End: "07:00"}, utc(21, 59), false},
+
+		// Non-UTC zone: Berlin is UTC+1 in March before the switch to summer time
+		{"zone: inside locally", QuietHours{Enabled: true, Start: "22:00", End: "07:00", Timezone: "Europe/Berlin"}, utc(21, 30), true},
+		{"zone: outside locally", QuietHours{Enabled: true, Start: "22:00", End: "07:00", Timezone: "Europe/Berlin"}, utc(6, 30), false},
+		{"zone: start edge", QuietHours{Enabled: true, Start: "22:00", End: "07:00", Timezone: "Europe/Berlin"}, utc(21, 0), true},
+		{"zone: end edge", QuietHours{Enabled: true, Start: "22:00", End: "07:00", Timezone: "Europe/Berlin"}, utc(6, 0), false},
+		{"zone: half-hour offset", QuietHours{Enabled: true, Start: "09:00", End: "17:00", Timezone: "Asia/Kolkata"}, utc(3, 30), true},
+		{"zone: unknown falls back to UTC", QuietHours{Enabled: true, Start: "09:00", End: "17:00", Timezone: "Nowhere/Special"}, utc(12, 0), true},
+
+		// Windows that never apply
+		{"disabled", QuietHours{Enabled: false, Start: "00:00", End: "23:59"}, utc(12, 0), false},
+		{"empty window", QuietHours{Enabled: true, Start: "09:00", End: "09:00"}, utc(9, 0), false},
+		{"malformed start", QuietHours{Enabled: true, Start: "9am", End: "17:00"}, utc(12, 0), false},
+		{"malformed end", QuietHours{Enabled: true, Start: "09:00", End: "25:00"}, utc(12, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.quiet.InQuietHours(tt.now))
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/pkg/push"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Push delivery settings
+const (
+	pushPollInterval = 2 * time.Second
+	pushClaimLease   = time.Minute // a crashed worker's claim expires after this
+	pushMaxAttempts  = 5
+	pushRetryBackoff = 30 * time.Second // doubled after each failed attempt
+)
+
+// PushQueue is the push queue and device registry, as kept by Repository
+type PushQueue interface {
+	ClaimPendingPush(ctx context.Context, now time.Time, lease time.Duration) (*Notification, error)
+	CompletePush(ctx context.Context, n *Notification) error
+	RetryPush(ctx context.Context, n *Notification, at time.Time, tokens []string) error
+	GetDeviceTokens(ctx context.Context, userID primitive.ObjectID) ([]string, error)
+	RemoveDeviceTokens(ctx context.Context, tokens []string) error
+}
+
+// ActorProvider defines interface to fetch the actor named in an alert
+type ActorProvider interface {
+	GetUserByObjectID(ctx context.Context, userID primitive.ObjectID) (*auth.User, error)
+}
+
+// PushWorker sends pending notifications to the recipient's devices. Workers on
+// every instance share the queue; a claim ensures each push is sent once.
+type PushWorker struct {
+	repo     PushQueue
+	authRepo ActorProvider
+	sender   push.Sender
+}
+
+// NewPushWorker creates a worker that delivers through sender
+func NewPushWorker(repo PushQueue, authRepo ActorProvider, sender push.Sender) *PushWorker {
+	return &PushWorker{
+		repo:     repo,
+		authRepo: authRepo,
+		sender:   sender,
+	}
+}
+
+// Start drains the queue until ctx is cancelled, polling when it is empty
+func (w *PushWorker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pushPollInterval)
+		defer ticker.Stop()
+
+		for {
+			for {
+				processed, err := w.ProcessNext(ctx)
+				if err != nil {
+					log.Printf("Push worker error: %v", err)
+					break
+				}
+				if !processed {
+					break
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// ProcessNext claims and delivers one pending notification.
+// It reports false when nothing was due.
+func (w *PushWorker) ProcessNext(ctx context.Context) (bool, error) {
+	n, err := w.repo.ClaimPendingPush(ctx, time.Now(), pushClaimLease)
+	if err != nil {
+		return false, err
+	}
+	if n == nil {
+		return false, nil
+	}
+
+	// Already seen in the app, so there is nothing to alert about
+	if n.IsRead {
+		return true, w.repo.CompletePush(ctx, n)
+	}
+
+	tokens, err := w.targetTokens(ctx, n)
+	if err != nil {
+		return true, w.retry(ctx, n, nil, err)
+	}
+	if len(tokens) == 0 {
+		return true, w.repo.CompletePush(ctx, n)
+	}
+
+	msg := w.buildMessage(ctx, n)
+	msg.Tokens = tokens
+
+	result, err := w.sender.Send(ctx, msg)
+	if err != nil {
+		return true, w.retry(ctx, n, nil, err)
+	}
+
+	if len(result.Invalid) > 0 {
+		if err := w.repo.RemoveDeviceTokens(ctx, result.Invalid); err != nil {
+			log.Printf("Failed to prune %d invalid push tokens: %v", len(result.Invalid), err)
+		}
+	}
+	if len(result.Failed) > 0 {
+		return true, w.retry(ctx, n, result.Failed, fmt.Errorf("%d device(s) failed", len(result.Failed)))
+	}
+
+	return true, w.repo.CompletePush(ctx, n)
+}
+
+// targetTokens returns the recipient's devices, narrowed to the ones that
+// failed last time when this is a retry
+func (w *PushWorker) targetTokens(ctx context.Context, n *Notification) ([]string, error) {
+	tokens, err := w.repo.GetDeviceTokens(ctx, n.RecipientID)
+	if err != nil || len(n.PushRetryTokens) == 0 {
+		return tokens, err
+	}
+
+	pending := make(map[string]bool, len(n.PushRetryTokens))
+	for _, token := range n.PushRetryTokens {
+		pending[token] = true
+	}
+	retry := make([]string, 0, len(n.PushRetryTokens))
+	for _, token := range tokens {
+		if pending[token] {
+			retry = append(retry, token)
+		}
+	}
+	return retry, nil
+}
+
+// retry schedules another attempt with exponential backoff, giving up after pushMaxAttempts
+func (w *PushWorker) retry(ctx context.Context, n *Notification, tokens []string, cause error) error {
+	if n.PushAttempts >= pushMaxAttempts {
+		log.Printf("Giving up on push for notification %s after %d attempts: %v", n.ID.Hex(), n.PushAttempts, cause)
+		return w.repo.CompletePush(ctx, n)
+	}
+
+	delay := pushRetryBackoff << (n.PushAttempts - 1)
+	return w.repo.RetryPush(ctx, n, time.Now().Add(delay), tokens)
+}
+
+// buildMessage renders the alert text and the data payload the app uses to open the notification
+func (w *PushWorker) buildMessage(ctx context.Context, n *Notification) push.Message {
+	// A deleted actor still gets an alert, just without a name
+	actorName := "Someone"
+	if n.Type != TypeWarning {
+		if actor, err := w.authRepo.GetUserByObjectID(ctx, n.ActorID); err == nil {
+			actorName = actor.DisplayName
+			if actorName == "" {
+				actorName = actor.Username
+			}
+		}
+	}
+
+	// "Alice" or "Alice and 2 others"
+	actors := actorName
+	if others := n.ActorCount - 1; others == 1 {
+		actors += " and 1 other"
+	} else if others > 1 {
+		actors += " and " + strconv.Itoa(others) + " others"
+	}
+
+	var title string
+	switch n.Type {
+	case TypeMention:
+		title = actorName + " mentioned you"
+	case TypeComment:
+		if n.ResourceType == "comment" {
+			title = actorName + " replied to your comment"
+		} else {
+			title = actorName + " commented on your anchor"
+		}
+	case TypeLike:
+		title = actors + " liked your anchor"
+	case TypeFollow:
+		title = actors + " started following you"
+	case TypeClone:
+		title = actorName + " cloned your anchor"
+	case TypeAnchorUpdate:
+		title = actorName + " added to an anchor you follow"
+	case TypeCollaboratorInvite:
+		title = actorName + " invited you to collaborate on an anchor"
+	case TypeCollaboratorItem:
+		title = actors + " added to an anchor you collaborate on"
+	case TypeWarning:
+		title = "Moderation notice"
+	default:
+		title = "New notification"
+	}
+
+	data := map[string]string{
+		"notificationId": n.ID.Hex(),
+		"type":           n.Type,
+		"resourceType":   n.ResourceType,
+		"resourceId":     n.ResourceID.Hex(),
+	}
+	if n.AnchorID != nil {
+		data["anchorId"] = n.AnchorID.Hex()
+	}
+
+	return push.Message{
+		Title: title,
+		Body:  n.Preview,
+		Data:  data,
+	}
+}
@@ -0,0 +1,214 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/pkg/push"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeQueue holds a single notification and its recipient's devices
+type fakeQueue struct {
+	n       Notification
+	devices []string
+	retries []time.Time // when each scheduled retry was due
+}
+
+func newFakeQueue(devices ...string) *fakeQueue {
+	return &fakeQueue{
+		n: Notification{
+			ID:          primitive.NewObjectID(),
+			RecipientID: primitive.NewObjectID(),
+			ActorID:     primitive.NewObjectID(),
+			ActorCount:  1,
+			Type:        TypeLike,
+			Preview:     "Weekend hikes",
+			PushPending: true,
+		},
+		devices: devices,
+	}
+}
+
+// makeDue brings the next attempt forward to now
+func (q *fakeQueue) makeDue() {
+	q.n.PushNextAttemptAt = nil
+}
+
+func (q *fakeQueue) ClaimPendingPush(ctx context.Context, now time.Time, lease time.Duration) (*Notification, error) {
+	if !q.n.PushPending || (q.n.PushNextAttemptAt != nil && q.n.PushNextAttemptAt.After(now)) {
+		return nil, nil
+	}
+	next := now.Add(lease)
+	q.n.PushNextAttemptAt = &next
+	q.n.PushAttempts++
+	claimed := q.n
+	return &claimed, nil
+}
+
+func (q *fakeQueue) CompletePush(ctx context.Context, n *Notification) error {
+	q.n.PushPending = false
+	q.n.PushNextAttemptAt = nil
+	q.n.PushRetryTokens = nil
+	return nil
+}
+
+func (q *fakeQueue) RetryPush(ctx context.Context, n *Notification, at time.Time, tokens []string) error {
+	q.n.PushNextAttemptAt = &at
+	if len(tokens) > 0 {
+		q.n.PushRetryTokens = tokens
+	}
+	q.retries = append(q.retries, at)
+	return nil
+}
+
+func (q *fakeQueue) GetDeviceTokens(ctx context.Context, userID primitive.ObjectID) ([]string, error) {
+	return append([]string(nil), q.devices...), nil
+}
+
+func (q *fakeQueue) RemoveDeviceTokens(ctx context.Context, tokens []string) error {
+	removed := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		removed[token] = true
+	}
+	kept := q.devices[:0]
+	for _, token := range q.devices {
+		if !removed[token] {
+			kept = append(kept, token)
+		}
+	}
+	q.devices = kept
+	return nil
+}
+
+type fakeActors struct{}
+
+func (fakeActors) GetUserByObjectID(ctx context.Context, userID primitive.ObjectID) (*auth.User, error) {
+	return nil, errors.New("user not found")
+}
+
+func TestPushWorker_RetriesWholeMessageAfterSendError(t *testing.T) {
+	q := newFakeQueue("a", "b")
+	sender := push.NewStubSender().Quiet()
+	sender.FailNext(1)
+	w := NewPushWorker(q, fakeActors{}, sender)
+	ctx := context.Background()
+
+	processed, err := w.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.True(t, processed)
+	require.True(t, q.n.PushPending)
+	require.Len(t, q.retries, 1)
+	require.Empty(t, q.n.PushRetryTokens)
+	require.Empty(t, sender.Sent())
+
+	// Not due again until the backoff has passed
+	processed, err = w.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.False(t, processed)
+
+	q.makeDue()
+	processed, err = w.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.True(t, processed)
+	require.False(t, q.n.PushPending)
+
+	sent := sender.Sent()
+	require.Len(t, sent, 1)
+	require.Equal(t, []string{"a", "b"}, sent[0].Tokens)
+	require.Equal(t, "Someone liked your anchor", sent[0].Title)
+}
+
+func TestPushWorker_RetriesOnlyFailedDevices(t *testing.T) {
+	q := newFakeQueue("a", "b")
+	sender := push.NewStubSender().Quiet()
+	sender.MarkFailing("b", true)
+	w := NewPushWorker(q, fakeActors{}, sender)
+	ctx := context.Background()
+
+	_, err := w.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.True(t, q.n.PushPending)
+	require.Equal(t, []string{"b"}, q.n.PushRetryTokens)
+
+	sender.MarkFailing("b", false)
+	q.makeDue()
+	_, err = w.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.False(t, q.n.PushPending)
+
+	sent := sender.Sent()
+	require.Len(t, sent, 2)
+	require.Equal(t, []string{"a"}, sent[0].Tokens)
+	require.Equal(t, []string{"b"}, sent[1].Tokens)
+}
+
+func TestPushWorker_GivesUpAfterMaxAttempts(t *testing.T) {
+	q := newFakeQueue("a")
+	sender := push.NewStubSender().Quiet()
+	sender.MarkFailing("a", true)
+	w := NewPushWorker(q, fakeActors{}, sender)
+	ctx := context.Background()
+
+	for i := 0; i < pushMaxAttempts; i++ {
+		q.makeDue()
+		processed, err := w.ProcessNext(ctx)
+		require.NoError(t, err)
+		require.True(t, processed)
+	}
+
+	require.False(t, q.n.PushPending)
+	require.Equal(t, pushMaxAttempts, q.n.PushAttempts)
+	require.Len(t, q.retries, pushMaxAttempts-1)
+	require.Empty(t, sender.Sent())
+
+	// The backoff doubles after each failed attempt
+	delay := time.Until(q.retries[0])
+	require.InDelta(t, pushRetryBackoff.Seconds(), delay.Seconds(), 1)
+	for i := 1; i < len(q.retries); i++ {
+		gap := q.retries[i].Sub(q.retries[i-1])
+		require.InDelta(t, (pushRetryBackoff << (i - 1)).Seconds(), gap.Seconds(), 1)
+	}
+
+	// Nothing is left to claim
+	q.makeDue()
+	processed, err := w.ProcessNext(ctx)
+	require.NoError(t, err)
+	require.False(t, processed)
+}
+
+func TestPushWorker_PrunesInvalidTokens(t *testing.T) {
+	q := newFakeQueue("ok", "gone")
+	sender := push.NewStubSender().Quiet()
+	sender.MarkInvalid("gone")
+	w := NewPushWorker(q, fakeActors{}, sender)
+
+	_, err := w.ProcessNext(context.Background())
+	require.NoError(t, err)
+
+	// Invalid tokens are forgotten, not retried
+	require.False(t, q.n.PushPending)
+	require.Empty(t, q.retries)
+	require.Equal(t, []string{"ok"}, q.devices)
+
+	sent := sender.Sent()
+	require.Len(t, sent, 1)
+	require.Equal(t, []string{"ok"}, sent[0].Tokens)
+}
+
+func TestPushWorker_SkipsReadNotifications(t *testing.T) {
+	q := newFakeQueue("a")
+	q.n.IsRead = true
+	sender := push.NewStubSender().Quiet()
+	w := NewPushWorker(q, fakeActors{}, sender)
+
+	processed, err := w.ProcessNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, processed)
+	require.False(t, q.n.PushPending)
+	require.Empty(t, sender.Sent())
+}
@@ -12,7 +12,10 @@ import (
 )
 
 type Repository struct {
-	collection *mongo.Collection
+	collection            *mongo.Collection
+	preferencesCollection *mongo.Collection
+	followsCollection     *mongo.Collection
+	devicesCollection     *mongo.Collection
 }
 
 func NewRepository(db *mongo.Database) *Repository {
@@ -36,16 +39,65 @@ func NewRepository(db *mongo.Database) *Repository {
 		{
 			Keys: bson.D{{Key: "createdAt", Value: 1}},
 		},
+		{
+			// Listing and stream resume order by last activity
+			Keys: bson.D{
+				{Key: "recipientId", Value: 1},
+				{Key: "updatedAt", Value: -1},
+			},
+		},
+		{
+			// Finding the open group to merge into
+			Keys: bson.D{
+				{Key: "recipientId", Value: 1},
+				{Key: "groupKey", Value: 1},
+				{Key: "updatedAt", Value: -1},
+			},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"groupKey": bson.M{"$exists": true}}),
+		},
+		{
+			// One open group per key, so concurrent first events cannot both start one
+			Keys: bson.D{
+				{Key: "recipientId", Value: 1},
+				{Key: "groupKey", Value: 1},
+			},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"groupOpen": true}),
+		},
+		{
+			// Push worker queue
+			Keys:    bson.D{{Key: "pushNextAttemptAt", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"pushPending": true}),
+		},
 	})
 
-	return &Repository{collection: collection}
+	devicesCollection := db.Collection("device_tokens")
+	_, _ = devicesCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}},
+		},
+	})
+
+	return &Repository{
+		collection:            collection,
+		preferencesCollection: db.Collection("notification_preferences"),
+		followsCollection:     db.Collection("follows"),
+		devicesCollection:     devicesCollection,
+	}
 }
 
 // CreateNotification creates a single notification
 func (r *Repository) CreateNotification(ctx context.Context, notification *Notification) error {
 	notification.ID = primitive.NewObjectID()
 	notification.CreatedAt = time.Now()
+	notification.UpdatedAt = notification.CreatedAt
 	notification.IsRead = false
+	if notification.PushPending {
+		notification.PushNextAttemptAt = &notification.CreatedAt
+	}
 
 	_, err := r.collection.InsertOne(ctx, notification)
 	return err
@@ -61,7 +113,11 @@ func (r *Repository) CreateMany(ctx context.Context, notifications []Notificatio
 	for i := range notifications {
 		notifications[i].ID = primitive.NewObjectID()
 		notifications[i].CreatedAt = time.Now()
+		notifications[i].UpdatedAt = notifications[i].CreatedAt
 		notifications[i].IsRead = false
+		if notifications[i].PushPending {
+			notifications[i].PushNextAttemptAt = &notifications[i].CreatedAt
+		}
 		docs[i] = notifications[i]
 	}
 
@@ -77,6 +133,9 @@ func (r *Repository) CreateNotifications(ctx context.Context, notifications []No
 
 	docs := make([]interface{}, len(notifications))
 	for i, n := range notifications {
+		if n.UpdatedAt.IsZero() {
+			n.UpdatedAt = n.CreatedAt
+		}
 		docs[i] = n
 	}
 
@@ -84,6 +143,116 @@ func (r *Repository) CreateNotifications(ctx context.Context, notifications []No
 	return err
 }
 
+// UpsertGrouped merges each notification into the recipient's open group for its
+// GroupKey, or starts a new group if none was active within GroupWindow.
+// A group that gains activity becomes unread again and moves to the top.
+func (r *Repository) UpsertGrouped(ctx context.Context, notifications []Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(notifications))
+	for _, n := range notifications {
+		filter := bson.M{
+			"recipientId": n.RecipientID,
+			"groupKey":    n.GroupKey,
+			"updatedAt":   bson.M{"$gte": now.Add(-GroupWindow)},
+		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(filter).
+			SetUpdate(groupUpdatePipeline(n, now)).
+			SetUpsert(true))
+	}
+
+	_, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	// Either a concurrent event opened the group first, or the recipient's last
+	// group went stale without being closed. Close stale groups and retry; the
+	// retry then merges into the group that won.
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return err
+	}
+	retry := make([]mongo.WriteModel, 0, len(bulkErr.WriteErrors))
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != 11000 {
+			return err
+		}
+		n := notifications[writeErr.Index]
+		if err := r.closeStaleGroups(ctx, n.RecipientID, n.GroupKey, now); err != nil {
+			return err
+		}
+		retry = append(retry, models[writeErr.Index])
+	}
+	_, err = r.collection.BulkWrite(ctx, retry, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// closeStaleGroups closes the recipient's groups for groupKey that had no
+// activity within GroupWindow, so a new group can be opened
+func (r *Repository) closeStaleGroups(ctx context.Context, recipientID primitive.ObjectID, groupKey string, now time.Time) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{
+		"recipientId": recipientID,
+		"groupKey":    groupKey,
+		"groupOpen":   true,
+		"updatedAt":   bson.M{"$lt": now.Add(-GroupWindow)},
+	}, bson.M{"$unset": bson.M{"groupOpen": ""}})
+	return err
+}
+
+// groupUpdatePipeline builds the aggregation-pipeline update that folds n into a group.
+// Running as a single update keeps concurrent merges from losing actors.
+func groupUpdatePipeline(n Notification, now time.Time) mongo.Pipeline {
+	existingActors := bson.M{"$ifNull": bson.A{"$actorIds", bson.A{}}}
+	seen := bson.M{"$in": bson.A{n.ActorID, existingActors}}
+
+	// Move a returning actor to the front; otherwise prepend and cap.
+	// Only the capped list is checked, so an older actor repeating counts again.
+	othersFirst := bson.M{"$filter": bson.M{
+		"input": existingActors,
+		"cond":  bson.M{"$ne": bson.A{"$$this", n.ActorID}},
+	}}
+	actorIDs := bson.M{"$slice": bson.A{
+		bson.M{"$concatArrays": bson.A{bson.A{n.ActorID}, othersFirst}},
+		GroupMaxActors,
+	}}
+
+	set := bson.M{
+		"actorIds":     actorIDs,
+		"actorCount":   bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$actorCount", 0}}, bson.M{"$cond": bson.A{seen, 0, 1}}}},
+		"eventCount":   bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$eventCount", 0}}, 1}},
+		"actorId":      n.ActorID,
+		"type":         n.Type,
+		"resourceType": n.ResourceType,
+		"resourceId":   n.ResourceID,
+		"preview":      n.Preview,
+		"silent":       n.Silent,
+		"isRead":       false,
+		"groupOpen":    true,
+		"createdAt":    bson.M{"$ifNull": bson.A{"$createdAt", now}},
+		"updatedAt":    now,
+	}
+	if n.AnchorID != nil {
+		set["anchorId"] = *n.AnchorID
+	}
+
+	// New activity queues a fresh push; a silent merge leaves any pending push as it is
+	if !n.PushPending {
+		return mongo.Pipeline{{{Key: "$set", Value: set}}}
+	}
+	set["pushPending"] = true
+	set["pushAttempts"] = 0
+	set["pushNextAttemptAt"] = now
+	return mongo.Pipeline{
+		{{Key: "$set", Value: set}},
+		{{Key: "$unset", Value: "pushRetryTokens"}},
+	}
+}
+
 // GetNotificationByID retrieves a notification by ID
 func (r *Repository) GetNotificationByID(ctx context.Context, notificationID primitive.ObjectID) (*Notification, error) {
 	var notification Notification
@@ -97,17 +266,21 @@ func (r *Repository) GetNotificationByID(ctx context.Context, notificationID pri
 	return &notification, nil
 }
 
-// GetUserNotifications retrieves notifications for a user
-func (r *Repository) GetUserNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, page, limit int) ([]Notification, int64, error) {
+// GetUserNotifications retrieves notifications for a user, skipping those from excluded actors
+func (r *Repository) GetUserNotifications(ctx context.Context, userID primitive.ObjectID, unreadOnly bool, page, limit int, excludeActorIDs []primitive.ObjectID) ([]Notification, int64, error) {
 	filter := bson.M{"recipientId": userID}
 	if unreadOnly {
 		filter["isRead"] = false
 	}
+	if len(excludeActorIDs) > 0 {
+		filter["actorId"] = bson.M{"$nin": excludeActorIDs}
+	}
 
-	// Sort: unread first, then by date
+	// Sort: unread first, then by latest activity (groups move up when they grow)
 	opts := options.Find().
 		SetSort(bson.D{
 			{Key: "isRead", Value: 1},
+			{Key: "updatedAt", Value: -1},
 			{Key: "createdAt", Value: -1},
 		}).
 		SetSkip(int64((page - 1) * limit)).
@@ -132,12 +305,48 @@ func (r *Repository) GetUserNotifications(ctx context.Context, userID primitive.
 	return notifications, total, nil
 }
 
-// CountUnread counts unread notifications for a user
-func (r *Repository) CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error) {
-	return r.collection.CountDocuments(ctx, bson.M{
+// GetNotificationsUpdatedSince retrieves a user's notifications created or regrouped after
+// the given time, oldest first, with notifications updated at exactly since only included
+// if their ID sorts after afterID. This replays what a reconnecting stream missed.
+func (r *Repository) GetNotificationsUpdatedSince(ctx context.Context, userID primitive.ObjectID, since time.Time, afterID primitive.ObjectID, limit int, excludeActorIDs []primitive.ObjectID) ([]Notification, error) {
+	filter := bson.M{
+		"recipientId": userID,
+		"$or": []bson.M{
+			{"updatedAt": bson.M{"$gt": since}},
+			{"updatedAt": since, "_id": bson.M{"$gt": afterID}},
+		},
+	}
+	if len(excludeActorIDs) > 0 {
+		filter["actorId"] = bson.M{"$nin": excludeActorIDs}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "updatedAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var notifications []Notification
+	if err = cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// CountUnread counts unread notifications for a user, skipping those from excluded actors
+func (r *Repository) CountUnread(ctx context.Context, userID primitive.ObjectID, excludeActorIDs []primitive.ObjectID) (int64, error) {
+	filter := bson.M{
 		"recipientId": userID,
 		"isRead":      false,
-	})
+	}
+	if len(excludeActorIDs) > 0 {
+		filter["actorId"] = bson.M{"$nin": excludeActorIDs}
+	}
+	return r.collection.CountDocuments(ctx, filter)
 }
 
 // MarkAsRead marks a notification as read
@@ -168,3 +377,199 @@ func (r *Repository) MarkAllAsRead(ctx context.Context, userID primitive.ObjectI
 	}
 	return result.ModifiedCount, nil
 }
+
+// GetPreferences returns the user's notification preferences, or the defaults if none are stored
+func (r *Repository) GetPreferences(ctx context.Context, userID primitive.ObjectID) (*Preferences, error) {
+	prefs := DefaultPreferences(userID)
+	err := r.preferencesCollection.FindOne(ctx, bson.M{"_id": userID}).Decode(prefs)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// GetPreferencesForUsers batch loads preferences; users without a document get the defaults
+func (r *Repository) GetPreferencesForUsers(ctx context.Context, userIDs []primitive.ObjectID) (map[primitive.ObjectID]*Preferences, error) {
+	result := make(map[primitive.ObjectID]*Preferences, len(userIDs))
+	for _, id := range userIDs {
+		result[id] = DefaultPreferences(id)
+	}
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	cursor, err := r.preferencesCollection.Find(ctx, bson.M{"_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var prefs Preferences
+		if err := cursor.Decode(&prefs); err != nil {
+			return nil, err
+		}
+		result[prefs.UserID] = &prefs
+	}
+	return result, cursor.Err()
+}
+
+// SavePreferences stores the user's full preferences document
+func (r *Repository) SavePreferences(ctx context.Context, prefs *Preferences) error {
+	prefs.UpdatedAt = time.Now()
+	_, err := r.preferencesCollection.ReplaceOne(ctx,
+		bson.M{"_id": prefs.UserID},
+		prefs,
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// GetFollowersAmong returns which of the candidate users follow the given user
+func (r *Repository) GetFollowersAmong(ctx context.Context, userID primitive.ObjectID, candidateIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	result := make(map[primitive.ObjectID]bool)
+	if len(candidateIDs) == 0 {
+		return result, nil
+	}
+
+	cursor, err := r.followsCollection.Find(ctx, bson.M{
+		"followingId": userID,
+		"followerId":  bson.M{"$in": candidateIDs},
+	}, options.Find().SetProjection(bson.M{"followerId": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			FollowerID primitive.ObjectID `bson:"followerId"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		result[doc.FollowerID] = true
+	}
+	return result, cursor.Err()
+}
+
+// ClaimPendingPush leases the next notification due for push delivery, so only
+// one worker sends it. Returns nil when nothing is due.
+func (r *Repository) ClaimPendingPush(ctx context.Context, now time.Time, lease time.Duration) (*Notification, error) {
+	var notification Notification
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"pushPending":       true,
+			"pushNextAttemptAt": bson.M{"$lte": now},
+		},
+		bson.M{
+			"$set": bson.M{"pushNextAttemptAt": now.Add(lease)},
+			"$inc": bson.M{"pushAttempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "pushNextAttemptAt", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&notification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// claimFilter matches a notification only while the worker's lease is still
+// current; new group activity resets the lease and must not be overwritten
+func claimFilter(n *Notification) bson.M {
+	return bson.M{
+		"_id":               n.ID,
+		"pushPending":       true,
+		"pushNextAttemptAt": n.PushNextAttemptAt,
+	}
+}
+
+// CompletePush clears the push state of a claimed notification
+func (r *Repository) CompletePush(ctx context.Context, n *Notification) error {
+	_, err := r.collection.UpdateOne(ctx, claimFilter(n), bson.M{
+		"$unset": bson.M{
+			"pushPending":       "",
+			"pushNextAttemptAt": "",
+			"pushRetryTokens":   "",
+		},
+	})
+	return err
+}
+
+// RetryPush schedules another attempt for a claimed notification. A nil tokens
+// slice retries every device of the recipient.
+func (r *Repository) RetryPush(ctx context.Context, n *Notification, at time.Time, tokens []string) error {
+	update := bson.M{"$set": bson.M{"pushNextAttemptAt": at}}
+	if len(tokens) > 0 {
+		update["$set"].(bson.M)["pushRetryTokens"] = tokens
+	}
+	_, err := r.collection.UpdateOne(ctx, claimFilter(n), update)
+	return err
+}
+
+// RegisterDevice stores a push token for the user, moving it from any previous owner
+func (r *Repository) RegisterDevice(ctx context.Context, userID primitive.ObjectID, token, platform string) (*Device, error) {
+	now := time.Now()
+	var device Device
+	err := r.devicesCollection.FindOneAndUpdate(ctx,
+		bson.M{"token": token},
+		bson.M{
+			"$set": bson.M{
+				"userId":     userID,
+				"platform":   platform,
+				"lastSeenAt": now,
+			},
+			"$setOnInsert": bson.M{"createdAt": now},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&device)
+	if err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// RemoveDevice deletes one of the user's push tokens
+func (r *Repository) RemoveDevice(ctx context.Context, userID primitive.ObjectID, token string) (bool, error) {
+	result, err := r.devicesCollection.DeleteOne(ctx, bson.M{"userId": userID, "token": token})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+// GetDeviceTokens returns every push token registered by the user
+func (r *Repository) GetDeviceTokens(ctx context.Context, userID primitive.ObjectID) ([]string, error) {
+	cursor, err := r.devicesCollection.Find(ctx, bson.M{"userId": userID},
+		options.Find().SetProjection(bson.M{"token": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tokens []string
+	for cursor.Next(ctx) {
+		var doc struct {
+			Token string `bson:"token"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, doc.Token)
+	}
+	return tokens, cursor.Err()
+}
+
+// RemoveDeviceTokens deletes tokens that the push provider rejected
+func (r *Repository) RemoveDeviceTokens(ctx context.Context, tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	_, err := r.devicesCollection.DeleteMany(ctx, bson.M{"token": bson.M{"$in": tokens}})
+	return err
+}
@@ -1,10 +1,14 @@
 package notifications
 
 import (
+	"context"
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
 	"github.com/xyz-asif/gotodo/internal/features/auth"
 	"github.com/xyz-asif/gotodo/internal/middleware"
+	"github.com/xyz-asif/gotodo/internal/pkg/push"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -13,8 +17,15 @@ func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Con
 	repo := NewRepository(db)
 	authRepo := auth.NewRepository(db)
 
+	// Real-time delivery: one change stream per instance, fanned out to open SSE connections
+	hub := NewHub(db)
+	hub.Start(context.Background())
+
+	// Push delivery: every instance runs a worker; claims keep pushes from being sent twice
+	NewPushWorker(repo, authRepo, newPushSender(cfg)).Start(context.Background())
+
 	// Initialize handler
-	handler := NewHandler(repo, authRepo, contentProvider, cfg)
+	handler := NewHandler(repo, authRepo, contentProvider, hub, cfg)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(authRepo, cfg)
@@ -25,9 +36,38 @@ func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Con
 	{
 		notifications.GET("", handler.ListNotifications)
 		notifications.GET("/unread-count", handler.GetUnreadCount)
+		notifications.GET("/stream", handler.StreamNotifications)
 		notifications.PATCH("/:id/read", handler.MarkAsRead)
 		notifications.PATCH("/read-all", handler.MarkAllAsRead)
 	}
+
+	// Notification preferences
+	me := router.Group("/users/me")
+	me.Use(authMiddleware)
+	{
+		me.GET("/notification-settings", handler.GetNotificationSettings)
+		me.PATCH("/notification-settings", handler.UpdateNotificationSettings)
+		me.POST("/devices", handler.RegisterDevice)
+		me.DELETE("/devices", handler.RemoveDevice)
+	}
+}
+
+// newPushSender picks the push driver from config, falling back to the stub
+// when Firebase cannot be initialized
+func newPushSender(cfg *config.Config) push.Sender {
+	if cfg.PushProvider != "fcm" {
+		return push.NewStubSender()
+	}
+
+	app, err := auth.NewFirebaseApp(cfg)
+	if err == nil {
+		var sender *push.FCMSender
+		if sender, err = push.NewFCMSender(context.Background(), app); err == nil {
+			return sender
+		}
+	}
+	log.Printf("Push notifications falling back to stub sender: %v", err)
+	return push.NewStubSender()
 }
 
 // GetService returns a notification service for use by other modules
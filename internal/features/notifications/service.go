@@ -2,6 +2,7 @@ package notifications
 
 import (
 	"context"
+	"log"
 	"time"
 
 	"github.com/xyz-asif/gotodo/internal/features/auth"
@@ -36,14 +37,21 @@ func (s *Service) SetFollowerProvider(provider FollowerProvider) {
 
 // CommentData holds data needed for notification creation
 type CommentData struct {
-	ID       primitive.ObjectID
-	AnchorID primitive.ObjectID
-	Content  string
-	Mentions []primitive.ObjectID
+	ID           primitive.ObjectID
+	AnchorID     primitive.ObjectID
+	Content      string
+	Mentions     []primitive.ObjectID
+	ParentID     *primitive.ObjectID // set for replies
+	ParentUserID primitive.ObjectID  // author of the comment replied to
 }
 
 // CreateCommentNotifications creates notifications for a new comment
 func (s *Service) CreateCommentNotifications(ctx context.Context, comment *CommentData, anchorID primitive.ObjectID, anchorUserID primitive.ObjectID, actor *auth.User) error {
+	// Shadow-banned users never reach anyone else
+	if actor.IsShadowBanned() {
+		return nil
+	}
+
 	var notifications []Notification
 
 	// 1. Mention notifications
@@ -69,19 +77,24 @@ func (s *Service) CreateCommentNotifications(ctx context.Context, comment *Comme
 		})
 	}
 
-	// 2. Comment notification to anchor owner
+	// 2. Comment notification to anchor owner, or for a reply to the parent comment's author
+	recipientID, resourceType, resourceID := anchorUserID, "anchor", anchorID
+	if comment.ParentID != nil {
+		recipientID, resourceType, resourceID = comment.ParentUserID, "comment", comment.ID
+	}
+
 	// Skip if:
-	// - Commenter is anchor owner (self-comment)
-	// - Anchor owner was already mentioned (avoid duplicate)
-	if anchorUserID != actor.ID && !containsID(comment.Mentions, anchorUserID) {
-		// Check if Owner has blocked Actor
-		if !s.isBlocked(ctx, anchorUserID, actor.ID) {
+	// - Commenter is the recipient (self-comment or self-reply)
+	// - Recipient was already mentioned (avoid duplicate)
+	if recipientID != actor.ID && !containsID(comment.Mentions, recipientID) {
+		// Check if Recipient has blocked Actor
+		if !s.isBlocked(ctx, recipientID, actor.ID) {
 			notifications = append(notifications, Notification{
-				RecipientID:  anchorUserID,
+				RecipientID:  recipientID,
 				ActorID:      actor.ID,
 				Type:         TypeComment,
-				ResourceType: "anchor",
-				ResourceID:   anchorID,
+				ResourceType: resourceType,
+				ResourceID:   resourceID,
 				AnchorID:     &anchorID,
 				Preview:      truncate(comment.Content, 100),
 			})
@@ -90,7 +103,7 @@ func (s *Service) CreateCommentNotifications(ctx context.Context, comment *Comme
 
 	// Batch insert
 	if len(notifications) > 0 {
-		return s.repo.CreateMany(ctx, notifications)
+		return s.deliver(ctx, notifications, false)
 	}
 
 	return nil
@@ -98,6 +111,10 @@ func (s *Service) CreateCommentNotifications(ctx context.Context, comment *Comme
 
 // CreateEditCommentNotifications creates notifications for NEW mentions in edited comment
 func (s *Service) CreateEditCommentNotifications(ctx context.Context, comment *CommentData, oldMentions []primitive.ObjectID, actor *auth.User) error {
+	if actor.IsShadowBanned() {
+		return nil
+	}
+
 	// Find new mentions (in current but not in old)
 	oldMentionSet := make(map[primitive.ObjectID]bool)
 	for _, id := range oldMentions {
@@ -139,7 +156,7 @@ func (s *Service) CreateEditCommentNotifications(ctx context.Context, comment *C
 	}
 
 	if len(notifications) > 0 {
-		return s.repo.CreateMany(ctx, notifications)
+		return s.deliver(ctx, notifications, false)
 	}
 
 	return nil
@@ -170,6 +187,10 @@ func (s *Service) CreateLikeNotification(ctx context.Context, anchorID primitive
 		return nil
 	}
 
+	if s.isShadowBanned(ctx, actorID) {
+		return nil
+	}
+
 	// Check if Owner has blocked Actor
 	if s.isBlocked(ctx, ownerID, actorID) {
 		return nil
@@ -183,9 +204,10 @@ func (s *Service) CreateLikeNotification(ctx context.Context, anchorID primitive
 		ResourceID:   anchorID,
 		AnchorID:     &anchorID,
 		Preview:      truncate(anchorTitle, 100),
+		GroupKey:     TypeLike + ":" + anchorID.Hex(),
 	}
 
-	return s.repo.CreateNotification(ctx, &notification)
+	return s.deliver(ctx, []Notification{notification}, true)
 }
 
 // CreateFollowNotification creates notification when someone follows a user
@@ -195,6 +217,10 @@ func (s *Service) CreateFollowNotification(ctx context.Context, actorID, targetU
 		return nil
 	}
 
+	if s.isShadowBanned(ctx, actorID) {
+		return nil
+	}
+
 	// Check if Target (Recipient) has blocked Actor
 	if s.isBlocked(ctx, targetUserID, actorID) {
 		return nil
@@ -208,9 +234,10 @@ func (s *Service) CreateFollowNotification(ctx context.Context, actorID, targetU
 		ResourceID:   actorID, // The follower is the resource
 		AnchorID:     nil,
 		Preview:      "",
+		GroupKey:     TypeFollow,
 	}
 
-	return s.repo.CreateNotification(ctx, &notification)
+	return s.deliver(ctx, []Notification{notification}, true)
 }
 
 // CreateCloneNotification creates notification when someone clones an anchor
@@ -220,6 +247,10 @@ func (s *Service) CreateCloneNotification(ctx context.Context, clonedAnchorID, o
 		return nil
 	}
 
+	if s.isShadowBanned(ctx, actorID) {
+		return nil
+	}
+
 	// Check if Owner has blocked Actor
 	if s.isBlocked(ctx, ownerID, actorID) {
 		return nil
@@ -235,7 +266,21 @@ func (s *Service) CreateCloneNotification(ctx context.Context, clonedAnchorID, o
 		Preview:      truncate(anchorTitle, 100),
 	}
 
-	return s.repo.CreateNotification(ctx, &notification)
+	return s.deliver(ctx, []Notification{notification}, false)
+}
+
+// CreateWarningNotification tells a user that a moderator has warned them about their content
+func (s *Service) CreateWarningNotification(ctx context.Context, recipientID, moderatorID primitive.ObjectID, resourceType string, resourceID primitive.ObjectID, note string) error {
+	notification := Notification{
+		RecipientID:  recipientID,
+		ActorID:      moderatorID,
+		Type:         TypeWarning,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Preview:      truncate(note, 100),
+	}
+
+	return s.deliver(ctx, []Notification{notification}, false)
 }
 
 func (s *Service) isBlocked(ctx context.Context, recipientID, actorID primitive.ObjectID) bool {
@@ -251,9 +296,94 @@ func (s *Service) isBlocked(ctx context.Context, recipientID, actorID primitive.
 	return false
 }
 
+// deliver applies each recipient's preferences, then stores what is left,
+// merging into open groups when grouped is set
+func (s *Service) deliver(ctx context.Context, notifications []Notification, grouped bool) error {
+	notifications = s.applyPreferences(ctx, notifications)
+	if len(notifications) == 0 {
+		return nil
+	}
+	for i := range notifications {
+		notifications[i].PushPending = !notifications[i].Silent
+	}
+	if grouped {
+		return s.repo.UpsertGrouped(ctx, notifications)
+	}
+	return s.repo.CreateMany(ctx, notifications)
+}
+
+// applyPreferences drops notifications that recipients opted out of and marks
+// those arriving in quiet hours as silent. Lookup failures deliver as normal.
+func (s *Service) applyPreferences(ctx context.Context, notifications []Notification) []Notification {
+	if len(notifications) == 0 {
+		return notifications
+	}
+
+	recipientIDs := make([]primitive.ObjectID, 0, len(notifications))
+	seen := make(map[primitive.ObjectID]bool)
+	for _, n := range notifications {
+		if !seen[n.RecipientID] {
+			seen[n.RecipientID] = true
+			recipientIDs = append(recipientIDs, n.RecipientID)
+		}
+	}
+
+	prefsMap, err := s.repo.GetPreferencesForUsers(ctx, recipientIDs)
+	if err != nil {
+		log.Printf("Failed to load notification preferences: %v", err)
+		return notifications
+	}
+
+	// Recipients that only accept notifications from people they follow, grouped by actor
+	restricted := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for _, n := range notifications {
+		if isPreferenceControlled(n.Type) && prefsMap[n.RecipientID].OnlyFromFollowing {
+			restricted[n.ActorID] = append(restricted[n.ActorID], n.RecipientID)
+		}
+	}
+	followsActor := make(map[primitive.ObjectID]map[primitive.ObjectID]bool, len(restricted))
+	for actorID, candidates := range restricted {
+		followers, err := s.repo.GetFollowersAmong(ctx, actorID, candidates)
+		if err != nil {
+			log.Printf("Failed to check followers for notification preferences: %v", err)
+			followers = make(map[primitive.ObjectID]bool)
+			for _, id := range candidates {
+				followers[id] = true
+			}
+		}
+		followsActor[actorID] = followers
+	}
+
+	now := time.Now()
+	kept := notifications[:0]
+	for _, n := range notifications {
+		prefs := prefsMap[n.RecipientID]
+		if isPreferenceControlled(n.Type) {
+			if !prefs.AllowsType(n.Type) {
+				continue
+			}
+			if prefs.OnlyFromFollowing && !followsActor[n.ActorID][n.RecipientID] {
+				continue
+			}
+		}
+		n.Silent = prefs.QuietHours.InQuietHours(now)
+		kept = append(kept, n)
+	}
+	return kept
+}
+
+// isShadowBanned reports whether the actor's activity should be hidden from other users
+func (s *Service) isShadowBanned(ctx context.Context, actorID primitive.ObjectID) bool {
+	banned, err := s.authRepo.IsShadowBanned(ctx, actorID)
+	if err != nil {
+		return false // Default to visible if error, like isBlocked
+	}
+	return banned
+}
+
 // CreateAnchorUpdateNotifications notifies followers about anchor updates
 func (s *Service) CreateAnchorUpdateNotifications(ctx context.Context, anchorID primitive.ObjectID, anchorTitle string, authorID primitive.ObjectID) error {
-	if s.followerProvider == nil {
+	if s.followerProvider == nil || s.isShadowBanned(ctx, authorID) {
 		return nil
 	}
 
@@ -267,8 +397,8 @@ func (s *Service) CreateAnchorUpdateNotifications(ctx context.Context, anchorID
 		return nil
 	}
 
-	// Create notifications in batch
-	now := time.Now()
+	// One group per follower per anchor, so repeated additions update in place
+	groupKey := TypeAnchorUpdate + ":" + anchorID.Hex()
 	notificationsList := make([]Notification, 0)
 
 	for _, followerID := range followerIDs {
@@ -278,7 +408,6 @@ func (s *Service) CreateAnchorUpdateNotifications(ctx context.Context, anchorID
 		}
 
 		notification := Notification{
-			ID:           primitive.NewObjectID(),
 			RecipientID:  followerID,
 			ActorID:      authorID,
 			Type:         TypeAnchorUpdate,
@@ -286,17 +415,62 @@ func (s *Service) CreateAnchorUpdateNotifications(ctx context.Context, anchorID
 			ResourceID:   anchorID,
 			AnchorID:     &anchorID,
 			Preview:      truncateString(anchorTitle, 100),
-			IsRead:       false,
-			CreatedAt:    now,
+			GroupKey:     groupKey,
 		}
 		notificationsList = append(notificationsList, notification)
 	}
 
-	if len(notificationsList) == 0 {
+	return s.deliver(ctx, notificationsList, true)
+}
+
+// CreateCollaboratorInviteNotification tells a user they were invited to collaborate on an anchor
+func (s *Service) CreateCollaboratorInviteNotification(ctx context.Context, anchorID primitive.ObjectID, anchorTitle string, ownerID, inviteeID primitive.ObjectID) error {
+	if s.isShadowBanned(ctx, ownerID) {
+		return nil
+	}
+
+	notification := Notification{
+		RecipientID:  inviteeID,
+		ActorID:      ownerID,
+		Type:         TypeCollaboratorInvite,
+		ResourceType: "anchor",
+		ResourceID:   anchorID,
+		AnchorID:     &anchorID,
+		Preview:      truncate(anchorTitle, 100),
+	}
+
+	return s.deliver(ctx, []Notification{notification}, false)
+}
+
+// CreateCollaboratorItemNotifications notifies an anchor's owner and collaborators
+// that one of them added an item. memberIDs may include the actor, who is skipped.
+func (s *Service) CreateCollaboratorItemNotifications(ctx context.Context, anchorID primitive.ObjectID, anchorTitle string, actorID primitive.ObjectID, memberIDs []primitive.ObjectID) error {
+	if s.isShadowBanned(ctx, actorID) {
 		return nil
 	}
 
-	return s.repo.CreateNotifications(ctx, notificationsList)
+	// One group per member per anchor, so repeated additions update in place
+	groupKey := TypeCollaboratorItem + ":" + anchorID.Hex()
+	notificationsList := make([]Notification, 0, len(memberIDs))
+
+	for _, memberID := range memberIDs {
+		if memberID == actorID {
+			continue
+		}
+
+		notificationsList = append(notificationsList, Notification{
+			RecipientID:  memberID,
+			ActorID:      actorID,
+			Type:         TypeCollaboratorItem,
+			ResourceType: "anchor",
+			ResourceID:   anchorID,
+			AnchorID:     &anchorID,
+			Preview:      truncate(anchorTitle, 100),
+			GroupKey:     groupKey,
+		})
+	}
+
+	return s.deliver(ctx, notificationsList, true)
 }
 
 // Helper function
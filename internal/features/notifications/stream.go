@@ -0,0 +1,165 @@
+package notifications
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StreamEvent is a change to a user's notifications, fanned out to their open streams
+type StreamEvent struct {
+	// Notification is set when a notification was inserted or a group gained activity
+	Notification *Notification
+	// UnreadChanged is set whenever the recipient's unread count may have changed
+	UnreadChanged bool
+}
+
+// streamBufferSize bounds how far a slow client may fall behind before events are dropped
+const streamBufferSize = 32
+
+// Hub watches the notifications collection with a change stream and delivers
+// events to subscribers on this instance. Because every instance watches the
+// same collection, a notification inserted anywhere reaches every open stream.
+type Hub struct {
+	collection *mongo.Collection
+
+	mu          sync.RWMutex
+	subscribers map[primitive.ObjectID]map[chan StreamEvent]struct{}
+}
+
+// NewHub creates a hub for the notifications collection
+func NewHub(db *mongo.Database) *Hub {
+	return &Hub{
+		collection:  db.Collection("notifications"),
+		subscribers: make(map[primitive.ObjectID]map[chan StreamEvent]struct{}),
+	}
+}
+
+// Subscribe registers a stream for the recipient. The returned cancel func must be called when the stream closes.
+func (h *Hub) Subscribe(recipientID primitive.ObjectID) (<-chan StreamEvent, func()) {
+	ch := make(chan StreamEvent, streamBufferSize)
+
+	h.mu.Lock()
+	if h.subscribers[recipientID] == nil {
+		h.subscribers[recipientID] = make(map[chan StreamEvent]struct{})
+	}
+	h.subscribers[recipientID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	cancel := func() {
+		h.mu.Lock()
+		delete(h.subscribers[recipientID], ch)
+		if len(h.subscribers[recipientID]) == 0 {
+			delete(h.subscribers, recipientID)
+		}
+		h.mu.Unlock()
+	}
+
+	return ch, cancel
+}
+
+// publish delivers an event without blocking; a full buffer drops the event,
+// and the next unread-count refresh brings the client back in sync
+func (h *Hub) publish(recipientID primitive.ObjectID, event StreamEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subscribers[recipientID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Start watches for changes until ctx is cancelled, reconnecting on errors
+func (h *Hub) Start(ctx context.Context) {
+	go func() {
+		var resumeToken bson.Raw
+		backoff := time.Second
+
+		for {
+			err := h.watch(ctx, &resumeToken)
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Printf("Notification change stream closed, retrying in %s: %v", backoff, err)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			if backoff < time.Minute {
+				backoff *= 2
+			}
+		}
+	}()
+}
+
+// watch runs one change stream session, recording the resume token as it goes
+func (h *Hub) watch(ctx context.Context, resumeToken *bson.Raw) error {
+	// Inserts, updates that flip isRead, and groups that gained activity
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"$or": []bson.M{
+				{"operationType": "insert"},
+				{
+					"operationType":                          "update",
+					"updateDescription.updatedFields.isRead": bson.M{"$exists": true},
+				},
+				{
+					"operationType": "update",
+					"updateDescription.updatedFields.eventCount": bson.M{"$exists": true},
+				},
+			},
+		}}},
+	}
+
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if *resumeToken != nil {
+		opts.SetResumeAfter(*resumeToken)
+	}
+
+	stream, err := h.collection.Watch(ctx, pipeline, opts)
+	if err != nil {
+		// The stored token may have rolled off the oplog; start fresh next time
+		*resumeToken = nil
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var change struct {
+			OperationType     string        `bson:"operationType"`
+			FullDocument      *Notification `bson:"fullDocument"`
+			UpdateDescription struct {
+				UpdatedFields bson.M `bson:"updatedFields"`
+			} `bson:"updateDescription"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			log.Printf("Failed to decode notification change: %v", err)
+			continue
+		}
+		*resumeToken = stream.ResumeToken()
+
+		if change.FullDocument == nil {
+			continue
+		}
+
+		event := StreamEvent{UnreadChanged: true}
+		_, grew := change.UpdateDescription.UpdatedFields["eventCount"]
+		if change.OperationType == "insert" || grew {
+			event.Notification = change.FullDocument
+		}
+		h.publish(change.FullDocument.RecipientID, event)
+	}
+
+	return stream.Err()
+}
@@ -1,5 +1,10 @@
 package notifications
 
+import (
+	"errors"
+	"time"
+)
+
 func ValidateNotificationListQuery(query *NotificationListQuery) error {
 	if query.Page < 1 {
 		query.Page = 1
@@ -14,3 +19,30 @@ func ValidateNotificationListQuery(query *NotificationListQuery) error {
 
 	return nil
 }
+
+func ValidateUpdatePreferencesRequest(req *UpdatePreferencesRequest) error {
+	if req.QuietHours == nil {
+		return nil
+	}
+
+	if req.QuietHours.Start != nil {
+		if _, err := parseClock(*req.QuietHours.Start); err != nil {
+			return err
+		}
+	}
+	if req.QuietHours.End != nil {
+		if _, err := parseClock(*req.QuietHours.End); err != nil {
+			return err
+		}
+	}
+	if req.QuietHours.Timezone != nil {
+		if *req.QuietHours.Timezone == "" {
+			return errors.New("timezone cannot be empty")
+		}
+		if _, err := time.LoadLocation(*req.QuietHours.Timezone); err != nil {
+			return errors.New("invalid timezone: " + *req.QuietHours.Timezone)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package purge
+
+import (
+	"errors"
+	"math"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Handler struct {
+	repo *Repository
+}
+
+func NewHandler(repo *Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// ListJobs godoc
+// @Summary List account purge jobs (admin)
+// @Description List account deletion jobs, newest first, with per-step progress
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status: pending, running, completed, failed"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Items per page (default 20, max 100)"
+// @Success 200 {object} response.APIResponse{data=PaginatedJobsResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /admin/purge-jobs [get]
+func (h *Handler) ListJobs(c *gin.Context) {
+	var query ListJobsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters", "INVALID_QUERY")
+		return
+	}
+
+	jobs, total, err := h.repo.ListJobs(c.Request.Context(), query.Status, query.Page, query.Limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch purge jobs", "DATABASE_ERROR")
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(query.Limit)))
+
+	var resp PaginatedJobsResponse
+	resp.Data = make([]JobResponse, len(jobs))
+	for i := range jobs {
+		resp.Data[i] = JobResponse{Job: &jobs[i], Progress: jobs[i].Progress()}
+	}
+	resp.Pagination.Page = query.Page
+	resp.Pagination.Limit = query.Limit
+	resp.Pagination.Total = total
+	resp.Pagination.TotalPages = totalPages
+	resp.Pagination.HasMore = query.Page < totalPages
+
+	response.Success(c, resp)
+}
+
+// GetJob godoc
+// @Summary Get an account purge job (admin)
+// @Description Get a deletion job's status and per-step progress
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} response.APIResponse{data=JobResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /admin/purge-jobs/{id} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid job ID", "INVALID_ID")
+		return
+	}
+
+	job, err := h.repo.GetJob(c.Request.Context(), jobID)
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	response.Success(c, JobResponse{Job: job, Progress: job.Progress()})
+}
+
+// RetryJob godoc
+// @Summary Retry a failed account purge job (admin)
+// @Description Requeue a job that gave up; it resumes from the step that failed
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID"
+// @Success 200 {object} response.APIResponse{data=JobResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /admin/purge-jobs/{id}/retry [post]
+func (h *Handler) RetryJob(c *gin.Context) {
+	jobID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid job ID", "INVALID_ID")
+		return
+	}
+
+	job, err := h.repo.Retry(c.Request.Context(), jobID)
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	response.Success(c, JobResponse{Job: job, Progress: job.Progress()})
+}
+
+func respondJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		response.NotFound(c, "Purge job not found", "JOB_NOT_FOUND")
+	case errors.Is(err, ErrJobNotFailed):
+		response.Conflict(c, "Only failed jobs can be retried", "JOB_NOT_FAILED")
+	default:
+		response.InternalServerError(c, "Failed to process purge job", "DATABASE_ERROR")
+	}
+}
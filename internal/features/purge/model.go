@@ -0,0 +1,101 @@
+package purge
+
+import (
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job status constants
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed" // gave up after maxAttempts; an admin can retry
+)
+
+// Step status constants
+const (
+	StepPending = "pending"
+	StepRunning = "running"
+	StepDone    = "done"
+)
+
+// Job is a durable, resumable deletion of everything a user left behind.
+// Progress is saved after every batch, so a crashed worker's job is picked up
+// where it stopped once its lease expires.
+type Job struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID primitive.ObjectID `bson:"userId" json:"userId"`
+	Status string             `bson:"status" json:"status"`
+	Steps  []StepProgress     `bson:"steps" json:"steps"`
+	// Current is the index of the step being worked on
+	Current int `bson:"current" json:"current"`
+	// Recount holds documents whose counters the current step is about to change.
+	// It is saved before a batch is deleted and cleared once the counters are
+	// recomputed, so a crash in between is repaired on resume.
+	Recount []primitive.ObjectID `bson:"recount,omitempty" json:"-"`
+	// Media is captured when the job is created, before the user document is gone
+	Media []MediaRef `bson:"media,omitempty" json:"-"`
+
+	Attempts    int        `bson:"attempts" json:"attempts"`
+	LastError   string     `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	LeaseUntil  *time.Time `bson:"leaseUntil,omitempty" json:"-"`
+	CreatedAt   time.Time  `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time  `bson:"updatedAt" json:"updatedAt"`
+	CompletedAt *time.Time `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// StepProgress reports what one step has done so far
+type StepProgress struct {
+	Name        string     `bson:"name" json:"name"`
+	Status      string     `bson:"status" json:"status"`
+	Deleted     int64      `bson:"deleted" json:"deleted"`
+	Updated     int64      `bson:"updated" json:"updated"` // counters and references fixed on other documents
+	CompletedAt *time.Time `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// MediaRef is an asset to remove from storage
+type MediaRef struct {
+	PublicID string       `bson:"publicId"`
+	Kind     storage.Kind `bson:"resourceType"` // stored under the name used before storage drivers
+}
+
+// Progress returns the share of steps finished, from 0 to 100
+func (j *Job) Progress() int {
+	if len(j.Steps) == 0 {
+		return 0
+	}
+	done := 0
+	for _, s := range j.Steps {
+		if s.Status == StepDone {
+			done++
+		}
+	}
+	return done * 100 / len(j.Steps)
+}
+
+// Response DTOs
+
+type JobResponse struct {
+	*Job
+	Progress int `json:"progress"`
+}
+
+type ListJobsQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending running completed failed"`
+	Page   int    `form:"page,default=1" binding:"min=1"`
+	Limit  int    `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+type PaginatedJobsResponse struct {
+	Data       []JobResponse `json:"data"`
+	Pagination struct {
+		Page       int   `json:"page"`
+		Limit      int   `json:"limit"`
+		Total      int64 `json:"total"`
+		TotalPages int   `json:"totalPages"`
+		HasMore    bool  `json:"hasMore"`
+	} `json:"pagination"`
+}
@@ -0,0 +1,252 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrJobNotFound  = errors.New("purge job not found")
+	ErrJobNotFailed = errors.New("only failed purge jobs can be retried")
+	ErrLeaseLost    = errors.New("purge job lease lost")
+)
+
+type Repository struct {
+	db             *mongo.Database
+	jobsCollection *mongo.Collection
+}
+
+func NewRepository(db *mongo.Database) *Repository {
+	jobsCollection := db.Collection("purge_jobs")
+
+	_, _ = jobsCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			// Worker queue
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "leaseUntil", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "userId", Value: 1},
+				{Key: "createdAt", Value: -1},
+			},
+		},
+	})
+
+	return &Repository{
+		db:             db,
+		jobsCollection: jobsCollection,
+	}
+}
+
+// Enqueue creates a purge job for the user, or returns the unfinished one
+// if the user already has one
+func (r *Repository) Enqueue(ctx context.Context, userID primitive.ObjectID, media []MediaRef) (*Job, error) {
+	now := time.Now()
+	steps := make([]StepProgress, len(purgeSteps))
+	for i, s := range purgeSteps {
+		steps[i] = StepProgress{Name: s.name, Status: StepPending}
+	}
+
+	var job Job
+	err := r.jobsCollection.FindOneAndUpdate(ctx,
+		bson.M{
+			"userId": userID,
+			"status": bson.M{"$ne": StatusCompleted},
+		},
+		bson.M{
+			"$setOnInsert": bson.M{
+				"status":    StatusPending,
+				"steps":     steps,
+				"current":   0,
+				"media":     media,
+				"attempts":  0,
+				"createdAt": now,
+				"updatedAt": now,
+			},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&job)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Claim leases the oldest job that is waiting or whose worker stopped renewing its lease.
+// Returns nil when there is nothing to do.
+func (r *Repository) Claim(ctx context.Context, now time.Time, lease time.Duration) (*Job, error) {
+	leaseUntil := now.Add(lease)
+
+	var job Job
+	err := r.jobsCollection.FindOneAndUpdate(ctx,
+		bson.M{
+			"status": bson.M{"$in": []string{StatusPending, StatusRunning}},
+			"$or": []bson.M{
+				{"leaseUntil": nil},
+				{"leaseUntil": bson.M{"$lte": now}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{
+				"status":     StatusRunning,
+				"leaseUntil": leaseUntil,
+				"updatedAt":  now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// SaveProgress stores the job's step progress and renews its lease.
+// It fails with ErrLeaseLost if another worker has taken the job over.
+func (r *Repository) SaveProgress(ctx context.Context, job *Job, lease time.Duration) error {
+	now := time.Now()
+	// Stored times have millisecond precision; truncate so the next save still matches
+	leaseUntil := now.Add(lease).Truncate(time.Millisecond)
+
+	result, err := r.jobsCollection.UpdateOne(ctx,
+		bson.M{"_id": job.ID, "leaseUntil": job.LeaseUntil},
+		bson.M{"$set": bson.M{
+			"steps":      job.Steps,
+			"current":    job.Current,
+			"recount":    job.Recount,
+			"leaseUntil": leaseUntil,
+			"updatedAt":  now,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrLeaseLost
+	}
+	job.LeaseUntil = &leaseUntil
+	job.UpdatedAt = now
+	return nil
+}
+
+// Complete marks a job finished
+func (r *Repository) Complete(ctx context.Context, job *Job) error {
+	now := time.Now()
+	_, err := r.jobsCollection.UpdateOne(ctx,
+		bson.M{"_id": job.ID, "leaseUntil": job.LeaseUntil},
+		bson.M{
+			"$set": bson.M{
+				"status":      StatusCompleted,
+				"steps":       job.Steps,
+				"current":     job.Current,
+				"completedAt": now,
+				"updatedAt":   now,
+			},
+			"$unset": bson.M{"leaseUntil": "", "recount": "", "lastError": "", "media": ""},
+		},
+	)
+	return err
+}
+
+// RecordFailure stores the error and the progress made so far, and schedules
+// another attempt at retryAt, or marks the job failed when giveUp is set
+func (r *Repository) RecordFailure(ctx context.Context, job *Job, cause error, retryAt time.Time, giveUp bool) error {
+	set := bson.M{
+		"steps":      job.Steps,
+		"current":    job.Current,
+		"recount":    job.Recount,
+		"lastError":  cause.Error(),
+		"leaseUntil": retryAt,
+		"updatedAt":  time.Now(),
+	}
+	if giveUp {
+		set["status"] = StatusFailed
+	}
+	_, err := r.jobsCollection.UpdateOne(ctx,
+		bson.M{"_id": job.ID, "leaseUntil": job.LeaseUntil},
+		bson.M{"$set": set},
+	)
+	return err
+}
+
+// Retry puts a failed job back in the queue, resuming from the step that failed
+func (r *Repository) Retry(ctx context.Context, jobID primitive.ObjectID) (*Job, error) {
+	var job Job
+	err := r.jobsCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": jobID, "status": StatusFailed},
+		bson.M{
+			"$set":   bson.M{"status": StatusPending, "attempts": 0, "updatedAt": time.Now()},
+			"$unset": bson.M{"leaseUntil": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, getErr := r.GetJob(ctx, jobID); getErr != nil {
+				return nil, getErr
+			}
+			return nil, ErrJobNotFailed
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJob returns a job by ID
+func (r *Repository) GetJob(ctx context.Context, jobID primitive.ObjectID) (*Job, error) {
+	var job Job
+	err := r.jobsCollection.FindOne(ctx, bson.M{"_id": jobID}).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListJobs returns jobs, newest first, optionally filtered by status
+func (r *Repository) ListJobs(ctx context.Context, status string, page, limit int) ([]Job, int64, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+
+	total, err := r.jobsCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetProjection(bson.M{"media": 0, "recount": 0})
+
+	cursor, err := r.jobsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []Job
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
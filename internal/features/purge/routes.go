@@ -0,0 +1,35 @@
+package purge
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/config"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/middleware"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RegisterRoutes starts the purge worker and sweeper and registers the admin progress routes.
+// content deletes anchors and is passed in because this package cannot import anchors.
+func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config, content ContentDeleter, media storage.Storage) {
+	repo := NewRepository(db)
+	authRepo := auth.NewRepository(db)
+
+	NewWorker(repo, content, media).Start(context.Background())
+
+	// Deleted accounts are purged once their grace period ends
+	NewSweeper(authRepo, NewService(repo)).Start(context.Background())
+
+	handler := NewHandler(repo)
+	authMiddleware := middleware.NewAuthMiddleware(authRepo, cfg)
+
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware, middleware.RequireAdmin())
+	{
+		admin.GET("/purge-jobs", handler.ListJobs)
+		admin.GET("/purge-jobs/:id", handler.GetJob)
+		admin.POST("/purge-jobs/:id/retry", handler.RetryJob)
+	}
+}
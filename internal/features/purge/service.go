@@ -0,0 +1,37 @@
+package purge
+
+import (
+	"context"
+
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Service queues purge jobs for other modules
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// EnqueuePurge queues the deletion of everything the user owns and returns the job ID.
+// Calling it again while a job is unfinished returns that job.
+func (s *Service) EnqueuePurge(ctx context.Context, user *auth.User) (primitive.ObjectID, error) {
+	// Asset IDs live on the user document, which the job deletes before the media step
+	var media []MediaRef
+	if user.ProfilePicturePublicID != "" {
+		media = append(media, MediaRef{PublicID: user.ProfilePicturePublicID, Kind: storage.KindImage})
+	}
+	if user.CoverImagePublicID != "" {
+		media = append(media, MediaRef{PublicID: user.CoverImagePublicID, Kind: storage.KindImage})
+	}
+
+	job, err := s.repo.Enqueue(ctx, user.ID, media)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return job.ID, nil
+}
@@ -0,0 +1,635 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// batchSize bounds how many documents one batch deletes between progress saves
+const batchSize = 500
+
+// step is one stage of a purge. run is called repeatedly until it reports done,
+// with progress saved in between, and must be safe to repeat after a crash.
+type step struct {
+	name string
+	run  func(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (done bool, err error)
+}
+
+// purgeSteps run in order. Steps that need the user's anchors come before
+// "anchors", and the user document goes after the user's content so the account
+// stays unusable (but identifiable) until that is gone. New steps are appended
+// so jobs already in progress keep their step positions.
+var purgeSteps = []step{
+	{name: "sessions", run: purgeSessions},
+	{name: "likes", run: batchStep(batchSpec{
+		collection: "likes",
+		filter:     func(u primitive.ObjectID) bson.M { return bson.M{"userId": u} },
+		refField:   "anchorId",
+		counter:    anchorLikeCount,
+	})},
+	{name: "comment_likes", run: batchStep(batchSpec{
+		collection: "commentLikes",
+		filter:     func(u primitive.ObjectID) bson.M { return bson.M{"userId": u} },
+		refField:   "commentId",
+		counter:    commentLikeCount,
+	})},
+	{name: "comments", run: batchStep(batchSpec{
+		collection: "comments",
+		// Comments with replies are anonymized by "comment_threads" instead
+		filter: func(u primitive.ObjectID) bson.M {
+			return bson.M{"userId": u, "replyCount": bson.M{"$not": bson.M{"$gt": 0}}}
+		},
+		refField: "anchorId",
+		counter:  anchorCommentCount,
+		// Likes other users left on the deleted comments, and the reply
+		// counts of the comments they replied to
+		cleanup: func(ctx context.Context, db *mongo.Database, ids []primitive.ObjectID) (int64, error) {
+			result, err := db.Collection("commentLikes").DeleteMany(ctx, bson.M{"commentId": bson.M{"$in": ids}})
+			if err != nil {
+				return 0, err
+			}
+			if err := recountReplies(ctx, db, ids); err != nil {
+				return 0, err
+			}
+			return result.DeletedCount, nil
+		},
+	})},
+	{name: "following", run: batchStep(batchSpec{
+		collection: "follows",
+		filter:     func(u primitive.ObjectID) bson.M { return bson.M{"followerId": u} },
+		refField:   "followingId",
+		counter:    userFollowerCount,
+	})},
+	{name: "followers", run: batchStep(batchSpec{
+		collection: "follows",
+		filter:     func(u primitive.ObjectID) bson.M { return bson.M{"followingId": u} },
+		refField:   "followerId",
+		counter:    userFollowingCount,
+	})},
+	{name: "anchor_follows", run: batchStep(batchSpec{
+		collection: "anchor_follows",
+		filter:     func(u primitive.ObjectID) bson.M { return bson.M{"userId": u} },
+		refField:   "anchorId",
+		counter:    anchorFollowerCount,
+	})},
+	{name: "anchor_engagement", run: purgeAnchorEngagement},
+	{name: "anchors", run: purgeAnchors},
+	{name: "notifications", run: purgeNotifications},
+	{name: "blocks", run: purgeBlocks},
+	{name: "reports", run: purgeReports},
+	{name: "user", run: purgeUser},
+	{name: "media", run: purgeMedia},
+	{name: "exports", run: purgeExports},
+	{name: "comment_threads", run: purgeCommentThreads},
+	{name: "bookmarks", run: purgeBookmarks},
+	{name: "collaborations", run: purgeCollaborations},
+	{name: "anchor_revisions", run: purgeRevisionActors},
+}
+
+// counter is a denormalized count kept on another document
+type counter struct {
+	collection   string // where the count lives
+	field        string
+	source       string // what is counted
+	sourceField  string // field in source referencing the counted document
+	sourceFilter bson.M
+	engagement   bool // anchors: recompute engagementScore as well
+	reactions    bool // recount reactionCounts by type as well
+}
+
+var (
+	anchorLikeCount     = counter{collection: "anchors", field: "likeCount", source: "likes", sourceField: "anchorId", engagement: true, reactions: true}
+	anchorCommentCount  = counter{collection: "anchors", field: "commentCount", source: "comments", sourceField: "anchorId", sourceFilter: bson.M{"deletedAt": nil, "status": nil}, engagement: true}
+	anchorFollowerCount = counter{collection: "anchors", field: "followerCount", source: "anchor_follows", sourceField: "anchorId"}
+	commentLikeCount    = counter{collection: "comments", field: "likeCount", source: "commentLikes", sourceField: "commentId", reactions: true}
+	userFollowerCount   = counter{collection: "users", field: "followerCount", source: "follows", sourceField: "followingId"}
+	userFollowingCount  = counter{collection: "users", field: "followingCount", source: "follows", sourceField: "followerId"}
+)
+
+// engagementScoreExpr matches anchors.Repository.UpdateEngagementScore:
+// likes*2 + clones*3 + comments + views/10
+var engagementScoreExpr = bson.M{"$add": bson.A{
+	bson.M{"$multiply": bson.A{"$likeCount", 2}},
+	bson.M{"$multiply": bson.A{"$cloneCount", 3}},
+	"$commentCount",
+	bson.M{"$floor": bson.M{"$divide": bson.A{"$viewCount", 10}}},
+}}
+
+// recount sets the counter on each document to the true count. Unlike an
+// increment, it gives the same result however many times it runs.
+func (c counter) recount(ctx context.Context, db *mongo.Database, ids []primitive.ObjectID) (int64, error) {
+	var updated int64
+	for _, id := range ids {
+		filter := bson.M{c.sourceField: id}
+		for k, v := range c.sourceFilter {
+			filter[k] = v
+		}
+		count, err := db.Collection(c.source).CountDocuments(ctx, filter)
+		if err != nil {
+			return updated, err
+		}
+
+		set := bson.M{c.field: count}
+		if c.reactions {
+			counts, err := countReactions(ctx, db.Collection(c.source), filter)
+			if err != nil {
+				return updated, err
+			}
+			// $literal replaces the whole map rather than merging into it
+			set["reactionCounts"] = bson.M{"$literal": counts}
+		}
+
+		// The score is a second stage so it sees the new count
+		pipeline := mongo.Pipeline{{{Key: "$set", Value: set}}}
+		if c.engagement {
+			pipeline = append(pipeline, bson.D{{Key: "$set", Value: bson.M{"engagementScore": engagementScoreExpr}}})
+		}
+
+		result, err := db.Collection(c.collection).UpdateOne(ctx, bson.M{"_id": id}, pipeline)
+		if err != nil {
+			return updated, err
+		}
+		updated += result.ModifiedCount
+	}
+	return updated, nil
+}
+
+// countReactions counts the reactions matching filter by type, the way
+// likes.ReactionStore keeps them in reactionCounts
+func countReactions(ctx context.Context, source *mongo.Collection, filter bson.M) (bson.M, error) {
+	cursor, err := source.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$reaction", "like"}},
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		Reaction string `bson:"_id"`
+		Count    int    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	counts := bson.M{}
+	for _, g := range groups {
+		counts[g.Reaction] = g.Count
+	}
+	return counts, nil
+}
+
+// batchSpec describes the user's documents in one collection whose removal
+// changes counters elsewhere
+type batchSpec struct {
+	collection string
+	filter     func(userID primitive.ObjectID) bson.M
+	refField   string // field referencing the document whose counter changes
+	counter    counter
+	cleanup    func(ctx context.Context, db *mongo.Database, ids []primitive.ObjectID) (int64, error)
+}
+
+// batchStep deletes one batch per call: the referenced documents are saved on
+// the job first, then the batch is deleted, then their counters are recounted
+func batchStep(spec batchSpec) func(context.Context, *Worker, *Job, *StepProgress) (bool, error) {
+	return func(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+		db := w.repo.db
+
+		// Finish the recount of a batch deleted before a crash
+		if len(job.Recount) > 0 {
+			updated, err := spec.counter.recount(ctx, db, job.Recount)
+			if err != nil {
+				return false, err
+			}
+			progress.Updated += updated
+			job.Recount = nil
+			return false, nil
+		}
+
+		cursor, err := db.Collection(spec.collection).Find(ctx, spec.filter(job.UserID),
+			options.Find().SetLimit(batchSize).SetProjection(bson.M{spec.refField: 1}))
+		if err != nil {
+			return false, err
+		}
+		var docs []bson.M
+		if err := cursor.All(ctx, &docs); err != nil {
+			return false, err
+		}
+		if len(docs) == 0 {
+			return true, nil
+		}
+
+		ids := make([]primitive.ObjectID, 0, len(docs))
+		seen := make(map[primitive.ObjectID]bool)
+		var refs []primitive.ObjectID
+		for _, doc := range docs {
+			ids = append(ids, doc["_id"].(primitive.ObjectID))
+			if ref, ok := doc[spec.refField].(primitive.ObjectID); ok && !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+
+		job.Recount = refs
+		if err := w.repo.SaveProgress(ctx, job, leaseDuration); err != nil {
+			return false, err
+		}
+
+		if spec.cleanup != nil {
+			deleted, err := spec.cleanup(ctx, db, ids)
+			if err != nil {
+				return false, err
+			}
+			progress.Deleted += deleted
+		}
+		result, err := db.Collection(spec.collection).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return false, err
+		}
+		progress.Deleted += result.DeletedCount
+
+		updated, err := spec.counter.recount(ctx, db, refs)
+		if err != nil {
+			return false, err
+		}
+		progress.Updated += updated
+		job.Recount = nil
+
+		return len(docs) < batchSize, nil
+	}
+}
+
+func purgeSessions(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	result, err := w.repo.db.Collection("refresh_tokens").DeleteMany(ctx, bson.M{"userId": job.UserID})
+	if err != nil {
+		return false, err
+	}
+	progress.Deleted += result.DeletedCount
+	return true, nil
+}
+
+// purgeAnchorEngagement removes what other users left on the user's anchors;
+// it has no counters to fix because the anchors themselves are deleted next
+func purgeAnchorEngagement(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	db := w.repo.db
+
+	anchorIDs, err := distinctIDs(ctx, db.Collection("anchors"), "_id", bson.M{"userId": job.UserID})
+	if err != nil {
+		return false, err
+	}
+	if len(anchorIDs) == 0 {
+		return true, nil
+	}
+	inAnchors := bson.M{"$in": anchorIDs}
+
+	commentIDs, err := distinctIDs(ctx, db.Collection("comments"), "_id", bson.M{"anchorId": inAnchors})
+	if err != nil {
+		return false, err
+	}
+
+	deletes := []struct {
+		collection string
+		filter     bson.M
+	}{
+		{"commentLikes", bson.M{"commentId": bson.M{"$in": commentIDs}}},
+		{"comments", bson.M{"anchorId": inAnchors}},
+		{"likes", bson.M{"anchorId": inAnchors}},
+		{"anchor_follows", bson.M{"anchorId": inAnchors}},
+		{"anchor_views", bson.M{"anchorId": inAnchors}},
+		{"reports", bson.M{"targetType": "anchor", "targetId": inAnchors}},
+	}
+	for _, d := range deletes {
+		result, err := db.Collection(d.collection).DeleteMany(ctx, d.filter)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", d.collection, err)
+		}
+		progress.Deleted += result.DeletedCount
+	}
+	return true, nil
+}
+
+// purgeAnchors deletes the user's anchors, items and their assets
+func purgeAnchors(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	anchors := w.repo.db.Collection("anchors")
+
+	before, err := anchors.CountDocuments(ctx, bson.M{"userId": job.UserID})
+	if err != nil {
+		return false, err
+	}
+	if before > 0 {
+		if err := w.content.DeleteAllByUser(ctx, job.UserID); err != nil {
+			return false, err
+		}
+	}
+
+	// The content deleter skips anchors it cannot read; retry until none are left
+	remaining, err := anchors.CountDocuments(ctx, bson.M{"userId": job.UserID})
+	if err != nil {
+		return false, err
+	}
+	progress.Deleted += before - remaining
+	if remaining > 0 {
+		return false, fmt.Errorf("%d anchors could not be deleted", remaining)
+	}
+	return true, nil
+}
+
+// purgeNotifications removes notifications to the user and takes the user out
+// of other people's notifications, including grouped ones
+func purgeNotifications(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	db := w.repo.db
+	notifications := db.Collection("notifications")
+	u := job.UserID
+
+	result, err := notifications.DeleteMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"recipientId": u},
+			{"actorId": u, "groupKey": bson.M{"$exists": false}},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	progress.Deleted += result.DeletedCount
+
+	// Drop the user from groups; the next most recent actor becomes the group's actor
+	update, err := notifications.UpdateMany(ctx,
+		bson.M{"groupKey": bson.M{"$exists": true}, "$or": []bson.M{{"actorId": u}, {"actorIds": u}}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"actorIds": bson.M{"$filter": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$actorIds", bson.A{}}},
+					"cond":  bson.M{"$ne": bson.A{"$$this", u}},
+				}},
+				"actorCount": bson.M{"$max": bson.A{bson.M{"$subtract": bson.A{bson.M{"$ifNull": bson.A{"$actorCount", 1}}, 1}}, 0}},
+			}}},
+			{{Key: "$set", Value: bson.M{
+				"actorId": bson.M{"$ifNull": bson.A{bson.M{"$first": "$actorIds"}, u}},
+			}}},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+	progress.Updated += update.ModifiedCount
+
+	// Groups where the user was the only actor left
+	result, err = notifications.DeleteMany(ctx, bson.M{"groupKey": bson.M{"$exists": true}, "actorId": u})
+	if err != nil {
+		return false, err
+	}
+	progress.Deleted += result.DeletedCount
+
+	for _, d := range []struct {
+		collection string
+		filter     bson.M
+	}{
+		{"notification_preferences", bson.M{"_id": u}},
+		{"device_tokens", bson.M{"userId": u}},
+	} {
+		result, err := db.Collection(d.collection).DeleteMany(ctx, d.filter)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", d.collection, err)
+		}
+		progress.Deleted += result.DeletedCount
+	}
+	return true, nil
+}
+
+// purgeBlocks removes the user from everyone's block list
+func purgeBlocks(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	result, err := w.repo.db.Collection("users").UpdateMany(ctx,
+		bson.M{"blockedUsers": job.UserID},
+		bson.M{"$pull": bson.M{"blockedUsers": job.UserID}},
+	)
+	if err != nil {
+		return false, err
+	}
+	progress.Updated += result.ModifiedCount
+	return true, nil
+}
+
+// purgeReports removes reports filed by or about the user.
+// The moderation audit log is kept.
+func purgeReports(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	result, err := w.repo.db.Collection("reports").DeleteMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"reporterId": job.UserID},
+			{"targetType": "user", "targetId": job.UserID},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	progress.Deleted += result.DeletedCount
+	return true, nil
+}
+
+// purgeBookmarks removes the user's saved anchors and folders. Other users'
+// saves of the user's anchors are kept and show as unavailable.
+func purgeBookmarks(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	for _, collection := range []string{"bookmarks", "bookmark_folders"} {
+		result, err := w.repo.db.Collection(collection).DeleteMany(ctx, bson.M{"userId": job.UserID})
+		if err != nil {
+			return false, err
+		}
+		progress.Deleted += result.DeletedCount
+	}
+	return true, nil
+}
+
+// purgeCollaborations removes the user from other users' anchors. Items they
+// added stay with the anchor, no longer attributed to them.
+func purgeCollaborations(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	result, err := w.repo.db.Collection("anchors").UpdateMany(ctx,
+		bson.M{"collaborators.userId": job.UserID},
+		bson.M{"$pull": bson.M{"collaborators": bson.M{"userId": job.UserID}}},
+	)
+	if err != nil {
+		return false, err
+	}
+	progress.Deleted += result.ModifiedCount
+
+	_, err = w.repo.db.Collection("items").UpdateMany(ctx,
+		bson.M{"addedBy": job.UserID},
+		bson.M{"$unset": bson.M{"addedBy": ""}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// purgeRevisionActors detaches the user from history entries on other users'
+// anchors; revisions of their own anchors went with the anchors
+func purgeRevisionActors(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	result, err := w.repo.db.Collection("anchor_revisions").UpdateMany(ctx,
+		bson.M{"actorId": job.UserID},
+		bson.M{"$unset": bson.M{"actorId": ""}},
+	)
+	if err != nil {
+		return false, err
+	}
+	progress.Updated += result.ModifiedCount
+	return true, nil
+}
+
+func purgeUser(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	result, err := w.repo.db.Collection("users").DeleteOne(ctx, bson.M{"_id": job.UserID})
+	if err != nil {
+		return false, err
+	}
+	progress.Deleted += result.DeletedCount
+	return true, nil
+}
+
+// purgeMedia deletes profile assets captured when the job was created
+func purgeMedia(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	if w.media == nil {
+		if len(job.Media) > 0 {
+			log.Printf("Purge job %s: no media storage configured, leaving %d assets", job.ID.Hex(), len(job.Media))
+		}
+		return true, nil
+	}
+
+	for _, m := range job.Media {
+		if err := w.media.Delete(ctx, m.Kind, m.PublicID); err != nil {
+			return false, fmt.Errorf("delete asset %s: %w", m.PublicID, err)
+		}
+	}
+	progress.Deleted += int64(len(job.Media))
+	return true, nil
+}
+
+// purgeExports deletes the user's data export archives and their records
+func purgeExports(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	db := w.repo.db
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName("exports"))
+	if err != nil {
+		return false, err
+	}
+
+	cursor, err := bucket.Find(bson.M{"metadata.userId": job.UserID})
+	if err != nil {
+		return false, err
+	}
+	var files []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &files); err != nil {
+		return false, err
+	}
+	for _, f := range files {
+		if err := bucket.Delete(f.ID); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+			return false, fmt.Errorf("delete export archive %s: %w", f.ID.Hex(), err)
+		}
+	}
+
+	result, err := db.Collection("exports").DeleteMany(ctx, bson.M{"userId": job.UserID})
+	if err != nil {
+		return false, err
+	}
+	progress.Deleted += result.DeletedCount
+	return true, nil
+}
+
+// purgeCommentThreads turns the user's comments that have replies into
+// anonymous tombstones, so other users' replies stay visible
+func purgeCommentThreads(ctx context.Context, w *Worker, job *Job, progress *StepProgress) (bool, error) {
+	db := w.repo.db
+	comments := db.Collection("comments")
+	filter := bson.M{"userId": job.UserID}
+
+	// Anchors whose comment count drops, saved before the update like batchStep does
+	if len(job.Recount) == 0 {
+		anchorIDs, err := distinctIDs(ctx, comments, "anchorId", filter)
+		if err != nil {
+			return false, err
+		}
+		if len(anchorIDs) == 0 {
+			return true, nil
+		}
+		job.Recount = anchorIDs
+		if err := w.repo.SaveProgress(ctx, job, leaseDuration); err != nil {
+			return false, err
+		}
+	}
+
+	now := time.Now()
+	result, err := comments.UpdateMany(ctx, filter, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"userId":    primitive.NilObjectID,
+			"content":   "",
+			"mentions":  bson.A{},
+			"tombstone": true,
+			"deletedAt": bson.M{"$ifNull": bson.A{"$deletedAt", now}},
+			"updatedAt": now,
+		}}},
+	})
+	if err != nil {
+		return false, err
+	}
+	progress.Updated += result.ModifiedCount
+
+	updated, err := anchorCommentCount.recount(ctx, db, job.Recount)
+	if err != nil {
+		return false, err
+	}
+	progress.Updated += updated
+	job.Recount = nil
+	return true, nil
+}
+
+// recountReplies sets the reply count of the comments replied to by ids as if
+// ids were already deleted, so it gives the same result when run again
+func recountReplies(ctx context.Context, db *mongo.Database, ids []primitive.ObjectID) error {
+	comments := db.Collection("comments")
+	parentIDs, err := distinctIDs(ctx, comments, "parentId", bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+
+	for _, parentID := range parentIDs {
+		count, err := comments.CountDocuments(ctx, bson.M{
+			"parentId": parentID,
+			"_id":      bson.M{"$nin": ids},
+			"status":   bson.M{"$ne": "held"},
+			"$or":      bson.A{bson.M{"deletedAt": nil}, bson.M{"tombstone": true}},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := comments.UpdateOne(ctx, bson.M{"_id": parentID},
+			bson.M{"$set": bson.M{"replyCount": count}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// distinctIDs returns the distinct ObjectID values of field in documents matching filter
+func distinctIDs(ctx context.Context, coll *mongo.Collection, field string, filter bson.M) ([]primitive.ObjectID, error) {
+	values, err := coll.Distinct(ctx, field, filter)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]primitive.ObjectID, 0, len(values))
+	for _, v := range values {
+		if id, ok := v.(primitive.ObjectID); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,92 @@
+package purge
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+)
+
+// Sweeper settings
+const (
+	sweepInterval  = 10 * time.Minute
+	sweepBatchSize = 100
+)
+
+// Sweeper queues purge jobs for deleted accounts whose grace period has ended
+type Sweeper struct {
+	authRepo *auth.Repository
+	service  *Service
+}
+
+func NewSweeper(authRepo *auth.Repository, service *Service) *Sweeper {
+	return &Sweeper{
+		authRepo: authRepo,
+		service:  service,
+	}
+}
+
+// Start sweeps until ctx is cancelled
+func (s *Sweeper) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			if queued, err := s.Sweep(ctx, time.Now()); err != nil {
+				log.Printf("Purge sweeper error: %v", err)
+			} else if queued > 0 {
+				log.Printf("Purge sweeper queued %d deleted accounts", queued)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Sweep queues a purge for every account due at now and returns how many were queued.
+// Closing the restore window comes first so an account is never restored mid-purge;
+// running it on several instances at once only queues the same job twice, which
+// Enqueue collapses into one.
+func (s *Sweeper) Sweep(ctx context.Context, now time.Time) (int, error) {
+	queued := 0
+	for {
+		users, err := s.authRepo.GetUsersDueForPurge(ctx, now, sweepBatchSize)
+		if err != nil {
+			return queued, err
+		}
+		if len(users) == 0 {
+			return queued, nil
+		}
+
+		for i := range users {
+			user := &users[i]
+			started, err := s.authRepo.MarkPurgeStarted(ctx, user.ID, now)
+			if err != nil {
+				return queued, err
+			}
+			if !started {
+				continue // restored just now
+			}
+
+			jobID, err := s.service.EnqueuePurge(ctx, user)
+			if err != nil {
+				return queued, err
+			}
+			if err := s.authRepo.MarkPurgeQueued(ctx, user.ID); err != nil {
+				return queued, err
+			}
+			log.Printf("Queued purge job %s for deleted user %s", jobID.Hex(), user.ID.Hex())
+			queued++
+		}
+
+		if len(users) < sweepBatchSize {
+			return queued, nil
+		}
+	}
+}
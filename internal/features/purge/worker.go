@@ -0,0 +1,140 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Worker settings
+const (
+	pollInterval  = 5 * time.Second
+	leaseDuration = 2 * time.Minute // renewed after every batch; a crashed worker's job resumes after this
+	maxAttempts   = 5
+	retryBackoff  = time.Minute // doubled after each failed attempt
+)
+
+// ContentDeleter deletes a user's anchors, their items and uploaded assets.
+// It is implemented outside this package to avoid an import cycle with anchors.
+type ContentDeleter interface {
+	DeleteAllByUser(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// MediaDeleter removes an asset from media storage
+type MediaDeleter interface {
+	Delete(ctx context.Context, kind storage.Kind, publicID string) error
+}
+
+// Worker runs purge jobs. Every instance runs one; leases keep two workers
+// from running the same job at once.
+type Worker struct {
+	repo    *Repository
+	content ContentDeleter
+	media   MediaDeleter
+}
+
+// NewWorker creates a purge worker. media may be nil when no storage is configured.
+func NewWorker(repo *Repository, content ContentDeleter, media MediaDeleter) *Worker {
+	return &Worker{
+		repo:    repo,
+		content: content,
+		media:   media,
+	}
+}
+
+// Start processes jobs until ctx is cancelled
+func (w *Worker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			for {
+				processed, err := w.ProcessNext(ctx)
+				if err != nil {
+					log.Printf("Purge worker error: %v", err)
+					break
+				}
+				if !processed {
+					break
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// ProcessNext claims one job and runs it to completion or to its next failure.
+// It reports false when no job was due.
+func (w *Worker) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := w.repo.Claim(ctx, time.Now(), leaseDuration)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	if err := w.run(ctx, job); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			return true, nil
+		}
+
+		giveUp := job.Attempts >= maxAttempts
+		retryAt := time.Now().Add(retryBackoff << (job.Attempts - 1))
+		log.Printf("Purge job %s failed at step %d (attempt %d): %v", job.ID.Hex(), job.Current, job.Attempts, err)
+		if recordErr := w.repo.RecordFailure(ctx, job, err, retryAt, giveUp); recordErr != nil {
+			return true, recordErr
+		}
+		return true, nil
+	}
+
+	log.Printf("Purge job %s for user %s completed", job.ID.Hex(), job.UserID.Hex())
+	return true, nil
+}
+
+// run continues the job from its current step, saving progress after every batch
+func (w *Worker) run(ctx context.Context, job *Job) error {
+	// Steps added since the job was created
+	for i := len(job.Steps); i < len(purgeSteps); i++ {
+		job.Steps = append(job.Steps, StepProgress{Name: purgeSteps[i].name, Status: StepPending})
+	}
+
+	for job.Current < len(purgeSteps) {
+		s := purgeSteps[job.Current]
+		progress := &job.Steps[job.Current]
+		progress.Status = StepRunning
+
+		for {
+			done, err := s.run(ctx, w, job, progress)
+			if err != nil {
+				return err
+			}
+			if done {
+				break
+			}
+			if err := w.repo.SaveProgress(ctx, job, leaseDuration); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		progress.Status = StepDone
+		progress.CompletedAt = &now
+		job.Current++
+		if err := w.repo.SaveProgress(ctx, job, leaseDuration); err != nil {
+			return err
+		}
+	}
+
+	return w.repo.Complete(ctx, job)
+}
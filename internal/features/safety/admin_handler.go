@@ -0,0 +1,541 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultSuspendDays is used when a suspension does not specify a duration
+const defaultSuspendDays = 7
+
+// ListReports godoc
+// @Summary List reports (admin)
+// @Description List reports in the moderation queue, oldest first
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status: pending, in_review, resolved, dismissed"
+// @Param targetType query string false "Filter by target type: anchor, item, user"
+// @Param page query int false "Page number (default 1)"
+// @Param limit query int false "Items per page (default 20, max 100)"
+// @Success 200 {object} response.APIResponse{data=PaginatedReportsResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Router /admin/reports [get]
+func (h *Handler) ListReports(c *gin.Context) {
+	var query ListReportsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.BadRequest(c, "Invalid query parameters", "INVALID_QUERY")
+		return
+	}
+
+	reports, total, err := h.repo.ListReports(c.Request.Context(), query.Status, query.TargetType, query.Page, query.Limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch reports", "DATABASE_ERROR")
+		return
+	}
+
+	totalPages := int(math.Ceil(float64(total) / float64(query.Limit)))
+
+	var resp PaginatedReportsResponse
+	resp.Data = reports
+	resp.Pagination.Page = query.Page
+	resp.Pagination.Limit = query.Limit
+	resp.Pagination.Total = total
+	resp.Pagination.TotalPages = totalPages
+	resp.Pagination.HasMore = query.Page < totalPages
+
+	response.Success(c, resp)
+}
+
+// GetReport godoc
+// @Summary Get report (admin)
+// @Description Get a report with its moderation audit trail
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report ID"
+// @Success 200 {object} response.APIResponse{data=ReportDetailResponse}
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Router /admin/reports/{id} [get]
+func (h *Handler) GetReport(c *gin.Context) {
+	reportID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid report ID", "INVALID_ID")
+		return
+	}
+
+	report, err := h.repo.GetReportByID(c.Request.Context(), reportID)
+	if err != nil {
+		h.respondReportError(c, err)
+		return
+	}
+
+	trail, err := h.repo.GetAuditTrail(c.Request.Context(), reportID)
+	if err != nil {
+		response.InternalServerError(c, "Failed to fetch audit trail", "DATABASE_ERROR")
+		return
+	}
+
+	response.Success(c, ReportDetailResponse{
+		Report:     *report,
+		AuditTrail: trail,
+	})
+}
+
+// ClaimReport godoc
+// @Summary Claim report (admin)
+// @Description Assign a pending report to the current moderator
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report ID"
+// @Success 200 {object} response.APIResponse{data=Report}
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /admin/reports/{id}/claim [post]
+func (h *Handler) ClaimReport(c *gin.Context) {
+	admin, reportID, ok := h.adminAndTargetID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.repo.ClaimReport(c.Request.Context(), reportID, admin.ID)
+	if err != nil {
+		h.respondReportError(c, err)
+		return
+	}
+
+	h.audit(c.Request.Context(), report, admin.ID, AuditClaim, "", "")
+
+	response.Success(c, report)
+}
+
+// ResolveReport godoc
+// @Summary Resolve report (admin)
+// @Description Apply a moderation action to the report's target and close the report
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report ID"
+// @Param request body ResolveReportRequest true "Resolution"
+// @Success 200 {object} response.APIResponse{data=Report}
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /admin/reports/{id}/resolve [post]
+func (h *Handler) ResolveReport(c *gin.Context) {
+	admin, reportID, ok := h.adminAndTargetID(c)
+	if !ok {
+		return
+	}
+
+	var req ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format", "INVALID_JSON")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	report, err := h.repo.GetReportByID(ctx, reportID)
+	if err != nil {
+		h.respondReportError(c, err)
+		return
+	}
+	if err := checkReportOpen(report, admin.ID); err != nil {
+		h.respondReportError(c, err)
+		return
+	}
+
+	target, err := h.resolutionTarget(ctx, report)
+	if err != nil {
+		respondResolutionError(c, err)
+		return
+	}
+	if err := checkResolution(report, target, admin.ID, &req); err != nil {
+		respondResolutionError(c, err)
+		return
+	}
+
+	// Closing first means only one moderator's resolution is ever applied
+	closed, err := h.repo.CloseReport(ctx, reportID, admin.ID, StatusResolved, req.Action, req.Note)
+	if err != nil {
+		h.respondReportError(c, err)
+		return
+	}
+
+	if err := h.applyResolution(ctx, report, target, admin.ID, &req); err != nil {
+		log.Printf("Failed to apply %s to report %s: %v", req.Action, reportID.Hex(), err)
+		if err := h.repo.ReopenReport(ctx, reportID, admin.ID); err != nil {
+			log.Printf("Failed to reopen report %s: %v", reportID.Hex(), err)
+		}
+		respondResolutionError(c, err)
+		return
+	}
+
+	h.audit(ctx, closed, admin.ID, AuditResolve, req.Action, req.Note)
+
+	response.Success(c, closed)
+}
+
+// DismissReport godoc
+// @Summary Dismiss report (admin)
+// @Description Close a report without taking action on its target
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report ID"
+// @Param request body DismissReportRequest false "Dismissal note"
+// @Success 200 {object} response.APIResponse{data=Report}
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 409 {object} response.APIResponse
+// @Router /admin/reports/{id}/dismiss [post]
+func (h *Handler) DismissReport(c *gin.Context) {
+	admin, reportID, ok := h.adminAndTargetID(c)
+	if !ok {
+		return
+	}
+
+	// Body is optional
+	var req DismissReportRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request format", "INVALID_JSON")
+			return
+		}
+	}
+
+	report, err := h.repo.CloseReport(c.Request.Context(), reportID, admin.ID, StatusDismissed, "", req.Note)
+	if err != nil {
+		h.respondReportError(c, err)
+		return
+	}
+
+	h.audit(c.Request.Context(), report, admin.ID, AuditDismiss, "", req.Note)
+
+	response.Success(c, report)
+}
+
+// UpdateUserModeration godoc
+// @Summary Set user moderation status (admin)
+// @Description Suspend, shadow-ban or reinstate a user outside of a report
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param request body UpdateModerationRequest true "Moderation status"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /admin/users/{id}/moderation [patch]
+func (h *Handler) UpdateUserModeration(c *gin.Context) {
+	admin, userID, ok := h.adminAndTargetID(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format", "INVALID_JSON")
+		return
+	}
+
+	if userID == admin.ID {
+		response.BadRequest(c, "Cannot change your own moderation status", "INVALID_TARGET")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var err error
+	switch req.Status {
+	case auth.ModerationSuspended:
+		var until *time.Time
+		if req.SuspendDays > 0 {
+			t := time.Now().AddDate(0, 0, req.SuspendDays)
+			until = &t
+		}
+		err = h.suspendUser(ctx, userID, until)
+	case auth.ModerationShadowBanned:
+		err = h.repo.ShadowBanUser(ctx, userID)
+	default:
+		err = h.repo.ReinstateUser(ctx, userID)
+	}
+	h.authRepo.InvalidateHiddenUsers()
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			response.NotFound(c, "User not found", "USER_NOT_FOUND")
+			return
+		}
+		response.InternalServerError(c, "Failed to update moderation status", "DATABASE_ERROR")
+		return
+	}
+
+	entry := &AuditEntry{
+		ActorID:    admin.ID,
+		Action:     AuditUser,
+		Resolution: req.Status,
+		TargetType: TargetUser,
+		TargetID:   userID,
+		Note:       req.Note,
+	}
+	if err := h.repo.AddAuditEntry(ctx, entry); err != nil {
+		log.Printf("Failed to write moderation audit entry for user %s: %v", userID.Hex(), err)
+	}
+
+	response.Success(c, gin.H{"userId": userID, "status": req.Status}, "Moderation status updated")
+}
+
+// UnhideAnchor godoc
+// @Summary Unhide anchor (admin)
+// @Description Reverse a hide_anchor resolution. The anchor stays private, but its owner can publish it again.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Anchor ID"
+// @Param request body DismissReportRequest false "Note for the audit trail"
+// @Success 200 {object} response.APIResponse
+// @Failure 403 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /admin/anchors/{id}/unhide [post]
+func (h *Handler) UnhideAnchor(c *gin.Context) {
+	admin, anchorID, ok := h.adminAndTargetID(c)
+	if !ok {
+		return
+	}
+
+	// Body is optional
+	var req DismissReportRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request format", "INVALID_JSON")
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+
+	if err := h.repo.UnhideAnchor(ctx, anchorID); err != nil {
+		if errors.Is(err, ErrAnchorNotFound) {
+			response.NotFound(c, "Anchor not found", "ANCHOR_NOT_FOUND")
+			return
+		}
+		response.InternalServerError(c, "Failed to unhide anchor", "DATABASE_ERROR")
+		return
+	}
+
+	entry := &AuditEntry{
+		ActorID:    admin.ID,
+		Action:     AuditUnhide,
+		TargetType: TargetAnchor,
+		TargetID:   anchorID,
+		Note:       req.Note,
+	}
+	if err := h.repo.AddAuditEntry(ctx, entry); err != nil {
+		log.Printf("Failed to write moderation audit entry for anchor %s: %v", anchorID.Hex(), err)
+	}
+
+	response.Success(c, gin.H{"anchorId": anchorID, "hiddenByModeration": false}, "Anchor unhidden")
+}
+
+// resolutionTarget identifies what a resolution acts on
+type resolutionTarget struct {
+	ownerID  primitive.ObjectID // the account responsible for the reported content
+	anchorID primitive.ObjectID // set for anchor and item reports
+}
+
+// resolutionTarget looks up the report target's owner and anchor
+func (h *Handler) resolutionTarget(ctx context.Context, report *Report) (resolutionTarget, error) {
+	var target resolutionTarget
+	var err error
+
+	switch report.TargetType {
+	case TargetAnchor:
+		target.anchorID = report.TargetID
+		target.ownerID, err = h.repo.GetAnchorOwner(ctx, target.anchorID)
+	case TargetItem:
+		target.anchorID, err = h.repo.GetItemAnchorID(ctx, report.TargetID)
+		if err == nil {
+			target.ownerID, err = h.repo.GetAnchorOwner(ctx, target.anchorID)
+		}
+	case TargetUser:
+		target.ownerID = report.TargetID
+	default:
+		err = fmt.Errorf("%w: unsupported target type %s", ErrInvalidResolution, report.TargetType)
+	}
+	return target, err
+}
+
+// checkResolution rejects actions that do not apply to the report
+func checkResolution(report *Report, target resolutionTarget, moderatorID primitive.ObjectID, req *ResolveReportRequest) error {
+	switch req.Action {
+	case ActionHideAnchor:
+		if report.TargetType != TargetAnchor {
+			return fmt.Errorf("%w: hide_anchor only applies to anchor reports", ErrInvalidResolution)
+		}
+	case ActionDeleteItem:
+		if report.TargetType != TargetItem {
+			return fmt.Errorf("%w: delete_item only applies to item reports", ErrInvalidResolution)
+		}
+	case ActionSuspendUser, ActionShadowBan:
+		if target.ownerID == moderatorID {
+			return fmt.Errorf("%w: cannot %s yourself", ErrInvalidResolution, strings.ReplaceAll(req.Action, "_", " "))
+		}
+	case ActionWarn:
+	default:
+		return fmt.Errorf("%w: unsupported action %s", ErrInvalidResolution, req.Action)
+	}
+	return nil
+}
+
+// applyResolution carries out a checked moderation action against the report's target
+func (h *Handler) applyResolution(ctx context.Context, report *Report, target resolutionTarget, moderatorID primitive.ObjectID, req *ResolveReportRequest) error {
+	switch req.Action {
+	case ActionHideAnchor:
+		return h.repo.HideAnchor(ctx, target.anchorID)
+
+	case ActionDeleteItem:
+		return h.repo.SoftDeleteItem(ctx, report.TargetID, target.anchorID)
+
+	case ActionSuspendUser:
+		days := req.SuspendDays
+		if days == 0 {
+			days = defaultSuspendDays
+		}
+		until := time.Now().AddDate(0, 0, days)
+		return h.suspendUser(ctx, target.ownerID, &until)
+
+	case ActionShadowBan:
+		defer h.authRepo.InvalidateHiddenUsers()
+		return h.repo.ShadowBanUser(ctx, target.ownerID)
+
+	case ActionWarn:
+		if err := h.repo.IncrementWarningCount(ctx, target.ownerID); err != nil {
+			return err
+		}
+		if h.notificationService != nil {
+			note := req.Note
+			if note == "" {
+				note = "Your content was reported and reviewed by a moderator."
+			}
+			if err := h.notificationService.CreateWarningNotification(ctx, target.ownerID, moderatorID, report.TargetType, report.TargetID, note); err != nil {
+				log.Printf("Failed to create warning notification: %v", err)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: unsupported action %s", ErrInvalidResolution, req.Action)
+}
+
+// respondResolutionError maps resolution failures to responses without
+// exposing database errors
+func respondResolutionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidResolution):
+		response.BadRequest(c, strings.TrimPrefix(err.Error(), ErrInvalidResolution.Error()+": "), "RESOLUTION_FAILED")
+	case errors.Is(err, ErrAnchorNotFound), errors.Is(err, ErrItemNotFound), errors.Is(err, ErrUserNotFound):
+		response.NotFound(c, "Report target not found", "TARGET_NOT_FOUND")
+	default:
+		response.InternalServerError(c, "Failed to apply resolution", "DATABASE_ERROR")
+	}
+}
+
+// suspendUser suspends an account and ends all of its sessions so it takes effect immediately
+func (h *Handler) suspendUser(ctx context.Context, userID primitive.ObjectID, until *time.Time) error {
+	if err := h.repo.SuspendUser(ctx, userID, until); err != nil {
+		return err
+	}
+	if err := h.authRepo.RevokeAllUserTokens(ctx, userID); err != nil {
+		log.Printf("Failed to revoke sessions for suspended user %s: %v", userID.Hex(), err)
+	}
+	return nil
+}
+
+// checkReportOpen mirrors CloseReport's conditions so effects are not applied to a report we cannot close
+func checkReportOpen(report *Report, moderatorID primitive.ObjectID) error {
+	switch report.Status {
+	case StatusResolved, StatusDismissed:
+		return ErrReportClosed
+	case StatusInReview:
+		if report.ClaimedBy != nil && *report.ClaimedBy != moderatorID {
+			return ErrReportClaimed
+		}
+	}
+	return nil
+}
+
+// audit records a moderator action; failures are logged but never block the action
+func (h *Handler) audit(ctx context.Context, report *Report, actorID primitive.ObjectID, action, resolution, note string) {
+	entry := &AuditEntry{
+		ReportID:   report.ID,
+		ActorID:    actorID,
+		Action:     action,
+		Resolution: resolution,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		Note:       note,
+	}
+	if err := h.repo.AddAuditEntry(ctx, entry); err != nil {
+		log.Printf("Failed to write moderation audit entry for report %s: %v", report.ID.Hex(), err)
+	}
+}
+
+// adminAndTargetID extracts the current admin and the ID path param
+func (h *Handler) adminAndTargetID(c *gin.Context) (*auth.User, primitive.ObjectID, bool) {
+	targetID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid ID format", "INVALID_ID")
+		return nil, primitive.NilObjectID, false
+	}
+
+	val, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "AUTH_FAILED")
+		return nil, primitive.NilObjectID, false
+	}
+	user, ok := val.(*auth.User)
+	if !ok {
+		response.InternalServerError(c, "User context error", "INTERNAL_ERROR")
+		return nil, primitive.NilObjectID, false
+	}
+
+	return user, targetID, true
+}
+
+// respondReportError maps repository errors to API responses
+func (h *Handler) respondReportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrReportNotFound):
+		response.NotFound(c, "Report not found", "REPORT_NOT_FOUND")
+	case errors.Is(err, ErrReportClaimed):
+		response.Conflict(c, "Report is claimed by another moderator", "REPORT_CLAIMED")
+	case errors.Is(err, ErrReportClosed):
+		response.Conflict(c, "Report is already closed", "REPORT_CLOSED")
+	default:
+		response.InternalServerError(c, "Failed to update report", "DATABASE_ERROR")
+	}
+}
@@ -4,21 +4,24 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
 	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/features/notifications"
 	"github.com/xyz-asif/gotodo/internal/pkg/response"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Handler struct {
-	repo     *Repository
-	authRepo *auth.Repository
-	cfg      *config.Config
+	repo                *Repository
+	authRepo            *auth.Repository
+	notificationService *notifications.Service
+	cfg                 *config.Config
 }
 
-func NewHandler(repo *Repository, authRepo *auth.Repository, cfg *config.Config) *Handler {
+func NewHandler(repo *Repository, authRepo *auth.Repository, notificationService *notifications.Service, cfg *config.Config) *Handler {
 	return &Handler{
-		repo:     repo,
-		authRepo: authRepo,
-		cfg:      cfg,
+		repo:                repo,
+		authRepo:            authRepo,
+		notificationService: notificationService,
+		cfg:                 cfg,
 	}
 }
 
@@ -6,15 +6,67 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Report status constants
+const (
+	StatusPending   = "pending"
+	StatusInReview  = "in_review"
+	StatusResolved  = "resolved"
+	StatusDismissed = "dismissed"
+)
+
+// Report target type constants
+const (
+	TargetAnchor = "anchor"
+	TargetItem   = "item"
+	TargetUser   = "user"
+)
+
+// Resolution action constants
+const (
+	ActionHideAnchor  = "hide_anchor"
+	ActionDeleteItem  = "delete_item"
+	ActionSuspendUser = "suspend_user"
+	ActionShadowBan   = "shadow_ban"
+	ActionWarn        = "warn"
+)
+
+// Audit action constants
+const (
+	AuditClaim   = "claim"
+	AuditResolve = "resolve"
+	AuditDismiss = "dismiss"
+	AuditUser    = "moderate_user" // direct change to a user's moderation status
+	AuditUnhide  = "unhide_anchor" // a hidden anchor was restored
+)
+
 type Report struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ReporterID     primitive.ObjectID  `bson:"reporterId" json:"reporterId"`
+	TargetID       primitive.ObjectID  `bson:"targetId" json:"targetId"`
+	TargetType     string              `bson:"targetType" json:"targetType"` // "anchor", "item", "user"
+	Reason         string              `bson:"reason" json:"reason"`
+	Status         string              `bson:"status" json:"status"` // "pending", "in_review", "resolved", "dismissed"
+	ClaimedBy      *primitive.ObjectID `bson:"claimedBy,omitempty" json:"claimedBy,omitempty"`
+	ClaimedAt      *time.Time          `bson:"claimedAt,omitempty" json:"claimedAt,omitempty"`
+	Resolution     string              `bson:"resolution,omitempty" json:"resolution,omitempty"`
+	ResolutionNote string              `bson:"resolutionNote,omitempty" json:"resolutionNote,omitempty"`
+	ResolvedBy     *primitive.ObjectID `bson:"resolvedBy,omitempty" json:"resolvedBy,omitempty"`
+	ResolvedAt     *time.Time          `bson:"resolvedAt,omitempty" json:"resolvedAt,omitempty"`
+	CreatedAt      time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// AuditEntry records a single moderator action for accountability
+type AuditEntry struct {
 	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	ReporterID primitive.ObjectID `bson:"reporterId" json:"reporterId"`
+	ReportID   primitive.ObjectID `bson:"reportId,omitempty" json:"reportId,omitempty"`
+	ActorID    primitive.ObjectID `bson:"actorId" json:"actorId"`
+	Action     string             `bson:"action" json:"action"` // "claim", "resolve", "dismiss", "moderate_user", "unhide_anchor"
+	Resolution string             `bson:"resolution,omitempty" json:"resolution,omitempty"`
+	TargetType string             `bson:"targetType" json:"targetType"`
 	TargetID   primitive.ObjectID `bson:"targetId" json:"targetId"`
-	TargetType string             `bson:"targetType" json:"targetType"` // "anchor", "item", "user"
-	Reason     string             `bson:"reason" json:"reason"`
-	Status     string             `bson:"status" json:"status"` // "pending", "reviewed"
+	Note       string             `bson:"note,omitempty" json:"note,omitempty"`
 	CreatedAt  time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt  time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
 
 type CreateReportRequest struct {
@@ -26,3 +78,49 @@ type CreateReportRequest struct {
 type BlockUserRequest struct {
 	// User ID to block is passed in path parameter
 }
+
+// ListReportsQuery represents the filters for the admin report queue
+type ListReportsQuery struct {
+	Status     string `form:"status" binding:"omitempty,oneof=pending in_review resolved dismissed"`
+	TargetType string `form:"targetType" binding:"omitempty,oneof=anchor item user"`
+	Page       int    `form:"page,default=1" binding:"min=1"`
+	Limit      int    `form:"limit,default=20" binding:"min=1,max=100"`
+}
+
+// ResolveReportRequest represents the moderator's decision on a report
+type ResolveReportRequest struct {
+	Action      string `json:"action" binding:"required,oneof=hide_anchor delete_item suspend_user shadow_ban warn"`
+	Note        string `json:"note" binding:"omitempty,max=1000"`
+	SuspendDays int    `json:"suspendDays" binding:"omitempty,min=1,max=3650"`
+}
+
+// UpdateModerationRequest sets a user's moderation status directly.
+// "active" lifts any suspension or shadow-ban.
+type UpdateModerationRequest struct {
+	Status      string `json:"status" binding:"required,oneof=active suspended shadow_banned"`
+	SuspendDays int    `json:"suspendDays" binding:"omitempty,min=1,max=3650"` // omit for an indefinite suspension
+	Note        string `json:"note" binding:"omitempty,max=1000"`
+}
+
+// DismissReportRequest represents the payload for dismissing a report
+type DismissReportRequest struct {
+	Note string `json:"note" binding:"omitempty,max=1000"`
+}
+
+// ReportDetailResponse is a report together with its audit trail
+type ReportDetailResponse struct {
+	Report     Report       `json:"report"`
+	AuditTrail []AuditEntry `json:"auditTrail"`
+}
+
+// PaginatedReportsResponse is the admin report queue page
+type PaginatedReportsResponse struct {
+	Data       []Report `json:"data"`
+	Pagination struct {
+		Page       int   `json:"page"`
+		Limit      int   `json:"limit"`
+		Total      int64 `json:"total"`
+		TotalPages int   `json:"totalPages"`
+		HasMore    bool  `json:"hasMore"`
+	} `json:"pagination"`
+}
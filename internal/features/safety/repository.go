@@ -5,27 +5,73 @@ import (
 	"errors"
 	"time"
 
+	"github.com/xyz-asif/gotodo/internal/features/auth"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrReportNotFound = errors.New("report not found")
+	ErrReportClaimed  = errors.New("report is claimed by another moderator")
+	ErrReportClosed   = errors.New("report is already closed")
+	ErrUserNotFound   = errors.New("user not found")
+	ErrAnchorNotFound = errors.New("anchor not found")
+	ErrItemNotFound   = errors.New("item not found")
+
+	// ErrInvalidResolution is wrapped with the reason a resolution does not apply
+	ErrInvalidResolution = errors.New("invalid resolution")
 )
 
 type Repository struct {
 	reportsCollection *mongo.Collection
 	usersCollection   *mongo.Collection
+	anchorsCollection *mongo.Collection
+	itemsCollection   *mongo.Collection
+	auditCollection   *mongo.Collection
 }
 
 func NewRepository(db *mongo.Database) *Repository {
+	reportsCollection := db.Collection("reports")
+	auditCollection := db.Collection("moderation_audit")
+
+	_, _ = reportsCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			// Admin queue: filter by status/type, oldest first
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "targetType", Value: 1},
+				{Key: "createdAt", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{{Key: "targetId", Value: 1}},
+		},
+	})
+
+	_, _ = auditCollection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "reportId", Value: 1}, {Key: "createdAt", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "actorId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	})
+
 	return &Repository{
-		reportsCollection: db.Collection("reports"),
+		reportsCollection: reportsCollection,
 		usersCollection:   db.Collection("users"),
+		anchorsCollection: db.Collection("anchors"),
+		itemsCollection:   db.Collection("items"),
+		auditCollection:   auditCollection,
 	}
 }
 
 func (r *Repository) CreateReport(ctx context.Context, report *Report) error {
 	report.CreatedAt = time.Now()
 	report.UpdatedAt = time.Now()
-	report.Status = "pending"
+	report.Status = StatusPending
 
 	result, err := r.reportsCollection.InsertOne(ctx, report)
 	if err != nil {
@@ -62,3 +108,317 @@ func (r *Repository) RemoveBlock(ctx context.Context, blockerID, blockedID primi
 	_, err := r.usersCollection.UpdateOne(ctx, filter, update)
 	return err
 }
+
+// ListReports returns reports matching the optional status and target type filters
+func (r *Repository) ListReports(ctx context.Context, status, targetType string, page, limit int) ([]Report, int64, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	if targetType != "" {
+		filter["targetType"] = targetType
+	}
+
+	total, err := r.reportsCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}}).
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.reportsCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var reports []Report
+	if err = cursor.All(ctx, &reports); err != nil {
+		return nil, 0, err
+	}
+	if reports == nil {
+		reports = []Report{}
+	}
+
+	return reports, total, nil
+}
+
+// GetReportByID finds a report by its ID
+func (r *Repository) GetReportByID(ctx context.Context, reportID primitive.ObjectID) (*Report, error) {
+	var report Report
+	err := r.reportsCollection.FindOne(ctx, bson.M{"_id": reportID}).Decode(&report)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ClaimReport assigns a pending report to a moderator
+func (r *Repository) ClaimReport(ctx context.Context, reportID, moderatorID primitive.ObjectID) (*Report, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id":    reportID,
+		"status": StatusPending,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":    StatusInReview,
+			"claimedBy": moderatorID,
+			"claimedAt": now,
+			"updatedAt": now,
+		},
+	}
+
+	var report Report
+	err := r.reportsCollection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&report)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, r.closedOrClaimedError(ctx, reportID, moderatorID)
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+// CloseReport marks an open report resolved or dismissed. A report claimed by
+// another moderator cannot be closed.
+func (r *Repository) CloseReport(ctx context.Context, reportID, moderatorID primitive.ObjectID, status, resolution, note string) (*Report, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id": reportID,
+		"$or": []bson.M{
+			{"status": StatusPending},
+			{"status": StatusInReview, "claimedBy": moderatorID},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":         status,
+			"resolution":     resolution,
+			"resolutionNote": note,
+			"resolvedBy":     moderatorID,
+			"resolvedAt":     now,
+			"updatedAt":      now,
+		},
+	}
+
+	var report Report
+	err := r.reportsCollection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&report)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, r.closedOrClaimedError(ctx, reportID, moderatorID)
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ReopenReport puts a report this moderator just closed back in review, for
+// when its resolution could not be applied
+func (r *Repository) ReopenReport(ctx context.Context, reportID, moderatorID primitive.ObjectID) error {
+	now := time.Now()
+	_, err := r.reportsCollection.UpdateOne(ctx,
+		bson.M{"_id": reportID, "status": StatusResolved, "resolvedBy": moderatorID},
+		bson.M{
+			"$set":   bson.M{"status": StatusInReview, "claimedBy": moderatorID, "claimedAt": now, "updatedAt": now},
+			"$unset": bson.M{"resolution": "", "resolutionNote": "", "resolvedBy": "", "resolvedAt": ""},
+		},
+	)
+	return err
+}
+
+// closedOrClaimedError explains why a conditional report update matched nothing
+func (r *Repository) closedOrClaimedError(ctx context.Context, reportID, moderatorID primitive.ObjectID) error {
+	report, err := r.GetReportByID(ctx, reportID)
+	if err != nil {
+		return err
+	}
+	if report.Status == StatusResolved || report.Status == StatusDismissed {
+		return ErrReportClosed
+	}
+	if report.ClaimedBy != nil && *report.ClaimedBy != moderatorID {
+		return ErrReportClaimed
+	}
+	return ErrReportClosed
+}
+
+// AddAuditEntry appends an entry to the moderation audit trail
+func (r *Repository) AddAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	entry.CreatedAt = time.Now()
+
+	result, err := r.auditCollection.InsertOne(ctx, entry)
+	if err != nil {
+		return err
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		entry.ID = oid
+	}
+	return nil
+}
+
+// GetAuditTrail returns the audit entries for a report, oldest first
+func (r *Repository) GetAuditTrail(ctx context.Context, reportID primitive.ObjectID) ([]AuditEntry, error) {
+	cursor, err := r.auditCollection.Find(ctx, bson.M{"reportId": reportID},
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []AuditEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []AuditEntry{}
+	}
+	return entries, nil
+}
+
+// GetAnchorOwner returns the owner of a non-deleted anchor
+func (r *Repository) GetAnchorOwner(ctx context.Context, anchorID primitive.ObjectID) (primitive.ObjectID, error) {
+	var doc struct {
+		UserID primitive.ObjectID `bson:"userId"`
+	}
+	err := r.anchorsCollection.FindOne(ctx, bson.M{"_id": anchorID, "deletedAt": nil},
+		options.FindOne().SetProjection(bson.M{"userId": 1})).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return primitive.NilObjectID, ErrAnchorNotFound
+		}
+		return primitive.NilObjectID, err
+	}
+	return doc.UserID, nil
+}
+
+// GetItemAnchorID returns the anchor a non-deleted item belongs to
+func (r *Repository) GetItemAnchorID(ctx context.Context, itemID primitive.ObjectID) (primitive.ObjectID, error) {
+	var doc struct {
+		AnchorID primitive.ObjectID `bson:"anchorId"`
+	}
+	err := r.itemsCollection.FindOne(ctx, bson.M{"_id": itemID, "deletedAt": nil},
+		options.FindOne().SetProjection(bson.M{"anchorId": 1})).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return primitive.NilObjectID, ErrItemNotFound
+		}
+		return primitive.NilObjectID, err
+	}
+	return doc.AnchorID, nil
+}
+
+// HideAnchor makes an anchor private and locks it there until a moderator reverses it
+func (r *Repository) HideAnchor(ctx context.Context, anchorID primitive.ObjectID) error {
+	update := bson.M{
+		"$set": bson.M{
+			"visibility":         "private",
+			"hiddenByModeration": true,
+			"isPinned":           false,
+			"updatedAt":          time.Now(),
+		},
+	}
+	_, err := r.anchorsCollection.UpdateOne(ctx, bson.M{"_id": anchorID}, update)
+	return err
+}
+
+// UnhideAnchor lifts a moderation hide. The anchor stays private until its
+// owner chooses to publish it again.
+func (r *Repository) UnhideAnchor(ctx context.Context, anchorID primitive.ObjectID) error {
+	result, err := r.anchorsCollection.UpdateOne(ctx,
+		bson.M{"_id": anchorID, "deletedAt": nil},
+		bson.M{
+			"$set":   bson.M{"updatedAt": time.Now()},
+			"$unset": bson.M{"hiddenByModeration": ""},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrAnchorNotFound
+	}
+	return nil
+}
+
+// SoftDeleteItem hides an item and keeps its anchor's item count in step
+func (r *Repository) SoftDeleteItem(ctx context.Context, itemID, anchorID primitive.ObjectID) error {
+	now := time.Now()
+	result, err := r.itemsCollection.UpdateOne(ctx,
+		bson.M{"_id": itemID, "deletedAt": nil},
+		bson.M{"$set": bson.M{"deletedAt": now, "updatedAt": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return nil
+	}
+
+	_, err = r.anchorsCollection.UpdateOne(ctx, bson.M{"_id": anchorID}, bson.M{
+		"$inc": bson.M{"itemCount": -1},
+		"$set": bson.M{"updatedAt": now},
+	})
+	return err
+}
+
+// SuspendUser suspends a user account until the given time, or indefinitely if until is nil
+func (r *Repository) SuspendUser(ctx context.Context, userID primitive.ObjectID, until *time.Time) error {
+	set := bson.M{
+		"moderationStatus": auth.ModerationSuspended,
+		"updatedAt":        time.Now(),
+	}
+	update := bson.M{"$set": set}
+	if until != nil {
+		set["suspendedUntil"] = *until
+	} else {
+		update["$unset"] = bson.M{"suspendedUntil": ""}
+	}
+	return r.updateUser(ctx, userID, update)
+}
+
+// ShadowBanUser hides a user's content from everyone but themselves
+func (r *Repository) ShadowBanUser(ctx context.Context, userID primitive.ObjectID) error {
+	return r.updateUser(ctx, userID, bson.M{
+		"$set":   bson.M{"moderationStatus": auth.ModerationShadowBanned, "updatedAt": time.Now()},
+		"$unset": bson.M{"suspendedUntil": ""},
+	})
+}
+
+// ReinstateUser lifts any suspension or shadow-ban
+func (r *Repository) ReinstateUser(ctx context.Context, userID primitive.ObjectID) error {
+	return r.updateUser(ctx, userID, bson.M{
+		"$set":   bson.M{"updatedAt": time.Now()},
+		"$unset": bson.M{"moderationStatus": "", "suspendedUntil": ""},
+	})
+}
+
+func (r *Repository) updateUser(ctx context.Context, userID primitive.ObjectID, update bson.M) error {
+	result, err := r.usersCollection.UpdateOne(ctx, bson.M{"_id": userID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// IncrementWarningCount records a moderator warning against a user
+func (r *Repository) IncrementWarningCount(ctx context.Context, userID primitive.ObjectID) error {
+	update := bson.M{
+		"$inc": bson.M{"warningCount": 1},
+		"$set": bson.M{"updatedAt": time.Now()},
+	}
+	_, err := r.usersCollection.UpdateOne(ctx, bson.M{"_id": userID}, update)
+	return err
+}
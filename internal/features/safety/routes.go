@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
 	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/features/notifications"
 	"github.com/xyz-asif/gotodo/internal/middleware"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -11,7 +12,7 @@ import (
 func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config) {
 	repo := NewRepository(db)
 	authRepo := auth.NewRepository(db)
-	handler := NewHandler(repo, authRepo, cfg)
+	handler := NewHandler(repo, authRepo, notifications.GetService(db), cfg)
 	authMiddleware := middleware.NewAuthMiddleware(authRepo, cfg)
 
 	// Reports
@@ -25,4 +26,17 @@ func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Con
 		users.DELETE("/:id/block", handler.UnblockUser)
 		users.GET("/me/blocks", handler.GetBlockedUsers)
 	}
+
+	// Moderation queue - admin only
+	admin := router.Group("/admin")
+	admin.Use(authMiddleware, middleware.RequireAdmin())
+	{
+		admin.GET("/reports", handler.ListReports)
+		admin.GET("/reports/:id", handler.GetReport)
+		admin.POST("/reports/:id/claim", handler.ClaimReport)
+		admin.POST("/reports/:id/resolve", handler.ResolveReport)
+		admin.POST("/reports/:id/dismiss", handler.DismissReport)
+		admin.PATCH("/users/:id/moderation", handler.UpdateUserModeration)
+		admin.POST("/anchors/:id/unhide", handler.UnhideAnchor)
+	}
 }
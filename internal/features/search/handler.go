@@ -2,6 +2,7 @@ package search
 
 import (
 	"context"
+	"log"
 	"math"
 
 	"github.com/gin-gonic/gin"
@@ -59,13 +60,15 @@ func (h *Handler) UnifiedSearch(c *gin.Context) {
 		}
 	}
 
+	hiddenUserIDs := h.getHiddenUserIDs(c.Request.Context(), currentUserID)
+
 	resp := UnifiedSearchResponse{
 		Query: query.Q,
 	}
 
 	// Search anchors
 	if query.Type == TypeAll || query.Type == TypeAnchors {
-		anchors, total, err := h.repo.SearchAnchors(c.Request.Context(), query.Q, nil, SortRelevant, 1, query.Limit)
+		anchors, total, err := h.repo.SearchAnchors(c.Request.Context(), query.Q, nil, SortRelevant, 1, query.Limit, hiddenUserIDs)
 		if err == nil {
 			anchorResults := h.enrichAnchorResults(c.Request.Context(), anchors)
 			resp.Anchors = &UnifiedSearchAnchorsResult{
@@ -84,7 +87,7 @@ func (h *Handler) UnifiedSearch(c *gin.Context) {
 
 	// Search users
 	if query.Type == TypeAll || query.Type == TypeUsers {
-		users, total, err := h.repo.SearchUsers(c.Request.Context(), query.Q, 1, query.Limit)
+		users, total, err := h.repo.SearchUsers(c.Request.Context(), query.Q, 1, query.Limit, hiddenUserIDs)
 		if err == nil {
 			userResults := h.enrichUserResults(c.Request.Context(), users, currentUserID)
 			resp.Users = &UnifiedSearchUsersResult{
@@ -136,7 +139,8 @@ func (h *Handler) SearchAnchors(c *gin.Context) {
 	}
 
 	// Search
-	anchors, total, err := h.repo.SearchAnchors(c.Request.Context(), query.Q, tagFilter, query.Sort, query.Page, query.Limit)
+	hiddenUserIDs := h.getHiddenUserIDs(c.Request.Context(), currentUserIDFromContext(c))
+	anchors, total, err := h.repo.SearchAnchors(c.Request.Context(), query.Q, tagFilter, query.Sort, query.Page, query.Limit, hiddenUserIDs)
 	if err != nil {
 		response.InternalServerError(c, "SEARCH_FAILED", "Failed to search anchors")
 		return
@@ -196,7 +200,8 @@ func (h *Handler) SearchUsers(c *gin.Context) {
 	}
 
 	// Search
-	users, total, err := h.repo.SearchUsers(c.Request.Context(), query.Q, query.Page, query.Limit)
+	hiddenUserIDs := h.getHiddenUserIDs(c.Request.Context(), currentUserID)
+	users, total, err := h.repo.SearchUsers(c.Request.Context(), query.Q, query.Page, query.Limit, hiddenUserIDs)
 	if err != nil {
 		response.InternalServerError(c, "SEARCH_FAILED", "Failed to search users")
 		return
@@ -245,7 +250,8 @@ func (h *Handler) SearchTags(c *gin.Context) {
 	}
 
 	// Search tags
-	tags, err := h.repo.SearchTags(c.Request.Context(), query.Q, query.Limit)
+	hiddenUserIDs := h.getHiddenUserIDs(c.Request.Context(), currentUserIDFromContext(c))
+	tags, err := h.repo.SearchTags(c.Request.Context(), query.Q, query.Limit, hiddenUserIDs)
 	if err != nil {
 		response.InternalServerError(c, "SEARCH_FAILED", "Failed to search tags")
 		return
@@ -259,6 +265,26 @@ func (h *Handler) SearchTags(c *gin.Context) {
 
 // Helper methods
 
+// getHiddenUserIDs returns shadow-banned and deleted users whose content the viewer must not see
+func (h *Handler) getHiddenUserIDs(ctx context.Context, viewerID *primitive.ObjectID) []primitive.ObjectID {
+	ids, err := h.authRepo.GetHiddenUserIDs(ctx, viewerID)
+	if err != nil {
+		log.Printf("Failed to load hidden users: %v", err)
+		return nil
+	}
+	return ids
+}
+
+// currentUserIDFromContext returns the authenticated user's ID, or nil for anonymous requests
+func currentUserIDFromContext(c *gin.Context) *primitive.ObjectID {
+	if usr, exists := c.Get("user"); exists {
+		if user, ok := usr.(*auth.User); ok {
+			return &user.ID
+		}
+	}
+	return nil
+}
+
 func (h *Handler) enrichAnchorResults(ctx context.Context, anchors []AnchorSearchDoc) []SearchAnchorResult {
 	if len(anchors) == 0 {
 		return []SearchAnchorResult{}
@@ -396,12 +422,14 @@ func (h *Handler) CombinedSearch(c *gin.Context) {
 		}
 	}
 
+	hiddenUserIDs := h.getHiddenUserIDs(c.Request.Context(), currentUserID)
+
 	resp := UnifiedSearchResponse{
 		Query: q,
 	}
 
 	// 1. Anchors
-	anchors, totalAnchors, err := h.repo.SearchAnchors(c.Request.Context(), q, nil, SortRelevant, 1, limit)
+	anchors, totalAnchors, err := h.repo.SearchAnchors(c.Request.Context(), q, nil, SortRelevant, 1, limit, hiddenUserIDs)
 	if err == nil {
 		resp.Anchors = &UnifiedSearchAnchorsResult{
 			Items:   h.enrichAnchorResults(c.Request.Context(), anchors),
@@ -413,7 +441,7 @@ func (h *Handler) CombinedSearch(c *gin.Context) {
 	}
 
 	// 2. Users
-	users, totalUsers, err := h.repo.SearchUsers(c.Request.Context(), q, 1, limit)
+	users, totalUsers, err := h.repo.SearchUsers(c.Request.Context(), q, 1, limit, hiddenUserIDs)
 	if err == nil {
 		resp.Users = &UnifiedSearchUsersResult{
 			Items:   h.enrichUserResults(c.Request.Context(), users, currentUserID),
@@ -425,7 +453,7 @@ func (h *Handler) CombinedSearch(c *gin.Context) {
 	}
 
 	// 3. Tags
-	tags, err := h.repo.SearchTags(c.Request.Context(), q, limit)
+	tags, err := h.repo.SearchTags(c.Request.Context(), q, limit, hiddenUserIDs)
 	if err == nil {
 		resp.Tags = tags
 	} else {
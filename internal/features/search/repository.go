@@ -22,14 +22,17 @@ func NewRepository(db *mongo.Database) *Repository {
 	}
 }
 
-// SearchAnchors performs text search on anchors
-func (r *Repository) SearchAnchors(ctx context.Context, query string, tag *string, sort string, page, limit int) ([]AnchorSearchDoc, int64, error) {
+// SearchAnchors performs text search on anchors, skipping anchors by excluded users
+func (r *Repository) SearchAnchors(ctx context.Context, query string, tag *string, sort string, page, limit int, excludeUserIDs []primitive.ObjectID) ([]AnchorSearchDoc, int64, error) {
 	// Build filter
 	filter := bson.M{
 		"$text":      bson.M{"$search": query},
 		"visibility": "public",
 		"deletedAt":  nil,
 	}
+	if len(excludeUserIDs) > 0 {
+		filter["userId"] = bson.M{"$nin": excludeUserIDs}
+	}
 
 	// Add tag filter if provided
 	if tag != nil && *tag != "" {
@@ -78,11 +81,14 @@ func (r *Repository) SearchAnchors(ctx context.Context, query string, tag *strin
 	return anchors, total, nil
 }
 
-// SearchUsers performs text search on users
-func (r *Repository) SearchUsers(ctx context.Context, query string, page, limit int) ([]UserSearchDoc, int64, error) {
+// SearchUsers performs text search on users, skipping excluded users
+func (r *Repository) SearchUsers(ctx context.Context, query string, page, limit int, excludeUserIDs []primitive.ObjectID) ([]UserSearchDoc, int64, error) {
 	filter := bson.M{
 		"$text": bson.M{"$search": query},
 	}
+	if len(excludeUserIDs) > 0 {
+		filter["_id"] = bson.M{"$nin": excludeUserIDs}
+	}
 
 	opts := options.Find().
 		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}).
@@ -109,24 +115,29 @@ func (r *Repository) SearchUsers(ctx context.Context, query string, page, limit
 	return users, total, nil
 }
 
-// SearchTags returns tags matching prefix with usage counts
-func (r *Repository) SearchTags(ctx context.Context, prefix string, limit int) ([]TagResult, error) {
+// SearchTags returns tags matching prefix with usage counts, ignoring anchors by excluded users
+func (r *Repository) SearchTags(ctx context.Context, prefix string, limit int, excludeUserIDs []primitive.ObjectID) ([]TagResult, error) {
 	prefixLower := strings.ToLower(prefix)
 
-	pipeline := mongo.Pipeline{
-		// Match public, non-deleted anchors with matching tag prefix
-		{{Key: "$match", Value: bson.M{
-			"visibility": "public",
-			"deletedAt":  nil,
-			"tags": bson.M{
-				"$elemMatch": bson.M{
-					"$regex": primitive.Regex{
-						Pattern: "^" + prefixLower,
-						Options: "i",
-					},
+	match := bson.M{
+		"visibility": "public",
+		"deletedAt":  nil,
+		"tags": bson.M{
+			"$elemMatch": bson.M{
+				"$regex": primitive.Regex{
+					Pattern: "^" + prefixLower,
+					Options: "i",
 				},
 			},
-		}}},
+		},
+	}
+	if len(excludeUserIDs) > 0 {
+		match["userId"] = bson.M{"$nin": excludeUserIDs}
+	}
+
+	pipeline := mongo.Pipeline{
+		// Match public, non-deleted anchors with matching tag prefix
+		{{Key: "$match", Value: match}},
 		// Unwind tags array
 		{{Key: "$unwind", Value: "$tags"}},
 		// Convert tag to lowercase for grouping
@@ -0,0 +1,102 @@
+package uploads
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/pkg/response"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+)
+
+type Handler struct {
+	repo     *Repository
+	uploader storage.DirectUploader // nil when the driver cannot sign uploads
+	tracker  storage.Tracker
+	ttl      time.Duration
+}
+
+func NewHandler(repo *Repository, store storage.Storage, tracker storage.Tracker, ttl time.Duration) *Handler {
+	h := &Handler{repo: repo, tracker: tracker, ttl: ttl}
+	if uploader, ok := store.(storage.DirectUploader); ok {
+		h.uploader = uploader
+	}
+	return h
+}
+
+// CreateUploadIntent godoc
+// @Summary Start a direct upload
+// @Description Get signed parameters for uploading a file straight to media storage instead of through the API. Send the file as described by upload before expiresAt, then pass intentId to POST /anchors/{id}/items/finalize. Intents that are not finalized in time are deleted along with their upload.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateIntentRequest true "File to upload"
+// @Success 201 {object} response.APIResponse{data=IntentResponse}
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 422 {object} response.APIResponse
+// @Failure 503 {object} response.APIResponse
+// @Router /media/upload-intents [post]
+func (h *Handler) CreateUploadIntent(c *gin.Context) {
+	usr, exists := c.Get("user")
+	if !exists {
+		response.Unauthorized(c, "Authentication required", "UNAUTHORIZED")
+		return
+	}
+	currentUser := usr.(*auth.User)
+
+	if h.uploader == nil {
+		response.ServiceUnavailable(c, "Direct uploads are not available", "UPLOAD_UNAVAILABLE")
+		return
+	}
+
+	var req CreateIntentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format", "INVALID_JSON")
+		return
+	}
+
+	kind := storage.Kind(req.Kind)
+	if err := storage.Validate(kind, req.Filename, req.Size); err != nil {
+		var invalid *storage.ValidationError
+		if errors.As(err, &invalid) {
+			response.ValidationError(c, invalid.Message, invalid.Code)
+			return
+		}
+		response.BadRequest(c, err.Error(), "INVALID_FILE")
+		return
+	}
+
+	expiresAt := time.Now().Add(h.ttl)
+	ticket, err := h.uploader.SignUpload(c.Request.Context(), kind, req.Filename, expiresAt)
+	if err != nil {
+		log.Printf("Failed to sign upload: %v", err)
+		response.InternalServerError(c, "Failed to start upload", "UPLOAD_FAILED")
+		return
+	}
+
+	intent := &Intent{
+		UserID:    currentUser.ID,
+		Kind:      kind,
+		PublicID:  ticket.PublicID,
+		Filename:  req.Filename,
+		Size:      req.Size,
+		ExpiresAt: expiresAt,
+	}
+	if err := h.repo.Create(c.Request.Context(), intent); err != nil {
+		response.InternalServerError(c, "Failed to start upload", "DATABASE_ERROR")
+		return
+	}
+	// Tracked before anything is uploaded, so an upload is never left untracked
+	h.tracker.Track(c.Request.Context(), kind, intent.PublicID)
+
+	response.Created(c, IntentResponse{
+		IntentID:  intent.ID,
+		PublicID:  intent.PublicID,
+		Upload:    ticket,
+		ExpiresAt: intent.ExpiresAt,
+	})
+}
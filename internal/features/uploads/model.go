@@ -0,0 +1,49 @@
+package uploads
+
+import (
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Intent status constants
+const (
+	StatusPending    = "pending"
+	StatusFinalizing = "finalizing" // claimed by a finalize request
+	StatusExpired    = "expired"    // claimed by the sweeper
+)
+
+// Intent records a signed upload that the client sends straight to media
+// storage. It is deleted once the upload is finalized into an item; intents
+// that are never finalized are swept after they expire, along with their asset.
+type Intent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"userId" json:"-"`
+	Kind      storage.Kind       `bson:"kind" json:"kind"`
+	PublicID  string             `bson:"publicId" json:"publicId"`
+	Filename  string             `bson:"filename" json:"filename"`
+	Size      int64              `bson:"size" json:"size"` // as declared by the client
+	Status    string             `bson:"status" json:"-"`
+	ExpiresAt time.Time          `bson:"expiresAt" json:"expiresAt"`
+	LeaseEnd  *time.Time         `bson:"leaseEnd,omitempty" json:"-"` // while finalizing; swept after it passes
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// CreateIntentRequest represents the payload for starting a direct upload
+type CreateIntentRequest struct {
+	Kind     string `json:"kind" binding:"required,oneof=image audio file"`
+	Filename string `json:"filename" binding:"required,max=255"`
+	Size     int64  `json:"size" binding:"required,min=1"`
+}
+
+// IntentResponse tells the client where to send the file. Upload the file
+// with Upload.Method to Upload.URL (as a multipart "file" field alongside
+// Upload.Fields for POST, or as the raw body for PUT) before ExpiresAt, then
+// finalize the intent.
+type IntentResponse struct {
+	IntentID  primitive.ObjectID    `json:"intentId"`
+	PublicID  string                `json:"publicId"`
+	Upload    *storage.UploadTicket `json:"upload"`
+	ExpiresAt time.Time             `json:"expiresAt"`
+}
@@ -0,0 +1,155 @@
+package uploads
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrIntentNotFound = errors.New("upload intent not found")
+	ErrIntentClaimed  = errors.New("upload intent is expired or already being finalized")
+)
+
+type Repository struct {
+	collection *mongo.Collection
+}
+
+func NewRepository(db *mongo.Database) *Repository {
+	collection := db.Collection("upload_intents")
+
+	_, _ = collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "userId", Value: 1}},
+		},
+		{
+			// Orphan sweep
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "expiresAt", Value: 1},
+			},
+		},
+		{
+			// Sweep of interrupted finalizes
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "leaseEnd", Value: 1},
+			},
+		},
+	})
+
+	return &Repository{collection: collection}
+}
+
+// Create stores a new pending intent
+func (r *Repository) Create(ctx context.Context, intent *Intent) error {
+	intent.Status = StatusPending
+	intent.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, intent)
+	if err != nil {
+		return err
+	}
+	intent.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// GetForUser returns one of the user's intents
+func (r *Repository) GetForUser(ctx context.Context, id, userID primitive.ObjectID) (*Intent, error) {
+	var intent Intent
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "userId": userID}).Decode(&intent)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrIntentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
+
+// Claim moves a pending, unexpired intent to finalizing so that it can only be
+// finalized once and the sweeper leaves its asset alone. The claim is a lease:
+// if the finalize neither deletes nor releases the intent before it ends, say
+// because the instance crashed, the sweeper takes the intent over.
+func (r *Repository) Claim(ctx context.Context, id, userID primitive.ObjectID, now time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{
+			"_id":       id,
+			"userId":    userID,
+			"status":    StatusPending,
+			"expiresAt": bson.M{"$gt": now},
+		},
+		bson.M{"$set": bson.M{
+			"status":   StatusFinalizing,
+			"leaseEnd": now.Add(finalizeLease),
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return ErrIntentClaimed
+	}
+	return nil
+}
+
+// Release returns a claimed intent to pending after a failed finalize, so it
+// can be retried or swept
+func (r *Repository) Release(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": StatusFinalizing},
+		bson.M{
+			"$set":   bson.M{"status": StatusPending},
+			"$unset": bson.M{"leaseEnd": ""},
+		},
+	)
+	return err
+}
+
+// Delete removes an intent
+func (r *Repository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// ClaimExpired claims one intent that expired, or whose finalize lease ended,
+// before now for the sweeper, or returns nil when there are none. Its expiry is
+// pushed back by lease, so an intent whose sweep failed or was interrupted is
+// picked up again later.
+func (r *Repository) ClaimExpired(ctx context.Context, now time.Time, lease time.Duration) (*Intent, error) {
+	var intent Intent
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"$or": []bson.M{
+			{
+				"status":    bson.M{"$in": []string{StatusPending, StatusExpired}},
+				"expiresAt": bson.M{"$lte": now},
+			},
+			{
+				"status":   StatusFinalizing,
+				"leaseEnd": bson.M{"$lte": now},
+			},
+		}},
+		bson.M{
+			"$set": bson.M{
+				"status":    StatusExpired,
+				"expiresAt": now.Add(lease),
+			},
+			"$unset": bson.M{"leaseEnd": ""},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "expiresAt", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&intent)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &intent, nil
+}
@@ -0,0 +1,34 @@
+package uploads
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/config"
+	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/middleware"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RegisterRoutes starts the orphan sweeper and registers the upload intent route.
+// Intents are finalized into items by the anchors package.
+func RegisterRoutes(router *gin.RouterGroup, db *mongo.Database, cfg *config.Config, tracker storage.Tracker) {
+	repo := NewRepository(db)
+	authRepo := auth.NewRepository(db)
+
+	// Direct uploads become anchor items, so they share the anchors folder
+	store, err := storage.New(cfg, "anchors")
+	if err != nil {
+		log.Printf("Failed to initialize media storage: %v", err)
+	} else {
+		NewSweeper(repo, tracker).Start(context.Background())
+	}
+
+	handler := NewHandler(repo, store, tracker, time.Duration(cfg.UploadIntentTTLMinutes)*time.Minute)
+	authMiddleware := middleware.NewAuthMiddleware(authRepo, cfg)
+
+	router.POST("/media/upload-intents", authMiddleware, handler.CreateUploadIntent)
+}
@@ -0,0 +1,78 @@
+package uploads
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
+)
+
+// Sweeper settings
+const (
+	sweepInterval  = 10 * time.Minute
+	sweepBatchSize = 100
+	sweepLease     = 10 * time.Minute // before a failed sweep is retried
+	finalizeLease  = 10 * time.Minute // before an interrupted finalize is swept
+)
+
+// Sweeper deletes intents that expired without being finalized, along with
+// anything uploaded for them
+type Sweeper struct {
+	repo    *Repository
+	tracker storage.Tracker
+}
+
+func NewSweeper(repo *Repository, tracker storage.Tracker) *Sweeper {
+	return &Sweeper{
+		repo:    repo,
+		tracker: tracker,
+	}
+}
+
+// Start sweeps until ctx is cancelled
+func (s *Sweeper) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			if swept, err := s.Sweep(ctx, time.Now()); err != nil {
+				log.Printf("Upload intent sweeper error: %v", err)
+			} else if swept > 0 {
+				log.Printf("Upload intent sweeper removed %d orphaned uploads", swept)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Sweep removes up to one batch of intents that expired before now and returns
+// how many were removed. Intents are claimed one at a time, so several
+// instances can sweep at once. Their assets are released to the media
+// reconciler, which deletes them.
+func (s *Sweeper) Sweep(ctx context.Context, now time.Time) (int, error) {
+	swept := 0
+	for i := 0; i < sweepBatchSize; i++ {
+		intent, err := s.repo.ClaimExpired(ctx, now, sweepLease)
+		if err != nil {
+			return swept, err
+		}
+		if intent == nil {
+			return swept, nil
+		}
+
+		if err := s.repo.Delete(ctx, intent.ID); err != nil {
+			return swept, err
+		}
+		// Nothing may have been uploaded; releasing a missing asset is fine
+		s.tracker.Release(ctx, intent.Kind, intent.PublicID)
+		swept++
+	}
+	return swept, nil
+}
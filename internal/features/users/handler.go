@@ -58,7 +58,7 @@ func (h *Handler) GetUserByUsername(c *gin.Context) {
 
 	// Get user by username
 	user, err := h.authRepo.GetUserByUsername(ctx, username)
-	if err != nil || user == nil {
+	if err != nil || user == nil || user.IsDeleted() {
 		response.NotFound(c, "User not found", "USER_NOT_FOUND")
 		return
 	}
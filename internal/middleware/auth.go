@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"log"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
@@ -44,6 +46,16 @@ func NewAuthMiddleware(repo *auth.Repository, cfg *config.Config) gin.HandlerFun
 			return
 		}
 
+		// Suspended accounts lose every session so they cannot keep refreshing tokens
+		if user.IsSuspended(time.Now()) {
+			if err := repo.RevokeAllUserTokens(c.Request.Context(), user.ID); err != nil {
+				log.Printf("Failed to revoke sessions for suspended user %s: %v", user.ID.Hex(), err)
+			}
+			response.Forbidden(c, "Account suspended", "ACCOUNT_SUSPENDED")
+			c.Abort()
+			return
+		}
+
 		c.Set("user", user)
 		c.Next()
 	}
@@ -81,7 +93,35 @@ func OptionalAuthMiddleware(repo *auth.Repository, cfg *config.Config) gin.Handl
 			return
 		}
 
+		// Suspended users are treated as anonymous
+		if user.IsSuspended(time.Now()) {
+			c.Next()
+			return
+		}
+
 		c.Set("user", user)
 		c.Next()
 	}
 }
+
+// RequireAdmin rejects requests from users without the admin role.
+// It must run after NewAuthMiddleware.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		val, exists := c.Get("user")
+		if !exists {
+			response.Unauthorized(c, "Authentication required", "AUTH_REQUIRED")
+			c.Abort()
+			return
+		}
+
+		user, ok := val.(*auth.User)
+		if !ok || !user.IsAdmin() {
+			response.Forbidden(c, "Admin access required", "ADMIN_REQUIRED")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -2,22 +2,42 @@ package cloudinary
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"mime/multipart"
-	"path/filepath"
+	"io"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
+// ErrAssetNotFound is returned by Asset when no asset has the public ID
+var ErrAssetNotFound = errors.New("cloudinary: asset not found")
+
 // Service handles Cloudinary upload operations
 type Service struct {
 	cld          *cloudinary.Cloudinary
+	cloudName    string
+	apiKey       string
+	apiSecret    string
 	uploadFolder string
 }
 
+// SignedUpload holds what a client needs to upload one file directly to Cloudinary
+type SignedUpload struct {
+	URL      string
+	PublicID string
+	Params   map[string]string // form fields to send alongside the file
+}
+
 // UploadResult contains the result of a successful upload
 type UploadResult struct {
 	URL      string
@@ -29,17 +49,6 @@ type UploadResult struct {
 	Format   string
 }
 
-// File validation constants
-var (
-	AllowedImageTypes = []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
-	AllowedAudioTypes = []string{".mp3", ".wav", ".aac", ".m4a", ".ogg"}
-	AllowedFileTypes  = []string{".pdf", ".docx", ".doc", ".epub", ".txt"}
-
-	MaxImageSize = int64(10 * 1024 * 1024) // 10MB
-	MaxAudioSize = int64(25 * 1024 * 1024) // 25MB
-	MaxFileSize  = int64(50 * 1024 * 1024) // 50MB
-)
-
 // NewService creates a new Cloudinary service instance
 func NewService(cloudName, apiKey, apiSecret, uploadFolder string) (*Service, error) {
 	if cloudName == "" || apiKey == "" || apiSecret == "" {
@@ -61,12 +70,15 @@ func NewService(cloudName, apiKey, apiSecret, uploadFolder string) (*Service, er
 
 	return &Service{
 		cld:          cld,
+		cloudName:    cloudName,
+		apiKey:       apiKey,
+		apiSecret:    apiSecret,
 		uploadFolder: uploadFolder,
 	}, nil
 }
 
 // UploadImage uploads an image file to Cloudinary
-func (s *Service) UploadImage(ctx context.Context, file multipart.File, filename string) (*UploadResult, error) {
+func (s *Service) UploadImage(ctx context.Context, file io.Reader, filename string) (*UploadResult, error) {
 	folder := s.uploadFolder + "/images"
 
 	uploadParams := uploader.UploadParams{
@@ -90,7 +102,7 @@ func (s *Service) UploadImage(ctx context.Context, file multipart.File, filename
 }
 
 // UploadAudio uploads an audio file to Cloudinary
-func (s *Service) UploadAudio(ctx context.Context, file multipart.File, filename string) (*UploadResult, error) {
+func (s *Service) UploadAudio(ctx context.Context, file io.Reader, filename string) (*UploadResult, error) {
 	folder := s.uploadFolder + "/audio"
 
 	uploadParams := uploader.UploadParams{
@@ -119,7 +131,7 @@ func (s *Service) UploadAudio(ctx context.Context, file multipart.File, filename
 }
 
 // UploadFile uploads a generic file to Cloudinary
-func (s *Service) UploadFile(ctx context.Context, file multipart.File, filename string) (*UploadResult, error) {
+func (s *Service) UploadFile(ctx context.Context, file io.Reader, filename string) (*UploadResult, error) {
 	folder := s.uploadFolder + "/files"
 
 	uploadParams := uploader.UploadParams{
@@ -140,6 +152,45 @@ func (s *Service) UploadFile(ctx context.Context, file multipart.File, filename
 	}, nil
 }
 
+// CopyAsset duplicates an existing asset by uploading it again from its delivery URL.
+// The copy gets its own public ID so it is unaffected when the source asset is deleted.
+func (s *Service) CopyAsset(ctx context.Context, sourceURL string, resourceType string) (*UploadResult, error) {
+	if sourceURL == "" {
+		return nil, errors.New("sourceURL is required")
+	}
+
+	var folder string
+	switch resourceType {
+	case "video":
+		folder = s.uploadFolder + "/audio"
+	case "raw":
+		folder = s.uploadFolder + "/files"
+	default:
+		resourceType = "image"
+		folder = s.uploadFolder + "/images"
+	}
+
+	uploadParams := uploader.UploadParams{
+		Folder:       folder,
+		ResourceType: resourceType,
+	}
+
+	// Cloudinary fetches remote URLs server-side, so no bytes pass through us
+	result, err := s.cld.Upload.Upload(ctx, sourceURL, uploadParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy asset: %w", err)
+	}
+
+	return &UploadResult{
+		URL:      result.SecureURL,
+		PublicID: result.PublicID,
+		Width:    result.Width,
+		Height:   result.Height,
+		FileSize: int64(result.Bytes),
+		Format:   result.Format,
+	}, nil
+}
+
 // Delete removes an asset from Cloudinary
 func (s *Service) Delete(ctx context.Context, publicID string, resourceType string) error {
 	if publicID == "" {
@@ -163,66 +214,89 @@ func (s *Service) Delete(ctx context.Context, publicID string, resourceType stri
 	return nil
 }
 
-// ValidateImageFile validates an image file upload
-func ValidateImageFile(header *multipart.FileHeader) error {
-	// Check file size
-	if header.Size > MaxImageSize {
-		return fmt.Errorf("image file size exceeds maximum allowed size of %d MB", MaxImageSize/(1024*1024))
+// SignUpload signs an upload of one file to a new public ID in the folder for
+// resourceType. ext is kept on raw public IDs, since Cloudinary does not
+// detect their format. Cloudinary accepts the signature for an hour.
+func (s *Service) SignUpload(resourceType, ext string) (*SignedUpload, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
 	}
 
-	// Check file extension
-	ext := getFileExtension(header.Filename)
-	if !isAllowedExtension(ext, AllowedImageTypes) {
-		return fmt.Errorf("invalid image file type: %s. Allowed types: %s", ext, strings.Join(AllowedImageTypes, ", "))
+	var publicID string
+	switch resourceType {
+	case "video":
+		publicID = s.uploadFolder + "/audio/" + hex.EncodeToString(b)
+	case "raw":
+		publicID = s.uploadFolder + "/files/" + hex.EncodeToString(b) + strings.ToLower(ext)
+	default:
+		resourceType = "image"
+		publicID = s.uploadFolder + "/images/" + hex.EncodeToString(b)
 	}
 
-	return nil
-}
-
-// ValidateAudioFile validates an audio file upload
-func ValidateAudioFile(header *multipart.FileHeader) error {
-	// Check file size
-	if header.Size > MaxAudioSize {
-		return fmt.Errorf("audio file size exceeds maximum allowed size of %d MB", MaxAudioSize/(1024*1024))
+	params := map[string]string{
+		"public_id": publicID,
+		"timestamp": strconv.FormatInt(time.Now().Unix(), 10),
+	}
+	if resourceType == "image" {
+		// An incoming transformation is applied before the image is stored, and
+		// transformed images are stored without their EXIF, XMP or IPTC metadata.
+		// a_exif rotates by the EXIF orientation first, so nothing visible changes.
+		params["transformation"] = "a_exif"
 	}
 
-	// Check file extension
-	ext := getFileExtension(header.Filename)
-	if !isAllowedExtension(ext, AllowedAudioTypes) {
-		return fmt.Errorf("invalid audio file type: %s. Allowed types: %s", ext, strings.Join(AllowedAudioTypes, ", "))
+	// Signed parameters are sorted by name and joined, then hashed with the secret
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + params[name]
+	}
+	digest := sha1.Sum([]byte(strings.Join(pairs, "&") + s.apiSecret))
 
-	return nil
+	params["api_key"] = s.apiKey
+	params["signature"] = hex.EncodeToString(digest[:])
+
+	return &SignedUpload{
+		URL:      fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/%s/upload", s.cloudName, resourceType),
+		PublicID: publicID,
+		Params:   params,
+	}, nil
 }
 
-// ValidateFile validates a generic file upload
-func ValidateFile(header *multipart.FileHeader) error {
-	// Check file size
-	if header.Size > MaxFileSize {
-		return fmt.Errorf("file size exceeds maximum allowed size of %d MB", MaxFileSize/(1024*1024))
+// Asset looks up an uploaded asset through the Admin API
+func (s *Service) Asset(ctx context.Context, publicID string, resourceType string) (*UploadResult, error) {
+	if publicID == "" {
+		return nil, errors.New("publicID is required")
 	}
 
-	// Check file extension
-	ext := getFileExtension(header.Filename)
-	if !isAllowedExtension(ext, AllowedFileTypes) {
-		return fmt.Errorf("invalid file type: %s. Allowed types: %s", ext, strings.Join(AllowedFileTypes, ", "))
+	if resourceType == "" {
+		resourceType = "image"
 	}
 
-	return nil
-}
-
-// getFileExtension returns the lowercase file extension including the dot
-func getFileExtension(filename string) string {
-	ext := filepath.Ext(filename)
-	return strings.ToLower(ext)
-}
-
-// isAllowedExtension checks if the extension is in the allowed list
-func isAllowedExtension(ext string, allowedTypes []string) bool {
-	for _, allowed := range allowedTypes {
-		if ext == allowed {
-			return true
+	result, err := s.cld.Admin.Asset(ctx, admin.AssetParams{
+		PublicID:  publicID,
+		AssetType: api.AssetType(resourceType),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch asset: %w", err)
+	}
+	if result.Error.Message != "" {
+		if strings.Contains(strings.ToLower(result.Error.Message), "not found") {
+			return nil, ErrAssetNotFound
 		}
+		return nil, fmt.Errorf("failed to fetch asset: %s", result.Error.Message)
 	}
-	return false
+
+	return &UploadResult{
+		URL:      result.SecureURL,
+		PublicID: result.PublicID,
+		Width:    result.Width,
+		Height:   result.Height,
+		FileSize: int64(result.Bytes),
+		Format:   result.Format,
+	}, nil
 }
@@ -0,0 +1,81 @@
+package push
+
+import (
+	"context"
+	"fmt"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/messaging"
+)
+
+// FCMSender delivers through Firebase Cloud Messaging
+type FCMSender struct {
+	client *messaging.Client
+}
+
+// NewFCMSender creates a sender from an initialized Firebase app
+func NewFCMSender(ctx context.Context, app *firebase.App) (*FCMSender, error) {
+	client, err := app.Messaging(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting firebase messaging client: %v", err)
+	}
+	return &FCMSender{client: client}, nil
+}
+
+// Send delivers msg to every token, splitting into batches of MaxTokensPerMessage
+func (s *FCMSender) Send(ctx context.Context, msg Message) (*Result, error) {
+	result := &Result{}
+
+	for start := 0; start < len(msg.Tokens); start += MaxTokensPerMessage {
+		end := start + MaxTokensPerMessage
+		if end > len(msg.Tokens) {
+			end = len(msg.Tokens)
+		}
+		tokens := msg.Tokens[start:end]
+
+		batch, err := s.client.SendEachForMulticast(ctx, &messaging.MulticastMessage{
+			Tokens: tokens,
+			Data:   msg.Data,
+			Notification: &messaging.Notification{
+				Title: msg.Title,
+				Body:  msg.Body,
+			},
+		})
+		if err != nil {
+			if start == 0 {
+				return nil, err
+			}
+			// Earlier batches went out; only this and later ones need retrying
+			result.Failed = append(result.Failed, msg.Tokens[start:]...)
+			return result, nil
+		}
+
+		// An invalid argument can be a malformed token or the message itself.
+		// Only a rejection of some of the batch's tokens blames the tokens, so
+		// a bad message doesn't cost every recipient their devices.
+		badMessage := batch.FailureCount == len(tokens)
+		for _, resp := range batch.Responses {
+			if !messaging.IsInvalidArgument(resp.Error) {
+				badMessage = false
+				break
+			}
+		}
+
+		for i, resp := range batch.Responses {
+			if resp.Success {
+				continue
+			}
+			switch {
+			case messaging.IsUnregistered(resp.Error),
+				messaging.IsSenderIDMismatch(resp.Error):
+				result.Invalid = append(result.Invalid, tokens[i])
+			case messaging.IsInvalidArgument(resp.Error) && !badMessage:
+				result.Invalid = append(result.Invalid, tokens[i])
+			default:
+				result.Failed = append(result.Failed, tokens[i])
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,28 @@
+package push
+
+import "context"
+
+// MaxTokensPerMessage is the most device tokens a single Send may target
+const MaxTokensPerMessage = 500
+
+// Message is a push notification addressed to one or more device tokens
+type Message struct {
+	Tokens []string
+	Title  string
+	Body   string
+	Data   map[string]string // delivered to the app alongside the alert
+}
+
+// Result reports per-token outcomes of a Send. Tokens in neither list were delivered.
+type Result struct {
+	// Invalid tokens are unregistered or malformed and should be forgotten
+	Invalid []string
+	// Failed tokens hit a transient error and may be retried
+	Failed []string
+}
+
+// Sender delivers push notifications. An error means nothing was sent and the
+// whole message may be retried.
+type Sender interface {
+	Send(ctx context.Context, msg Message) (*Result, error)
+}
@@ -0,0 +1,108 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+)
+
+// ErrStubUnavailable is returned by the stub while it is set to fail
+var ErrStubUnavailable = errors.New("push: stub sender unavailable")
+
+// stubHistory bounds how many sent messages the stub remembers
+const stubHistory = 100
+
+// StubSender logs messages instead of sending them, for local development and
+// tests. Tokens can be marked invalid or failing to exercise pruning and retries.
+type StubSender struct {
+	mu       sync.Mutex
+	sent     []Message
+	invalid  map[string]bool
+	failing  map[string]bool
+	failNext int
+	quiet    bool
+}
+
+// NewStubSender creates a stub that logs every message
+func NewStubSender() *StubSender {
+	return &StubSender{
+		invalid: make(map[string]bool),
+		failing: make(map[string]bool),
+	}
+}
+
+// Quiet stops the stub from logging messages
+func (s *StubSender) Quiet() *StubSender {
+	s.quiet = true
+	return s
+}
+
+// MarkInvalid makes future sends report token as invalid
+func (s *StubSender) MarkInvalid(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalid[token] = true
+}
+
+// MarkFailing makes future sends report token as a transient failure until cleared
+func (s *StubSender) MarkFailing(token string, failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if failing {
+		s.failing[token] = true
+	} else {
+		delete(s.failing, token)
+	}
+}
+
+// FailNext makes the next n sends fail outright
+func (s *StubSender) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+}
+
+// Sent returns the most recent messages delivered to at least one token
+func (s *StubSender) Sent() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.sent...)
+}
+
+// Send records msg for its deliverable tokens
+func (s *StubSender) Send(ctx context.Context, msg Message) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failNext > 0 {
+		s.failNext--
+		return nil, ErrStubUnavailable
+	}
+
+	result := &Result{}
+	delivered := msg
+	delivered.Tokens = nil
+	for _, token := range msg.Tokens {
+		switch {
+		case s.invalid[token]:
+			result.Invalid = append(result.Invalid, token)
+		case s.failing[token]:
+			result.Failed = append(result.Failed, token)
+		default:
+			delivered.Tokens = append(delivered.Tokens, token)
+		}
+	}
+
+	if len(delivered.Tokens) > 0 {
+		s.sent = append(s.sent, delivered)
+		if len(s.sent) > stubHistory {
+			s.sent = s.sent[len(s.sent)-stubHistory:]
+		}
+		if !s.quiet {
+			log.Printf("[push stub] %d device(s): %s - %s", len(delivered.Tokens), msg.Title, msg.Body)
+		}
+	}
+
+	return result, nil
+}
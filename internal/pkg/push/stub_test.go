@@ -0,0 +1,57 @@
+package push
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStubSender_ClassifiesTokens(t *testing.T) {
+	s := NewStubSender().Quiet()
+	s.MarkInvalid("gone")
+	s.MarkFailing("flaky", true)
+
+	res, err := s.Send(context.Background(), Message{
+		Tokens: []string{"ok", "gone", "flaky"},
+		Title:  "Hello",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"gone"}, res.Invalid)
+	require.Equal(t, []string{"flaky"}, res.Failed)
+
+	sent := s.Sent()
+	require.Len(t, sent, 1)
+	require.Equal(t, []string{"ok"}, sent[0].Tokens)
+}
+
+func TestStubSender_FailNext(t *testing.T) {
+	s := NewStubSender().Quiet()
+	s.FailNext(1)
+
+	_, err := s.Send(context.Background(), Message{Tokens: []string{"a"}})
+	require.ErrorIs(t, err, ErrStubUnavailable)
+	require.Empty(t, s.Sent())
+
+	res, err := s.Send(context.Background(), Message{Tokens: []string{"a"}})
+	require.NoError(t, err)
+	require.Empty(t, res.Invalid)
+	require.Empty(t, res.Failed)
+	require.Len(t, s.Sent(), 1)
+}
+
+func TestStubSender_RecoversFailingToken(t *testing.T) {
+	s := NewStubSender().Quiet()
+	s.MarkFailing("flaky", true)
+
+	res, err := s.Send(context.Background(), Message{Tokens: []string{"flaky"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"flaky"}, res.Failed)
+	require.Empty(t, s.Sent())
+
+	s.MarkFailing("flaky", false)
+	res, err = s.Send(context.Background(), Message{Tokens: []string{"flaky"}})
+	require.NoError(t, err)
+	require.Empty(t, res.Failed)
+	require.Len(t, s.Sent(), 1)
+}
@@ -1,7 +1,9 @@
 package ratelimit
 
 import (
-	"net/http"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,35 +14,9 @@ import (
 func Middleware(limiter *RateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Use IP address as the key (or user ID if authenticated)
-		key := c.ClientIP()
-
-		if !limiter.Allow(key) {
-			remaining := limiter.GetRemaining(key)
-			resetTime := limiter.GetResetTime(key)
-
-			c.Header("X-RateLimit-Limit", "100")
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", resetTime.Format(time.RFC3339))
-			c.Header("Retry-After", "60")
-
-			response.Respond(c, http.StatusTooManyRequests, false, "Rate limit exceeded. Try again later.", map[string]interface{}{
-				"retry_after": "60s",
-				"reset_time":  resetTime.Format(time.RFC3339),
-				"limit":       100,
-				"remaining":   remaining,
-			}, "RATE_LIMIT_EXCEEDED")
-			c.Abort()
+		if !enforce(c, limiter, c.ClientIP()) {
 			return
 		}
-
-		// Add rate limit headers
-		remaining := limiter.GetRemaining(key)
-		resetTime := limiter.GetResetTime(key)
-
-		c.Header("X-RateLimit-Limit", "100")
-		c.Header("X-RateLimit-Remaining", string(rune(remaining)))
-		c.Header("X-RateLimit-Reset", resetTime.Format(time.RFC3339))
-
 		c.Next()
 	}
 }
@@ -55,33 +31,9 @@ func UserBasedMiddleware(limiter *RateLimiter) gin.HandlerFunc {
 			userID = c.ClientIP()
 		}
 
-		if !limiter.Allow(userID) {
-			remaining := limiter.GetRemaining(userID)
-			resetTime := limiter.GetResetTime(userID)
-
-			c.Header("X-RateLimit-Limit", "100")
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", resetTime.Format(time.RFC3339))
-			c.Header("Retry-After", "60")
-
-			response.Respond(c, http.StatusTooManyRequests, false, "Rate limit exceeded. Try again later.", map[string]interface{}{
-				"retry_after": "60s",
-				"reset_time":  resetTime.Format(time.RFC3339),
-				"limit":       100,
-				"remaining":   remaining,
-			}, "RATE_LIMIT_EXCEEDED")
-			c.Abort()
+		if !enforce(c, limiter, userID) {
 			return
 		}
-
-		// Add rate limit headers
-		remaining := limiter.GetRemaining(userID)
-		resetTime := limiter.GetResetTime(userID)
-
-		c.Header("X-RateLimit-Limit", "100")
-		c.Header("X-RateLimit-Remaining", string(rune(remaining)))
-		c.Header("X-RateLimit-Reset", resetTime.Format(time.RFC3339))
-
 		c.Next()
 	}
 }
@@ -94,33 +46,9 @@ func CustomKeyMiddleware(limiter *RateLimiter, keyFunc func(c *gin.Context) stri
 			key = c.ClientIP() // Fallback to IP
 		}
 
-		if !limiter.Allow(key) {
-			remaining := limiter.GetRemaining(key)
-			resetTime := limiter.GetResetTime(key)
-
-			c.Header("X-RateLimit-Limit", "100")
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", resetTime.Format(time.RFC3339))
-			c.Header("Retry-After", "60")
-
-			response.Respond(c, http.StatusTooManyRequests, false, "Rate limit exceeded. Try again later.", map[string]interface{}{
-				"retry_after": "60s",
-				"reset_time":  resetTime.Format(time.RFC3339),
-				"limit":       100,
-				"remaining":   remaining,
-			}, "RATE_LIMIT_EXCEEDED")
-			c.Abort()
+		if !enforce(c, limiter, key) {
 			return
 		}
-
-		// Add rate limit headers
-		remaining := limiter.GetRemaining(key)
-		resetTime := limiter.GetResetTime(key)
-
-		c.Header("X-RateLimit-Limit", "100")
-		c.Header("X-RateLimit-Remaining", string(rune(remaining)))
-		c.Header("X-RateLimit-Reset", resetTime.Format(time.RFC3339))
-
 		c.Next()
 	}
 }
@@ -152,34 +80,79 @@ func DifferentLimitsMiddleware(limiters map[string]*RateLimiter) gin.HandlerFunc
 			return
 		}
 
-		key := c.ClientIP()
-		if !limiter.Allow(key) {
-			remaining := limiter.GetRemaining(key)
-			resetTime := limiter.GetResetTime(key)
-
-			c.Header("X-RateLimit-Limit", "100")
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", resetTime.Format(time.RFC3339))
-			c.Header("Retry-After", "60")
-
-			response.Respond(c, http.StatusTooManyRequests, false, "Rate limit exceeded. Try again later.", map[string]interface{}{
-				"retry_after": "60s",
-				"reset_time":  resetTime.Format(time.RFC3339),
-				"limit":       100,
-				"remaining":   remaining,
-			}, "RATE_LIMIT_EXCEEDED")
-			c.Abort()
+		if !enforce(c, limiter, c.ClientIP()) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		// Add rate limit headers
-		remaining := limiter.GetRemaining(key)
-		resetTime := limiter.GetResetTime(key)
+// Rule binds a route pattern to a limiter. Pattern is matched against the
+// registered gin route (c.FullPath()); a trailing "*" matches any route with that prefix.
+type Rule struct {
+	Pattern string
+	Limiter *RateLimiter
+}
+
+// RouteMiddleware applies the first rule whose pattern matches the current route.
+// Requests on routes without a matching rule pass through unlimited.
+// Each rule keeps its own counters, so a client's budget on one policy does not drain another.
+func RouteMiddleware(rules []Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
 
-		c.Header("X-RateLimit-Limit", "100")
-		c.Header("X-RateLimit-Remaining", string(rune(remaining)))
-		c.Header("X-RateLimit-Reset", resetTime.Format(time.RFC3339))
+		for _, rule := range rules {
+			if rule.Limiter != nil && matchRoute(rule.Pattern, route) {
+				if !enforce(c, rule.Limiter, c.ClientIP()) {
+					return
+				}
+				break
+			}
+		}
 
 		c.Next()
 	}
 }
+
+// matchRoute reports whether route satisfies pattern (exact, or prefix when pattern ends in "*")
+func matchRoute(pattern, route string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(route, strings.TrimSuffix(pattern, "*"))
+	}
+	return route == pattern
+}
+
+// enforce records a request for key, sets the X-RateLimit-* headers and, when the
+// limit is exceeded, writes the 429 response and aborts. It returns false if aborted.
+func enforce(c *gin.Context, limiter *RateLimiter, key string) bool {
+	result := limiter.Take(c.Request.Context(), key)
+	allowed := result.Allowed
+	remaining := result.Remaining
+	resetTime := result.ResetAt
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limiter.Limit()))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(resetTime.Unix(), 10))
+
+	if allowed {
+		return true
+	}
+
+	retryAfter := int(math.Ceil(time.Until(resetTime).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+
+	response.TooManyRequests(c, "Rate limit exceeded. Try again later.", map[string]interface{}{
+		"retry_after": strconv.Itoa(retryAfter) + "s",
+		"reset_time":  resetTime.Format(time.RFC3339),
+		"limit":       limiter.Limit(),
+		"remaining":   remaining,
+	}, "RATE_LIMIT_EXCEEDED")
+	c.Abort()
+	return false
+}
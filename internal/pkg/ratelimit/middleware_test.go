@@ -35,3 +35,52 @@ func TestMiddleware_RateLimitExceeded(t *testing.T) {
 	require.Contains(t, data, "retry_after")
 	require.Contains(t, data, "reset_time")
 }
+
+func TestMiddleware_SetsRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lim := New(5, time.Minute)
+	r := gin.New()
+	r.Use(Middleware(lim))
+	r.GET("/", func(c *gin.Context) {
+		c.JSON(200, gin.H{"ok": true})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	require.Equal(t, 200, w.Code)
+	require.Equal(t, "5", w.Header().Get("X-RateLimit-Limit"))
+	require.Equal(t, "4", w.Header().Get("X-RateLimit-Remaining"))
+	require.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+	require.Empty(t, w.Header().Get("Retry-After"))
+}
+
+func TestRouteMiddleware_AppliesMatchingRule(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RouteMiddleware([]Rule{
+		{Pattern: "/login", Limiter: New(1, time.Minute)},
+		{Pattern: "/feed/*", Limiter: New(0, time.Minute)},
+	}))
+	ok := func(c *gin.Context) { c.JSON(200, gin.H{"ok": true}) }
+	r.POST("/login", ok)
+	r.GET("/feed/:id", ok)
+	r.GET("/open", ok)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	require.Equal(t, 200, serve("POST", "/login").Code)
+	w := serve("POST", "/login")
+	require.Equal(t, 429, w.Code)
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	require.Equal(t, 429, serve("GET", "/feed/abc").Code)
+
+	w = serve("GET", "/open")
+	require.Equal(t, 200, w.Code)
+	require.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+}
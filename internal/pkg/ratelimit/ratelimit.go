@@ -1,177 +1,182 @@
 package ratelimit
 
 import (
-	"sync"
+	"context"
+	"log"
 	"time"
 )
 
+// Result describes the outcome of a rate limit check for a single key
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time // when the key's budget is restored (or, if denied, when to retry)
+}
+
+// Store holds rate limit state. Implementations decide the algorithm and where
+// state lives; a shared store lets limits hold across several API instances.
+type Store interface {
+	// Take records a request for key and reports whether it is allowed
+	Take(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+	// Peek reports the current state for key without recording a request
+	Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+	// Reset clears all state for key
+	Reset(ctx context.Context, key string) error
+}
+
+// LocalStore is a Store whose state lives in process memory and must be swept
+type LocalStore interface {
+	Store
+	// Sweep evicts keys that have been idle for longer than their window
+	Sweep(now time.Time) int
+	// Clear removes all keys
+	Clear()
+	// Len returns the number of tracked keys
+	Len() int
+}
+
 // RateLimiter represents a rate limiter instance
 type RateLimiter struct {
-	requests map[string][]time.Time
-	limit    int
-	window   time.Duration
-	mu       sync.RWMutex
+	store     Store
+	limit     int
+	window    time.Duration
+	namespace string
 }
 
-// New creates a new rate limiter
-func New(limit int, window time.Duration) *RateLimiter {
-	return &RateLimiter{
-		requests: make(map[string][]time.Time),
-		limit:    limit,
-		window:   window,
+// Option configures a RateLimiter
+type Option func(*RateLimiter)
+
+// WithStore sets the backing store (defaults to an in-memory sliding log)
+func WithStore(store Store) Option {
+	return func(rl *RateLimiter) {
+		rl.store = store
 	}
 }
 
-// Allow checks if a request is allowed for the given key
-func (rl *RateLimiter) Allow(key string) bool {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	now := time.Now()
-	cutoff := now.Add(-rl.window)
-
-	// Clean old requests
-	var valid []time.Time
-	for _, t := range rl.requests[key] {
-		if t.After(cutoff) {
-			valid = append(valid, t)
-		}
+// WithNamespace prefixes every key, so several limiters can share one store
+func WithNamespace(namespace string) Option {
+	return func(rl *RateLimiter) {
+		rl.namespace = namespace
 	}
+}
 
-	if len(valid) >= rl.limit {
-		return false
+// New creates a new rate limiter
+func New(limit int, window time.Duration, opts ...Option) *RateLimiter {
+	rl := &RateLimiter{
+		limit:  limit,
+		window: window,
+	}
+	for _, opt := range opts {
+		opt(rl)
 	}
+	if rl.store == nil {
+		rl.store = NewMemoryStore()
+	}
+	return rl
+}
 
-	rl.requests[key] = append(valid, now)
-	return true
+// Limit returns the maximum number of requests allowed per window
+func (rl *RateLimiter) Limit() int {
+	return rl.limit
 }
 
-// GetRemaining returns the number of remaining requests for the given key
-func (rl *RateLimiter) GetRemaining(key string) int {
-	rl.mu.RLock()
-	defer rl.mu.RUnlock()
+// Window returns the duration of the rate limit window
+func (rl *RateLimiter) Window() time.Duration {
+	return rl.window
+}
 
-	now := time.Now()
-	cutoff := now.Add(-rl.window)
+// Store returns the backing store
+func (rl *RateLimiter) Store() Store {
+	return rl.store
+}
 
-	var valid int
-	for _, t := range rl.requests[key] {
-		if t.After(cutoff) {
-			valid++
-		}
+func (rl *RateLimiter) key(key string) string {
+	if rl.namespace == "" {
+		return key
 	}
+	return rl.namespace + ":" + key
+}
 
-	remaining := rl.limit - valid
-	if remaining < 0 {
-		remaining = 0
+// Take records a request for key and returns the full result.
+// If the store fails the request is allowed, so an outage never locks users out.
+func (rl *RateLimiter) Take(ctx context.Context, key string) Result {
+	result, err := rl.store.Take(ctx, rl.key(key), rl.limit, rl.window)
+	if err != nil {
+		log.Printf("ratelimit: store error for key %s: %v", key, err)
+		return Result{Allowed: true, Limit: rl.limit, Remaining: rl.limit, ResetAt: time.Now().Add(rl.window)}
 	}
+	return result
+}
 
-	return remaining
+// Allow checks if a request is allowed for the given key
+func (rl *RateLimiter) Allow(key string) bool {
+	return rl.Take(context.Background(), key).Allowed
 }
 
-// GetResetTime returns the time when the rate limit will reset for the given key
-func (rl *RateLimiter) GetResetTime(key string) time.Time {
-	rl.mu.RLock()
-	defer rl.mu.RUnlock()
-
-	now := time.Now()
-	cutoff := now.Add(-rl.window)
-
-	var oldest time.Time
-	for _, t := range rl.requests[key] {
-		if t.After(cutoff) {
-			if oldest.IsZero() || t.Before(oldest) {
-				oldest = t
-			}
-		}
+func (rl *RateLimiter) peek(key string) Result {
+	result, err := rl.store.Peek(context.Background(), rl.key(key), rl.limit, rl.window)
+	if err != nil {
+		return Result{Limit: rl.limit, Remaining: rl.limit, ResetAt: time.Now()}
 	}
+	return result
+}
 
-	if oldest.IsZero() {
-		return now
-	}
+// GetRemaining returns the number of remaining requests for the given key
+func (rl *RateLimiter) GetRemaining(key string) int {
+	return rl.peek(key).Remaining
+}
 
-	return oldest.Add(rl.window)
+// GetResetTime returns the time when the rate limit will reset for the given key
+func (rl *RateLimiter) GetResetTime(key string) time.Time {
+	return rl.peek(key).ResetAt
 }
 
 // Reset clears the rate limit for the given key
 func (rl *RateLimiter) Reset(key string) {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	delete(rl.requests, key)
+	_ = rl.store.Reset(context.Background(), rl.key(key))
 }
 
-// ResetAll clears all rate limits
+// ResetAll clears all rate limits (only supported by local stores)
 func (rl *RateLimiter) ResetAll() {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	rl.requests = make(map[string][]time.Time)
+	if local, ok := rl.store.(LocalStore); ok {
+		local.Clear()
+	}
 }
 
 // GetStats returns statistics about the rate limiter
 func (rl *RateLimiter) GetStats() map[string]interface{} {
-	rl.mu.RLock()
-	defer rl.mu.RUnlock()
-
-	now := time.Now()
-	cutoff := now.Add(-rl.window)
-
 	stats := map[string]interface{}{
 		"limit":  rl.limit,
 		"window": rl.window.String(),
-		"keys":   len(rl.requests),
 	}
 
-	var totalRequests int
-	var activeKeys int
-
-	for _, requests := range rl.requests {
-		var valid int
-		for _, t := range requests {
-			if t.After(cutoff) {
-				valid++
-			}
-		}
-
-		if valid > 0 {
-			activeKeys++
-			totalRequests += valid
-		}
+	if local, ok := rl.store.(LocalStore); ok {
+		stats["keys"] = local.Len()
+	}
+	if ms, ok := rl.store.(*MemoryStore); ok {
+		activeKeys, totalRequests := ms.activity(time.Now())
+		stats["activeKeys"] = activeKeys
+		stats["totalRequests"] = totalRequests
 	}
-
-	stats["activeKeys"] = activeKeys
-	stats["totalRequests"] = totalRequests
 
 	return stats
 }
 
 // Cleanup removes expired entries to prevent memory leaks
 func (rl *RateLimiter) Cleanup() {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	now := time.Now()
-	cutoff := now.Add(-rl.window)
-
-	for key, requests := range rl.requests {
-		var valid []time.Time
-		for _, t := range requests {
-			if t.After(cutoff) {
-				valid = append(valid, t)
-			}
-		}
-
-		if len(valid) == 0 {
-			delete(rl.requests, key)
-		} else {
-			rl.requests[key] = valid
-		}
+	if local, ok := rl.store.(LocalStore); ok {
+		local.Sweep(time.Now())
 	}
 }
 
-// StartCleanup starts a background cleanup routine
+// StartCleanup starts a background janitor that evicts idle keys.
+// It is a no-op for stores that expire their own state (e.g. MongoStore).
 func (rl *RateLimiter) StartCleanup(interval time.Duration) {
+	if _, ok := rl.store.(LocalStore); !ok {
+		return
+	}
+
 	go func() {
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// GCRAStore implements the generic cell rate algorithm, an exact token bucket
+// that stores a single timestamp (the theoretical arrival time) per key.
+// Requests refill smoothly at limit/window instead of all at once.
+type GCRAStore struct {
+	tats map[string]time.Time
+	mu   sync.Mutex
+}
+
+// NewGCRAStore creates an empty in-memory GCRA store
+func NewGCRAStore() *GCRAStore {
+	return &GCRAStore{
+		tats: make(map[string]time.Time),
+	}
+}
+
+// gcraEvaluate computes the outcome for a request at now given the stored TAT
+func gcraEvaluate(tat time.Time, limit int, window time.Duration, now time.Time) (Result, time.Time) {
+	if limit <= 0 {
+		return Result{Allowed: false, Limit: limit, ResetAt: now.Add(window)}, tat
+	}
+
+	interval := window / time.Duration(limit)
+	if tat.Before(now) {
+		tat = now
+	}
+
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-window)
+	if now.Before(allowAt) {
+		return Result{Allowed: false, Limit: limit, Remaining: 0, ResetAt: allowAt}, tat
+	}
+
+	remaining := int((window - newTAT.Sub(now)) / interval)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Result{Allowed: true, Limit: limit, Remaining: remaining, ResetAt: newTAT}, newTAT
+}
+
+// Take records a request for key and reports whether it is allowed
+func (s *GCRAStore) Take(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, tat := gcraEvaluate(s.tats[key], limit, window, time.Now())
+	if result.Allowed {
+		s.tats[key] = tat
+	}
+	return result, nil
+}
+
+// Peek reports the current state for key without recording a request
+func (s *GCRAStore) Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	tat := s.tats[key]
+	if tat.Before(now) {
+		tat = now
+	}
+
+	result := Result{Allowed: true, Limit: limit, Remaining: limit, ResetAt: tat}
+	if limit > 0 {
+		interval := window / time.Duration(limit)
+		result.Remaining = int((window - tat.Sub(now)) / interval)
+		if result.Remaining < 0 {
+			result.Remaining = 0
+		}
+	}
+	result.Allowed = result.Remaining > 0
+	return result, nil
+}
+
+// Reset clears all state for key
+func (s *GCRAStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tats, key)
+	return nil
+}
+
+// Sweep evicts keys whose bucket has fully refilled, since they carry no state
+func (s *GCRAStore) Sweep(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	evicted := 0
+	for key, tat := range s.tats {
+		if !tat.After(now) {
+			delete(s.tats, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// Clear removes all keys
+func (s *GCRAStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tats = make(map[string]time.Time)
+}
+
+// Len returns the number of tracked keys
+func (s *GCRAStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.tats)
+}
@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	hits   []time.Time
+	window time.Duration
+}
+
+// MemoryStore is an in-process sliding-log store. It is exact but keeps one
+// timestamp per request, so it suits low limits on a single instance.
+type MemoryStore struct {
+	entries map[string]*memoryEntry
+	mu      sync.Mutex
+}
+
+// NewMemoryStore creates an empty in-memory sliding-log store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+// prune drops hits that fell out of the window; caller must hold the lock
+func (s *MemoryStore) prune(key string, window time.Duration, now time.Time) *memoryEntry {
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+
+	cutoff := now.Add(-window)
+	valid := entry.hits[:0]
+	for _, t := range entry.hits {
+		if t.After(cutoff) {
+			valid = append(valid, t)
+		}
+	}
+	entry.hits = valid
+	entry.window = window
+	return entry
+}
+
+func (s *MemoryStore) result(entry *memoryEntry, limit int, window time.Duration, now time.Time) Result {
+	used := 0
+	resetAt := now
+	if entry != nil && len(entry.hits) > 0 {
+		used = len(entry.hits)
+		resetAt = entry.hits[0].Add(window)
+	}
+
+	remaining := limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Result{
+		Allowed:   used < limit,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetAt:   resetAt,
+	}
+}
+
+// Take records a request for key and reports whether it is allowed
+func (s *MemoryStore) Take(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry := s.prune(key, window, now)
+	result := s.result(entry, limit, window, now)
+	if !result.Allowed {
+		if result.ResetAt.Equal(now) {
+			result.ResetAt = now.Add(window)
+		}
+		return result, nil
+	}
+
+	if entry == nil {
+		entry = &memoryEntry{window: window}
+		s.entries[key] = entry
+	}
+	entry.hits = append(entry.hits, now)
+
+	// This request was allowed; result would count it against itself
+	result = s.result(entry, limit, window, now)
+	result.Allowed = true
+	result.Remaining = limit - len(entry.hits)
+	return result, nil
+}
+
+// Peek reports the current state for key without recording a request
+func (s *MemoryStore) Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	return s.result(s.prune(key, window, now), limit, window, now), nil
+}
+
+// Reset clears all state for key
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Sweep evicts keys with no hits inside their window
+func (s *MemoryStore) Sweep(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	evicted := 0
+	for key, entry := range s.entries {
+		if len(s.prune(key, entry.window, now).hits) == 0 {
+			delete(s.entries, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// Clear removes all keys
+func (s *MemoryStore) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[string]*memoryEntry)
+}
+
+// Len returns the number of tracked keys
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+// activity counts keys and requests that are still inside their window
+func (s *MemoryStore) activity(now time.Time) (activeKeys int, totalRequests int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.entries {
+		cutoff := now.Add(-entry.window)
+		valid := 0
+		for _, t := range entry.hits {
+			if t.After(cutoff) {
+				valid++
+			}
+		}
+		if valid > 0 {
+			activeKeys++
+			totalRequests += valid
+		}
+	}
+	return activeKeys, totalRequests
+}
@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoStore is a shared fixed-window counter store. Each key/window pair is a
+// document whose TTL index removes it once the window has passed, so several
+// API instances enforce a single limit without any local state to sweep.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+type mongoCounter struct {
+	ID        string    `bson:"_id"`
+	Key       string    `bson:"key"`
+	Count     int       `bson:"count"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// NewMongoStore creates a store backed by the given collection and ensures its indexes
+func NewMongoStore(db *mongo.Database, collectionName string) *MongoStore {
+	if collectionName == "" {
+		collectionName = "rate_limits"
+	}
+	collection := db.Collection(collectionName)
+
+	_, _ = collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys: bson.D{{Key: "key", Value: 1}},
+		},
+	})
+
+	return &MongoStore{collection: collection}
+}
+
+// windowBounds returns the start and end of the fixed window containing now
+func windowBounds(now time.Time, window time.Duration) (time.Time, time.Time) {
+	start := now.Truncate(window)
+	return start, start.Add(window)
+}
+
+func counterID(key string, start time.Time) string {
+	return key + "@" + strconv.FormatInt(start.Unix(), 10)
+}
+
+// Take records a request for key and reports whether it is allowed
+func (s *MongoStore) Take(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	start, end := windowBounds(time.Now(), window)
+
+	filter := bson.M{"_id": counterID(key, start)}
+	update := bson.M{
+		"$inc":         bson.M{"count": 1},
+		"$setOnInsert": bson.M{"key": key, "expiresAt": end},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var counter mongoCounter
+	if err := s.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter); err != nil {
+		return Result{}, err
+	}
+
+	remaining := limit - counter.Count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Result{
+		Allowed:   counter.Count <= limit,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetAt:   end,
+	}, nil
+}
+
+// Peek reports the current state for key without recording a request
+func (s *MongoStore) Peek(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	start, end := windowBounds(time.Now(), window)
+
+	var counter mongoCounter
+	err := s.collection.FindOne(ctx, bson.M{"_id": counterID(key, start)}).Decode(&counter)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return Result{}, err
+	}
+
+	remaining := limit - counter.Count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Result{
+		Allowed:   counter.Count < limit,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetAt:   end,
+	}, nil
+}
+
+// Reset clears all state for key
+func (s *MongoStore) Reset(ctx context.Context, key string) error {
+	_, err := s.collection.DeleteMany(ctx, bson.M{"key": key})
+	return err
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_SlidingLog(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	for i := 0; i < 3; i++ {
+		res, err := s.Take(ctx, "k", 3, time.Minute)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, 2-i, res.Remaining)
+	}
+
+	res, err := s.Take(ctx, "k", 3, time.Minute)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, 0, res.Remaining)
+	require.True(t, res.ResetAt.After(time.Now()))
+}
+
+func TestMemoryStore_SweepEvictsIdleKeys(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	_, _ = s.Take(ctx, "idle", 5, 10*time.Millisecond)
+	_, _ = s.Take(ctx, "busy", 5, time.Hour)
+	require.Equal(t, 2, s.Len())
+
+	evicted := s.Sweep(time.Now().Add(time.Second))
+	require.Equal(t, 1, evicted)
+	require.Equal(t, 1, s.Len())
+}
+
+func TestGCRAStore_BurstThenDeny(t *testing.T) {
+	ctx := context.Background()
+	s := NewGCRAStore()
+
+	for i := 0; i < 5; i++ {
+		res, err := s.Take(ctx, "k", 5, time.Minute)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, 4-i, res.Remaining)
+	}
+
+	res, err := s.Take(ctx, "k", 5, time.Minute)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+
+	// The next token arrives after one emission interval (window / limit)
+	wait := time.Until(res.ResetAt)
+	require.InDelta(t, (12 * time.Second).Seconds(), wait.Seconds(), 1)
+	require.Equal(t, 1, s.Len())
+}
+
+func TestGCRAStore_SweepEvictsRefilledKeys(t *testing.T) {
+	ctx := context.Background()
+	s := NewGCRAStore()
+
+	_, _ = s.Take(ctx, "k", 10, time.Minute)
+	require.Equal(t, 0, s.Sweep(time.Now()))
+	require.Equal(t, 1, s.Sweep(time.Now().Add(time.Minute)))
+	require.Equal(t, 0, s.Len())
+}
+
+func TestRateLimiter_NamespacesShareStore(t *testing.T) {
+	store := NewMemoryStore()
+	a := New(1, time.Minute, WithStore(store), WithNamespace("a"))
+	b := New(1, time.Minute, WithStore(store), WithNamespace("b"))
+
+	require.True(t, a.Allow("ip"))
+	require.False(t, a.Allow("ip"))
+	require.True(t, b.Allow("ip"))
+	require.Equal(t, 2, store.Len())
+}
@@ -115,6 +115,17 @@ func ValidationError(c *gin.Context, message string, errorCode ...string) {
 	Error(c, http.StatusUnprocessableEntity, message, errorCode...)
 }
 
+// TooManyRequests sends a 429 Too Many Requests error. data tells the client
+// when it may try again.
+func TooManyRequests(c *gin.Context, message string, data interface{}, errorCode ...string) {
+	code := ""
+	if len(errorCode) > 0 {
+		code = errorCode[0]
+	}
+
+	Respond(c, http.StatusTooManyRequests, false, message, data, code)
+}
+
 // InternalServerError sends a 500 Internal Server Error
 func InternalServerError(c *gin.Context, message string, errorCode ...string) {
 	Error(c, http.StatusInternalServerError, message, errorCode...)
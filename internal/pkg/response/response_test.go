@@ -57,3 +57,21 @@ func TestPaginatedResponse(t *testing.T) {
 	require.Equal(t, float64(10), data["limit"].(float64))
 	require.Equal(t, float64(1), data["page"].(float64))
 }
+
+func TestTooManyRequests(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	TooManyRequests(c, "slow down", map[string]any{"retry_after": "5s"}, "RATE_LIMIT_EXCEEDED")
+
+	require.Equal(t, 429, w.Code)
+	var body map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &body)
+	require.NoError(t, err)
+	require.Equal(t, false, body["success"])
+	require.Equal(t, float64(429), body["statusCode"])
+	require.Equal(t, "slow down", body["message"])
+	require.Equal(t, "RATE_LIMIT_EXCEEDED", body["code"])
+	data := body["data"].(map[string]any)
+	require.Equal(t, "5s", data["retry_after"])
+}
@@ -0,0 +1,145 @@
+// Package safehttp provides an HTTP client for fetching user-supplied URLs.
+// It only connects to public addresses on the standard web ports, so a URL
+// cannot be used to reach internal services or cloud metadata endpoints.
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ErrBlocked is returned, wrapped, for URLs and addresses the client refuses to reach
+var ErrBlocked = errors.New("destination not allowed")
+
+// MaxRedirects is how many redirects a request may follow
+const MaxRedirects = 5
+
+// allowedPorts are the ports the client connects to
+var allowedPorts = map[int]bool{80: true, 443: true}
+
+// blockedPrefixes are the non-public address ranges
+var blockedPrefixes = mustParsePrefixes(
+	"0.0.0.0/8",      // "this" network
+	"10.0.0.0/8",     // private
+	"100.64.0.0/10",  // carrier-grade NAT
+	"127.0.0.0/8",    // loopback
+	"169.254.0.0/16", // link-local, including cloud metadata at 169.254.169.254
+	"172.16.0.0/12",  // private
+	"192.0.0.0/24",   // IETF protocol assignments
+	"192.168.0.0/16", // private
+	"198.18.0.0/15",  // benchmarking
+	"224.0.0.0/4",    // multicast
+	"240.0.0.0/4",    // reserved, including broadcast
+	"::/128",         // unspecified
+	"::1/128",        // loopback
+	"64:ff9b::/96",   // NAT64, which can map to any of the IPv4 ranges above
+	"fc00::/7",       // unique local, including cloud metadata at fd00:ec2::254
+	"fe80::/10",      // link-local
+	"ff00::/8",       // multicast
+)
+
+func mustParsePrefixes(cidrs ...string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, len(cidrs))
+	for i, cidr := range cidrs {
+		prefixes[i] = netip.MustParsePrefix(cidr)
+	}
+	return prefixes
+}
+
+// IsPublic reports whether addr is a public unicast address
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL reports whether u may be fetched: it must be http or https on an
+// allowed port, and a literal IP host must be public. Host names are checked
+// when the client connects, against the addresses they resolve to.
+func CheckURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrBlocked, u.Scheme)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrBlocked)
+	}
+
+	port := 80
+	if u.Scheme == "https" {
+		port = 443
+	}
+	if p := u.Port(); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return fmt.Errorf("%w: port %q", ErrBlocked, p)
+		}
+		port = n
+	}
+	if !allowedPorts[port] {
+		return fmt.Errorf("%w: port %d", ErrBlocked, port)
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublic(addr) {
+		return fmt.Errorf("%w: address %s", ErrBlocked, addr)
+	}
+	return nil
+}
+
+// NewClient returns a client that only connects to public addresses on the
+// allowed ports. Every connection is checked after DNS resolution, so a host
+// name that resolves, or later re-resolves, to an internal address is
+// refused, and every redirect target is checked before it is followed.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: checkConn,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy: it would make the connection, bypassing the address check
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          20,
+			IdleConnTimeout:       30 * time.Second,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: timeout,
+		},
+		CheckRedirect: checkRedirect,
+	}
+}
+
+// checkConn runs before each connection with the resolved address
+func checkConn(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlocked, address)
+	}
+	if !IsPublic(addrPort.Addr()) || !allowedPorts[int(addrPort.Port())] {
+		return fmt.Errorf("%w: %s", ErrBlocked, address)
+	}
+	return nil
+}
+
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= MaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", MaxRedirects)
+	}
+	return CheckURL(req.URL)
+}
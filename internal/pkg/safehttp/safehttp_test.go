@@ -0,0 +1,93 @@
+package safehttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublic(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"8.8.8.8":            true,
+		"1.1.1.1":            true,
+		"2606:4700::1111":    true,
+		"127.0.0.1":          false,
+		"10.1.2.3":           false,
+		"172.20.0.1":         false,
+		"192.168.1.1":        false,
+		"100.64.0.1":         false,
+		"169.254.169.254":    false,
+		"0.0.0.0":            false,
+		"255.255.255.255":    false,
+		"::1":                false,
+		"::":                 false,
+		"::ffff:127.0.0.1":   false, // IPv4-mapped
+		"::ffff:10.0.0.1":    false,
+		"64:ff9b::a9fe:a9fe": false, // NAT64 of 169.254.169.254
+		"fd00:ec2::254":      false,
+		"fe80::1":            false,
+	} {
+		require.Equal(t, public, IsPublic(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	for raw, allowed := range map[string]bool{
+		"https://example.com/page":      true,
+		"http://example.com:80/":        true,
+		"https://example.com:443/":      true,
+		"http://8.8.8.8/":               true,
+		"ftp://example.com/file":        false,
+		"file:///etc/passwd":            false,
+		"gopher://example.com/":         false,
+		"http://example.com:22/":        false,
+		"http://example.com:6379/":      false,
+		"http://127.0.0.1/":             false,
+		"http://169.254.169.254/latest": false,
+		"http://[::1]/":                 false,
+		"http://[::ffff:127.0.0.1]/":    false,
+		"http:///path":                  false,
+	} {
+		u, err := url.Parse(raw)
+		require.NoError(t, err, raw)
+		if allowed {
+			require.NoError(t, CheckURL(u), raw)
+		} else {
+			require.ErrorIs(t, CheckURL(u), ErrBlocked, raw)
+		}
+	}
+}
+
+func TestClient_RefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	// The host name resolves to loopback; the check happens at connect time
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	u.Host = "localhost:" + u.Port()
+
+	_, err = NewClient(time.Second).Get(u.String())
+	require.ErrorIs(t, err, ErrBlocked)
+}
+
+func TestCheckRedirect(t *testing.T) {
+	req := func(raw string) *http.Request {
+		r, err := http.NewRequest(http.MethodGet, raw, nil)
+		require.NoError(t, err)
+		return r
+	}
+
+	require.NoError(t, checkRedirect(req("https://example.com/next"), []*http.Request{req("https://example.com/")}))
+	require.ErrorIs(t, checkRedirect(req("http://169.254.169.254/latest/meta-data"), []*http.Request{req("https://example.com/")}), ErrBlocked)
+
+	via := make([]*http.Request, MaxRedirects)
+	require.Error(t, checkRedirect(req("https://example.com/again"), via))
+}
@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Stat when nothing has been uploaded to a public ID
+var ErrNotFound = errors.New("storage: asset not found")
+
+// UploadTicket lets a client upload one file straight to storage
+type UploadTicket struct {
+	PublicID string            `json:"publicId"`
+	URL      string            `json:"url"`
+	Method   string            `json:"method"`           // "POST" (multipart form, file in the "file" field) or "PUT" (raw body)
+	Fields   map[string]string `json:"fields,omitempty"` // form fields to send alongside the file
+}
+
+// DirectUploader is implemented by drivers that accept uploads straight from
+// clients, so large files do not pass through the API
+type DirectUploader interface {
+	// SignUpload reserves a new public ID and returns how to upload a file of
+	// kind to it. The ticket is valid until about expiresAt.
+	SignUpload(ctx context.Context, kind Kind, filename string, expiresAt time.Time) (*UploadTicket, error)
+	// Stat describes an uploaded asset, or returns ErrNotFound
+	Stat(ctx context.Context, kind Kind, publicID string) (*UploadResult, error)
+}
@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Content types the allowed extensions must sniff as
+const (
+	typeJPEG = "image/jpeg"
+	typePNG  = "image/png"
+	typeGIF  = "image/gif"
+	typeWebP = "image/webp"
+	typeMP3  = "audio/mpeg"
+	typeWAV  = "audio/wave"
+	typeAAC  = "audio/aac"
+	typeM4A  = "audio/mp4"
+	typeOgg  = "audio/ogg"
+	typePDF  = "application/pdf"
+	typeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	typeDOC  = "application/msword"
+	typeEPUB = "application/epub+zip"
+	typeText = "text/plain"
+)
+
+// extensionTypes maps each allowed extension to the content type its files must have
+var extensionTypes = map[string]string{
+	".jpg":  typeJPEG,
+	".jpeg": typeJPEG,
+	".png":  typePNG,
+	".gif":  typeGIF,
+	".webp": typeWebP,
+	".mp3":  typeMP3,
+	".wav":  typeWAV,
+	".aac":  typeAAC,
+	".m4a":  typeM4A,
+	".ogg":  typeOgg,
+	".pdf":  typePDF,
+	".docx": typeDOCX,
+	".doc":  typeDOC,
+	".epub": typeEPUB,
+	".txt":  typeText,
+}
+
+// sniffLen is how much of a file DetectContentType looks at
+const sniffLen = 512
+
+// FileInfo is what inspecting an upload's contents found
+type FileInfo struct {
+	ContentType string
+	Width       int     // images
+	Height      int     // images
+	Duration    float64 // audio, in seconds
+}
+
+// Inspect validates an upload by its contents rather than its name: the bytes
+// must be of the type its extension claims, images must be within the pixel
+// limits and audio within the length limit.
+func Inspect(kind Kind, r io.ReaderAt, filename string, size int64) (*FileInfo, error) {
+	if err := Validate(kind, filename, size); err != nil {
+		return nil, err
+	}
+
+	ext := getFileExtension(filename)
+	contentType := DetectContentType(r, size)
+	if contentType != extensionTypes[ext] {
+		return nil, invalid(CodeContentMismatch, "file contents do not match its %s extension", ext)
+	}
+
+	info := &FileInfo{ContentType: contentType}
+	switch kind {
+	case KindImage:
+		width, height, err := imageDimensions(r, size, contentType)
+		if err != nil {
+			return nil, invalid(CodeUnreadableMedia, "could not read the image's dimensions")
+		}
+		if err := CheckDimensions(width, height); err != nil {
+			return nil, err
+		}
+		info.Width, info.Height = width, height
+	case KindAudio:
+		duration, err := audioDuration(r, size, contentType)
+		if err != nil {
+			return nil, invalid(CodeUnreadableMedia, "could not read the audio's duration")
+		}
+		if err := CheckDuration(duration); err != nil {
+			return nil, err
+		}
+		info.Duration = duration
+	}
+	return info, nil
+}
+
+// Prepare inspects an upload and returns the bytes that should be stored.
+// Images come back with their metadata stripped; other files are unchanged.
+func Prepare(kind Kind, r io.ReaderAt, filename string, size int64) (io.Reader, *FileInfo, error) {
+	info, err := Inspect(kind, r, filename, size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content := io.NewSectionReader(r, 0, size)
+	if kind != KindImage {
+		return content, info, nil
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	stripped, err := StripMetadata(info.ContentType, data)
+	if err != nil {
+		return nil, nil, invalid(CodeUnreadableMedia, "could not read the image")
+	}
+	return bytes.NewReader(stripped), info, nil
+}
+
+// DetectContentType sniffs a file's type from its leading bytes. It recognizes
+// every allowed upload type, including the ones net/http does not, and falls
+// back to http.DetectContentType without parameters.
+func DetectContentType(r io.ReaderAt, size int64) string {
+	head := make([]byte, sniffLen)
+	n, _ := r.ReadAt(head, 0)
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		// Checked before text, since a PDF can be all ASCII
+		return typePDF
+	case bytes.HasPrefix(head, []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")):
+		// OLE2 compound file, the container of .doc
+		return typeDOC
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		return zipContentType(r, size, head)
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		if isAudioMP4(head) {
+			return typeM4A
+		}
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return typeOgg
+	case bytes.HasPrefix(head, []byte("ID3")):
+		// Tagged MP3 or AAC; the audio stream starts after the tag
+		frame := make([]byte, 4)
+		if _, err := r.ReadAt(frame, id3Size(head)); err == nil && isADTS(frame) {
+			return typeAAC
+		}
+		return typeMP3
+	case isADTS(head):
+		return typeAAC
+	case isMP3Frame(head):
+		return typeMP3
+	case isText(head):
+		return typeText
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	return contentType
+}
+
+// zipContentType tells EPUB and Word documents apart from other ZIP archives
+func zipContentType(r io.ReaderAt, size int64, head []byte) string {
+	// EPUB requires an uncompressed "mimetype" entry first
+	if len(head) >= 58 && string(head[30:38]) == "mimetype" && string(head[38:58]) == typeEPUB {
+		return typeEPUB
+	}
+
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		// Only the start of the file may be available; Office documents
+		// list their parts first
+		if len(head) >= 49 && string(head[30:49]) == "[Content_Types].xml" {
+			return typeDOCX
+		}
+		return "application/zip"
+	}
+	for _, f := range archive.File {
+		if f.Name == "word/document.xml" {
+			return typeDOCX
+		}
+	}
+	return "application/zip"
+}
+
+// isAudioMP4 reports whether an ftyp box names an audio-only MP4 brand
+func isAudioMP4(head []byte) bool {
+	boxSize := int(head[0])<<24 | int(head[1])<<16 | int(head[2])<<8 | int(head[3])
+	if boxSize > len(head) {
+		boxSize = len(head)
+	}
+	// Major brand, then compatible brands after the minor version
+	for i := 8; i+4 <= boxSize; i += 4 {
+		if i == 12 {
+			continue
+		}
+		switch string(head[i : i+4]) {
+		case "M4A ", "M4B ":
+			return true
+		}
+	}
+	return false
+}
+
+// isText reports whether head looks like UTF-8 text
+func isText(head []byte) bool {
+	if len(head) == 0 {
+		return false
+	}
+	// The sniffed window can end part way through a character
+	for i := 0; i < utf8.UTFMax-1 && len(head) > 0 && !utf8.Valid(head); i++ {
+		head = head[:len(head)-1]
+	}
+	if !utf8.Valid(head) {
+		return false
+	}
+	for _, b := range head {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid), "expected a validation error, got %v", err)
+	require.Equal(t, code, invalid.Code)
+}
+
+func zipOf(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		_, err := w.Create(name)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+// wav builds a PCM WAV header followed by dataSize bytes of silence
+func wav(byteRate uint32, dataSize int) []byte {
+	b := []byte("RIFF\x00\x00\x00\x00WAVEfmt ")
+	b = binary.LittleEndian.AppendUint32(b, 16)
+	b = binary.LittleEndian.AppendUint16(b, 1) // PCM
+	b = binary.LittleEndian.AppendUint16(b, 1) // mono
+	b = binary.LittleEndian.AppendUint32(b, byteRate)
+	b = binary.LittleEndian.AppendUint32(b, byteRate)
+	b = binary.LittleEndian.AppendUint16(b, 1)
+	b = binary.LittleEndian.AppendUint16(b, 8)
+	b = append(b, "data"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(dataSize))
+	b = append(b, make([]byte, dataSize)...)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(b)-8))
+	return b
+}
+
+func inspect(kind Kind, data []byte, filename string) (*FileInfo, error) {
+	return Inspect(kind, bytes.NewReader(data), filename, int64(len(data)))
+}
+
+func TestInspect_ChecksContentsAgainstExtension(t *testing.T) {
+	_, err := inspect(KindFile, []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00"), "invoice.pdf")
+	requireCode(t, err, CodeContentMismatch)
+
+	_, err = inspect(KindImage, []byte("%PDF-1.4\n"), "photo.png")
+	requireCode(t, err, CodeContentMismatch)
+
+	_, err = inspect(KindFile, []byte("MZ"), "setup.exe")
+	requireCode(t, err, CodeUnsupportedType)
+
+	info, err := inspect(KindFile, []byte("%PDF-1.4\n"), "invoice.PDF")
+	require.NoError(t, err)
+	require.Equal(t, "application/pdf", info.ContentType)
+
+	docx := zipOf(t, "[Content_Types].xml", "word/document.xml")
+	info, err = inspect(KindFile, docx, "letter.docx")
+	require.NoError(t, err)
+	require.Equal(t, typeDOCX, info.ContentType)
+
+	_, err = inspect(KindFile, zipOf(t, "payload.exe"), "letter.docx")
+	requireCode(t, err, CodeContentMismatch)
+
+	info, err = inspect(KindFile, []byte("Shopping list\n- café\n"), "notes.txt")
+	require.NoError(t, err)
+	require.Equal(t, "text/plain", info.ContentType)
+}
+
+func TestInspect_ImageDimensions(t *testing.T) {
+	info, err := inspect(KindImage, encodePNG(t, 3, 2), "photo.png")
+	require.NoError(t, err)
+	require.Equal(t, 3, info.Width)
+	require.Equal(t, 2, info.Height)
+
+	_, err = inspect(KindImage, encodePNG(t, MaxImageDimension+1, 1), "banner.png")
+	requireCode(t, err, CodeImageTooLarge)
+
+	// Lossless WebP header for a 640x480 canvas
+	webp := []byte("RIFF\x00\x00\x00\x00WEBPVP8L\x00\x00\x00\x00\x2f")
+	webp = binary.LittleEndian.AppendUint32(webp, 639|479<<14)
+	webp = append(webp, make([]byte, 16)...)
+	info, err = inspect(KindImage, webp, "photo.webp")
+	require.NoError(t, err)
+	require.Equal(t, 640, info.Width)
+	require.Equal(t, 480, info.Height)
+}
+
+func TestInspect_AudioDuration(t *testing.T) {
+	info, err := inspect(KindAudio, wav(8000, 16000), "clip.wav")
+	require.NoError(t, err)
+	require.InDelta(t, 2.0, info.Duration, 0.001)
+
+	_, err = inspect(KindAudio, wav(1, int(MaxAudioDuration.Seconds())+1), "long.wav")
+	requireCode(t, err, CodeAudioTooLong)
+
+	// Constant bitrate MP3: MPEG-1 layer III, 128kbps, 44.1kHz
+	mp3 := append([]byte{0xff, 0xfb, 0x90, 0x64}, make([]byte, 15996)...)
+	info, err = inspect(KindAudio, mp3, "clip.mp3")
+	require.NoError(t, err)
+	require.Equal(t, "audio/mpeg", info.ContentType)
+	require.InDelta(t, 1.0, info.Duration, 0.001)
+
+	_, err = inspect(KindAudio, []byte("ID3\x03\x00\x00\x00\x00\x00\x00"), "empty.mp3")
+	requireCode(t, err, CodeUnreadableMedia)
+}
+
+func TestPrepare_StripsImageMetadata(t *testing.T) {
+	data := encodePNG(t, 2, 2)
+
+	// Add a text chunk after the header chunk
+	text := "GPS\x0051.5,-0.1"
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(text)))
+	chunk = append(chunk, "tEXt"+text...)
+	chunk = append(chunk, 0, 0, 0, 0) // CRC, not checked
+	tagged := append(append(append([]byte(nil), data[:33]...), chunk...), data[33:]...)
+
+	content, info, err := Prepare(KindImage, bytes.NewReader(tagged), "photo.png", int64(len(tagged)))
+	require.NoError(t, err)
+	require.Equal(t, 2, info.Width)
+
+	var stripped bytes.Buffer
+	_, err = stripped.ReadFrom(content)
+	require.NoError(t, err)
+	require.Equal(t, data, stripped.Bytes())
+
+	// Other kinds are passed through untouched
+	content, _, err = Prepare(KindFile, strings.NewReader("plain text"), "notes.txt", 10)
+	require.NoError(t, err)
+	stripped.Reset()
+	_, err = stripped.ReadFrom(content)
+	require.NoError(t, err)
+	require.Equal(t, "plain text", stripped.String())
+}
@@ -0,0 +1,286 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	// Decoders for reading image dimensions
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// ErrInvalidPublicID is returned for public IDs this driver could not have issued
+var ErrInvalidPublicID = errors.New("storage: invalid public ID")
+
+// LocalStorage stores media on the local filesystem, for development and CI.
+// Files are served by the API through a download route; their URLs carry an
+// HMAC signature so other files under the root cannot be fetched by guessing.
+type LocalStorage struct {
+	root    string
+	baseURL string
+	secret  []byte
+	folder  string
+}
+
+// NewLocalStorage creates a driver storing files under root. baseURL is the
+// download route that files are served from.
+func NewLocalStorage(root, baseURL, secret, folder string) (*LocalStorage, error) {
+	if root == "" || baseURL == "" {
+		return nil, errors.New("storage: local root and base URL are required")
+	}
+	if secret == "" {
+		return nil, errors.New("storage: a signing secret is required")
+	}
+	if folder == "" {
+		folder = "anchor"
+	}
+
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: failed to create %s: %w", root, err)
+	}
+
+	return &LocalStorage{
+		root:    root,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  []byte(secret),
+		folder:  folder,
+	}, nil
+}
+
+// Upload writes file under the folder for its kind
+func (s *LocalStorage) Upload(ctx context.Context, kind Kind, file io.Reader, filename string) (*UploadResult, error) {
+	publicID, err := s.newPublicID(kind, filepath.Ext(filename))
+	if err != nil {
+		return nil, err
+	}
+	return s.write(kind, publicID, file)
+}
+
+// Copy duplicates the file under a new public ID
+func (s *LocalStorage) Copy(ctx context.Context, kind Kind, publicID, sourceURL string) (*UploadResult, error) {
+	src, err := s.Open(publicID)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	copyID, err := s.newPublicID(kind, path.Ext(publicID))
+	if err != nil {
+		return nil, err
+	}
+	return s.write(kind, copyID, src)
+}
+
+// Delete removes the file. Deleting a missing file is not an error.
+func (s *LocalStorage) Delete(ctx context.Context, kind Kind, publicID string) error {
+	name, err := s.path(publicID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete asset: %w", err)
+	}
+	return nil
+}
+
+// Open opens a stored file for reading
+func (s *LocalStorage) Open(publicID string) (*os.File, error) {
+	name, err := s.path(publicID)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(name)
+}
+
+// URL returns the signed download URL for a public ID
+func (s *LocalStorage) URL(publicID string) string {
+	return s.baseURL + "/" + publicID + "?sig=" + url.QueryEscape(s.sign(publicID))
+}
+
+// Verify reports whether sig was issued for publicID by this driver
+func (s *LocalStorage) Verify(publicID, sig string) bool {
+	return hmac.Equal([]byte(sig), []byte(s.sign(publicID)))
+}
+
+// SignUpload returns a URL the client can PUT the file to until expiresAt
+func (s *LocalStorage) SignUpload(ctx context.Context, kind Kind, filename string, expiresAt time.Time) (*UploadTicket, error) {
+	publicID, err := s.newPublicID(kind, filepath.Ext(filename))
+	if err != nil {
+		return nil, err
+	}
+
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{}
+	query.Set("kind", string(kind))
+	query.Set("expires", expires)
+	query.Set("sig", s.sign(uploadPayload(publicID, string(kind), expires)))
+
+	return &UploadTicket{
+		PublicID: publicID,
+		URL:      s.baseURL + "/" + publicID + "?" + query.Encode(),
+		Method:   http.MethodPut,
+	}, nil
+}
+
+// VerifyUpload reports whether the upload URL parameters were issued by
+// SignUpload for publicID and have not expired
+func (s *LocalStorage) VerifyUpload(publicID, kind, expires, sig string, now time.Time) bool {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(s.sign(uploadPayload(publicID, kind, expires))))
+}
+
+// Receive stores the body of a signed upload once it passes the same checks
+// as an upload through the API. Each public ID can only be written once.
+func (s *LocalStorage) Receive(kind Kind, publicID string, r io.Reader) (*UploadResult, error) {
+	if _, err := s.path(publicID); err != nil {
+		return nil, err
+	}
+
+	// Spool the body so its contents can be inspected before it is stored
+	tmp, err := os.CreateTemp(s.root, ".receive-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	content, _, err := Prepare(kind, tmp, publicID, size)
+	if err != nil {
+		return nil, err
+	}
+	return s.write(kind, publicID, content)
+}
+
+// Stat describes a stored file
+func (s *LocalStorage) Stat(ctx context.Context, kind Kind, publicID string) (*UploadResult, error) {
+	name, err := s.path(publicID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(name)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.describe(kind, publicID, name, info.Size()), nil
+}
+
+func (s *LocalStorage) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// uploadPayload is what upload signatures cover. The prefix keeps download
+// signatures, which cover the bare public ID, from being used to upload.
+func uploadPayload(publicID, kind, expires string) string {
+	return "upload:" + kind + ":" + expires + ":" + publicID
+}
+
+// path maps a public ID to its file, rejecting IDs that would escape the root
+func (s *LocalStorage) path(publicID string) (string, error) {
+	clean := strings.TrimPrefix(path.Clean("/"+publicID), "/")
+	if clean == "" || clean != publicID || strings.Contains(publicID, "\\") {
+		return "", ErrInvalidPublicID
+	}
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
+
+func (s *LocalStorage) newPublicID(kind Kind, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	var dir string
+	switch kind {
+	case KindImage:
+		dir = "images"
+	case KindAudio:
+		dir = "audio"
+	default:
+		dir = "files"
+	}
+
+	return s.folder + "/" + dir + "/" + hex.EncodeToString(b) + strings.ToLower(ext), nil
+}
+
+func (s *LocalStorage) write(kind Kind, publicID string, r io.Reader) (*UploadResult, error) {
+	name, err := s.path(publicID)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+	size, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(name)
+		return nil, fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return s.describe(kind, publicID, name, size), nil
+}
+
+func (s *LocalStorage) describe(kind Kind, publicID, name string, size int64) *UploadResult {
+	result := &UploadResult{
+		URL:      s.URL(publicID),
+		PublicID: publicID,
+		FileSize: size,
+		Format:   strings.TrimPrefix(path.Ext(publicID), "."),
+	}
+	if kind == KindImage {
+		result.Width, result.Height = imageSize(name)
+	}
+	return result
+}
+
+// imageSize reads an image's dimensions, or zeros for formats it cannot decode
+func imageSize(name string) (int, int) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, 0
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0
+	}
+	return cfg.Width, cfg.Height
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLocalStorage(t *testing.T) *LocalStorage {
+	t.Helper()
+	s, err := NewLocalStorage(t.TempDir(), "http://localhost:8080/api/v1/media/files/", "secret", "tests")
+	require.NoError(t, err)
+	return s
+}
+
+func TestLocalStorage_UploadAndOpen(t *testing.T) {
+	s := newTestLocalStorage(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 3, 2))))
+	size := int64(buf.Len())
+
+	res, err := s.Upload(context.Background(), KindImage, &buf, "Photo.PNG")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(res.PublicID, "tests/images/"))
+	require.True(t, strings.HasSuffix(res.PublicID, ".png"))
+	require.Equal(t, size, res.FileSize)
+	require.Equal(t, "png", res.Format)
+	require.Equal(t, 3, res.Width)
+	require.Equal(t, 2, res.Height)
+	require.True(t, strings.HasPrefix(res.URL, "http://localhost:8080/api/v1/media/files/"+res.PublicID+"?sig="))
+
+	f, err := s.Open(res.PublicID)
+	require.NoError(t, err)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Len(t, data, int(size))
+}
+
+func TestLocalStorage_SignedURL(t *testing.T) {
+	s := newTestLocalStorage(t)
+
+	res, err := s.Upload(context.Background(), KindFile, strings.NewReader("hello"), "notes.txt")
+	require.NoError(t, err)
+
+	u, err := url.Parse(res.URL)
+	require.NoError(t, err)
+	sig := u.Query().Get("sig")
+	require.True(t, s.Verify(res.PublicID, sig))
+	require.False(t, s.Verify(res.PublicID, ""))
+	require.False(t, s.Verify("tests/files/other.txt", sig))
+
+	other, err := NewLocalStorage(t.TempDir(), "http://localhost", "another-secret", "tests")
+	require.NoError(t, err)
+	require.False(t, other.Verify(res.PublicID, sig))
+}
+
+func TestLocalStorage_CopyAndDelete(t *testing.T) {
+	s := newTestLocalStorage(t)
+	ctx := context.Background()
+
+	src, err := s.Upload(ctx, KindAudio, strings.NewReader("audio"), "clip.mp3")
+	require.NoError(t, err)
+
+	cp, err := s.Copy(ctx, KindAudio, src.PublicID, src.URL)
+	require.NoError(t, err)
+	require.NotEqual(t, src.PublicID, cp.PublicID)
+	require.True(t, strings.HasPrefix(cp.PublicID, "tests/audio/"))
+
+	require.NoError(t, s.Delete(ctx, KindAudio, src.PublicID))
+	_, err = s.Open(src.PublicID)
+	require.True(t, os.IsNotExist(err))
+
+	// The copy survives, and deleting twice is fine
+	f, err := s.Open(cp.PublicID)
+	require.NoError(t, err)
+	f.Close()
+	require.NoError(t, s.Delete(ctx, KindAudio, src.PublicID))
+}
+
+func TestLocalStorage_RejectsEscapingPublicIDs(t *testing.T) {
+	s := newTestLocalStorage(t)
+
+	for _, id := range []string{"", "../secret", "tests/../../secret", "/etc/passwd", "tests//a", "tests\\a", "tests/"} {
+		_, err := s.Open(id)
+		require.ErrorIs(t, err, ErrInvalidPublicID, id)
+		require.ErrorIs(t, s.Delete(context.Background(), KindFile, id), ErrInvalidPublicID, id)
+	}
+}
+
+func TestLocalStorage_SignedUpload(t *testing.T) {
+	s := newTestLocalStorage(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	ticket, err := s.SignUpload(ctx, KindFile, "report.pdf", now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, "PUT", ticket.Method)
+	require.True(t, strings.HasPrefix(ticket.PublicID, "tests/files/"))
+	require.True(t, strings.HasSuffix(ticket.PublicID, ".pdf"))
+
+	u, err := url.Parse(ticket.URL)
+	require.NoError(t, err)
+	q := u.Query()
+	require.True(t, s.VerifyUpload(ticket.PublicID, q.Get("kind"), q.Get("expires"), q.Get("sig"), now))
+	require.False(t, s.VerifyUpload(ticket.PublicID, q.Get("kind"), q.Get("expires"), q.Get("sig"), now.Add(2*time.Minute)))
+	require.False(t, s.VerifyUpload(ticket.PublicID, string(KindImage), q.Get("expires"), q.Get("sig"), now))
+	// A download signature never authorizes an upload
+	require.False(t, s.VerifyUpload(ticket.PublicID, q.Get("kind"), q.Get("expires"), s.sign(ticket.PublicID), now))
+
+	_, err = s.Stat(ctx, KindFile, ticket.PublicID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// Contents are checked before anything is stored, so a rejected upload can be retried
+	_, err = s.Receive(KindFile, ticket.PublicID, strings.NewReader("MZ\x90\x00"))
+	requireCode(t, err, CodeContentMismatch)
+	_, err = s.Stat(ctx, KindFile, ticket.PublicID)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, err = s.Receive(KindFile, ticket.PublicID, strings.NewReader("%PDF-1.4"))
+	require.NoError(t, err)
+	_, err = s.Receive(KindFile, ticket.PublicID, strings.NewReader("again"))
+	require.Error(t, err)
+
+	stat, err := s.Stat(ctx, KindFile, ticket.PublicID)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), stat.FileSize)
+	require.Equal(t, "pdf", stat.Format)
+	require.True(t, s.Verify(ticket.PublicID, mustQuery(t, stat.URL).Get("sig")))
+}
+
+func mustQuery(t *testing.T, rawURL string) url.Values {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u.Query()
+}
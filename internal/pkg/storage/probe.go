@@ -0,0 +1,364 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+)
+
+// errMalformed is returned by the probes for headers they cannot parse
+var errMalformed = errors.New("storage: malformed media header")
+
+// imageDimensions reads an image's size from its header without decoding it
+func imageDimensions(r io.ReaderAt, size int64, contentType string) (int, int, error) {
+	if contentType == typeWebP {
+		return webpDimensions(r)
+	}
+	cfg, _, err := image.DecodeConfig(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// webpDimensions reads the canvas size from the first chunk of a WebP file
+func webpDimensions(r io.ReaderAt) (int, int, error) {
+	b := make([]byte, 30)
+	if _, err := r.ReadAt(b, 0); err != nil {
+		return 0, 0, errMalformed
+	}
+
+	switch string(b[12:16]) {
+	case "VP8 ":
+		// Lossy: a frame tag, then a start code and 14-bit dimensions
+		if b[23] != 0x9d || b[24] != 0x01 || b[25] != 0x2a {
+			return 0, 0, errMalformed
+		}
+		return int(binary.LittleEndian.Uint16(b[26:]) & 0x3fff), int(binary.LittleEndian.Uint16(b[28:]) & 0x3fff), nil
+	case "VP8L":
+		// Lossless: a signature byte, then 14-bit dimensions minus one
+		if b[20] != 0x2f {
+			return 0, 0, errMalformed
+		}
+		bits := binary.LittleEndian.Uint32(b[21:])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1, nil
+	case "VP8X":
+		// Extended: 24-bit canvas dimensions minus one
+		width := int(b[24]) | int(b[25])<<8 | int(b[26])<<16
+		height := int(b[27]) | int(b[28])<<8 | int(b[29])<<16
+		return width + 1, height + 1, nil
+	}
+	return 0, 0, errMalformed
+}
+
+// audioDuration reads an audio file's length in seconds from its headers
+func audioDuration(r io.ReaderAt, size int64, contentType string) (float64, error) {
+	switch contentType {
+	case typeMP3:
+		return mp3Duration(r, size)
+	case typeWAV:
+		return wavDuration(r, size)
+	case typeAAC:
+		return adtsDuration(r, size)
+	case typeM4A:
+		return mp4Duration(r, size)
+	case typeOgg:
+		return oggDuration(r, size)
+	}
+	return 0, errMalformed
+}
+
+// wavDuration divides the size of the data chunk by the byte rate in the fmt chunk
+func wavDuration(r io.ReaderAt, size int64) (float64, error) {
+	var byteRate uint32
+	hdr := make([]byte, 8)
+	for off := int64(12); off+8 <= size; {
+		if _, err := r.ReadAt(hdr, off); err != nil {
+			return 0, errMalformed
+		}
+		n := int64(binary.LittleEndian.Uint32(hdr[4:]))
+
+		switch string(hdr[:4]) {
+		case "fmt ":
+			fmtChunk := make([]byte, 16)
+			if n < 16 {
+				return 0, errMalformed
+			}
+			if _, err := r.ReadAt(fmtChunk, off+8); err != nil {
+				return 0, errMalformed
+			}
+			byteRate = binary.LittleEndian.Uint32(fmtChunk[8:])
+		case "data":
+			if byteRate == 0 {
+				return 0, errMalformed
+			}
+			// Streaming encoders can leave the size unset
+			if off+8+n > size {
+				n = size - off - 8
+			}
+			return float64(n) / float64(byteRate), nil
+		}
+		off += 8 + n + n&1 // chunks are padded to an even size
+	}
+	return 0, errMalformed
+}
+
+// id3Size returns the length of the ID3v2 tag at the start of head, or zero
+func id3Size(head []byte) int64 {
+	if len(head) < 10 || !bytes.HasPrefix(head, []byte("ID3")) {
+		return 0
+	}
+	// The size is syncsafe: 7 bits per byte
+	n := int64(head[6]&0x7f)<<21 | int64(head[7]&0x7f)<<14 | int64(head[8]&0x7f)<<7 | int64(head[9]&0x7f)
+	n += 10
+	if head[5]&0x10 != 0 {
+		n += 10 // footer
+	}
+	return n
+}
+
+// mp3Frame describes an MPEG audio layer III frame header
+type mp3Frame struct {
+	bitrate    int // bits per second
+	sampleRate int
+	samples    int // per frame
+	sideInfo   int // bytes between the header and the frame data
+}
+
+var (
+	mp3Bitrates = [2][16]int{ // kbps for layer III, MPEG-1 then MPEG-2 and 2.5
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+	}
+	mp3SampleRates = [3]int{44100, 48000, 32000} // MPEG-1; halved for MPEG-2, quartered for 2.5
+)
+
+func parseMP3Frame(b []byte) (mp3Frame, bool) {
+	if len(b) < 4 || b[0] != 0xff || b[1]&0xe0 != 0xe0 {
+		return mp3Frame{}, false
+	}
+	version := b[1] >> 3 & 3 // 0: MPEG-2.5, 2: MPEG-2, 3: MPEG-1
+	layer := b[1] >> 1 & 3   // 1: layer III
+	bitrateIndex := b[2] >> 4
+	rateIndex := b[2] >> 2 & 3
+	if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mp3Frame{}, false
+	}
+
+	mono := b[3]>>6 == 3
+	frame := mp3Frame{sampleRate: mp3SampleRates[rateIndex]}
+	if version == 3 {
+		frame.bitrate = mp3Bitrates[0][bitrateIndex] * 1000
+		frame.samples = 1152
+		frame.sideInfo = 32
+		if mono {
+			frame.sideInfo = 17
+		}
+	} else {
+		frame.bitrate = mp3Bitrates[1][bitrateIndex] * 1000
+		frame.samples = 576
+		frame.sideInfo = 17
+		if mono {
+			frame.sideInfo = 9
+		}
+		frame.sampleRate /= 2
+		if version == 0 {
+			frame.sampleRate /= 2
+		}
+	}
+	return frame, true
+}
+
+func isMP3Frame(head []byte) bool {
+	_, ok := parseMP3Frame(head)
+	return ok
+}
+
+// mp3Duration uses the frame count from a Xing, Info or VBRI header when the
+// encoder wrote one, and otherwise assumes a constant bitrate
+func mp3Duration(r io.ReaderAt, size int64) (float64, error) {
+	head := make([]byte, 10)
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return 0, errMalformed
+	}
+	start := id3Size(head)
+
+	// Encoders may pad between the tag and the first frame
+	buf := make([]byte, 4096)
+	n, _ := r.ReadAt(buf, start)
+	buf = buf[:n]
+	for i := 0; i+4 <= len(buf); i++ {
+		frame, ok := parseMP3Frame(buf[i:])
+		if !ok {
+			continue
+		}
+		if frames := mp3FrameCount(buf[i:], frame); frames > 0 {
+			return float64(frames) * float64(frame.samples) / float64(frame.sampleRate), nil
+		}
+		return float64(size-start-int64(i)) * 8 / float64(frame.bitrate), nil
+	}
+	return 0, errMalformed
+}
+
+// mp3FrameCount reads the frame count from a VBR header in the first frame, or zero
+func mp3FrameCount(b []byte, frame mp3Frame) uint32 {
+	if xing := 4 + frame.sideInfo; len(b) >= xing+12 {
+		tag := string(b[xing : xing+4])
+		flags := binary.BigEndian.Uint32(b[xing+4:])
+		if (tag == "Xing" || tag == "Info") && flags&1 != 0 {
+			return binary.BigEndian.Uint32(b[xing+8:])
+		}
+	}
+	if len(b) >= 36+18 && string(b[36:40]) == "VBRI" {
+		return binary.BigEndian.Uint32(b[36+14:])
+	}
+	return 0
+}
+
+func isADTS(head []byte) bool {
+	return len(head) >= 7 && head[0] == 0xff && head[1]&0xf6 == 0xf0
+}
+
+var adtsSampleRates = []int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// adtsDuration counts the frames of a raw AAC stream, 1024 samples each
+func adtsDuration(r io.ReaderAt, size int64) (float64, error) {
+	head := make([]byte, 10)
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return 0, errMalformed
+	}
+
+	hdr := make([]byte, 7)
+	sampleRate, blocks := 0, 0
+	for off := id3Size(head); off+7 <= size; {
+		if _, err := r.ReadAt(hdr, off); err != nil || !isADTS(hdr) {
+			break
+		}
+		if sampleRate == 0 {
+			rateIndex := int(hdr[2] >> 2 & 0xf)
+			if rateIndex >= len(adtsSampleRates) {
+				return 0, errMalformed
+			}
+			sampleRate = adtsSampleRates[rateIndex]
+		}
+		length := int64(hdr[3]&3)<<11 | int64(hdr[4])<<3 | int64(hdr[5]>>5)
+		if length < 7 {
+			break
+		}
+		blocks += int(hdr[6]&3) + 1
+		off += length
+	}
+	if blocks == 0 {
+		return 0, errMalformed
+	}
+	return float64(blocks) * 1024 / float64(sampleRate), nil
+}
+
+// mp4Duration reads the duration and timescale from the movie header box
+func mp4Duration(r io.ReaderAt, size int64) (float64, error) {
+	moov, moovSize, err := findBox(r, 0, size, "moov")
+	if err != nil {
+		return 0, err
+	}
+	mvhd, mvhdSize, err := findBox(r, moov, moov+moovSize, "mvhd")
+	if err != nil {
+		return 0, err
+	}
+	if mvhdSize < 32 {
+		return 0, errMalformed
+	}
+
+	b := make([]byte, 32)
+	if _, err := r.ReadAt(b, mvhd); err != nil {
+		return 0, errMalformed
+	}
+	var timescale uint32
+	var duration uint64
+	if b[0] == 1 {
+		timescale = binary.BigEndian.Uint32(b[20:])
+		duration = binary.BigEndian.Uint64(b[24:])
+	} else {
+		timescale = binary.BigEndian.Uint32(b[12:])
+		duration = uint64(binary.BigEndian.Uint32(b[16:]))
+	}
+	if timescale == 0 {
+		return 0, errMalformed
+	}
+	return float64(duration) / float64(timescale), nil
+}
+
+// findBox returns the offset and size of the payload of the first box named
+// name between start and end
+func findBox(r io.ReaderAt, start, end int64, name string) (int64, int64, error) {
+	hdr := make([]byte, 16)
+	for off := start; off+8 <= end; {
+		if _, err := r.ReadAt(hdr[:8], off); err != nil {
+			return 0, 0, errMalformed
+		}
+		n, headerLen := int64(binary.BigEndian.Uint32(hdr)), int64(8)
+		switch n {
+		case 0: // runs to the end
+			n = end - off
+		case 1: // 64-bit size follows the name
+			if _, err := r.ReadAt(hdr[8:], off+8); err != nil {
+				return 0, 0, errMalformed
+			}
+			n, headerLen = int64(binary.BigEndian.Uint64(hdr[8:])), 16
+		}
+		if n < headerLen || n > end-off {
+			return 0, 0, errMalformed
+		}
+		if string(hdr[4:8]) == name {
+			return off + headerLen, n - headerLen, nil
+		}
+		off += n
+	}
+	return 0, 0, errMalformed
+}
+
+// oggMaxPage is the largest possible Ogg page
+const oggMaxPage = 27 + 255 + 255*255
+
+// oggDuration divides the last page's granule position, the stream's length
+// in samples, by the sample rate from the Vorbis or Opus header
+func oggDuration(r io.ReaderAt, size int64) (float64, error) {
+	head := make([]byte, sniffLen)
+	n, _ := r.ReadAt(head, 0)
+	head = head[:n]
+	if len(head) < 27 || int(head[26])+27 > len(head) {
+		return 0, errMalformed
+	}
+	packet := head[27+int(head[26]):]
+
+	var rate, preSkip int64
+	switch {
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")) && len(packet) >= 16:
+		rate = int64(binary.LittleEndian.Uint32(packet[12:]))
+	case bytes.HasPrefix(packet, []byte("OpusHead")) && len(packet) >= 12:
+		rate = 48000 // Opus granules always count at 48kHz
+		preSkip = int64(binary.LittleEndian.Uint16(packet[10:]))
+	default:
+		return 0, errMalformed
+	}
+
+	tailSize := int64(oggMaxPage)
+	if tailSize > size {
+		tailSize = size
+	}
+	tail := make([]byte, tailSize)
+	if _, err := r.ReadAt(tail, size-tailSize); err != nil && err != io.EOF {
+		return 0, errMalformed
+	}
+	last := bytes.LastIndex(tail, []byte("OggS"))
+	if last < 0 || last+14 > len(tail) {
+		return 0, errMalformed
+	}
+
+	samples := int64(binary.LittleEndian.Uint64(tail[last+6:])) - preSkip
+	if rate == 0 || samples <= 0 {
+		return 0, errMalformed
+	}
+	return float64(samples) / float64(rate), nil
+}
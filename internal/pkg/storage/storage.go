@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/xyz-asif/gotodo/internal/config"
+	"github.com/xyz-asif/gotodo/internal/pkg/cloudinary"
+)
+
+// Kind is the kind of media an asset holds. Drivers use it to pick where the
+// asset is stored and how it is delivered.
+type Kind string
+
+const (
+	KindImage Kind = "image"
+	KindAudio Kind = "audio"
+	KindFile  Kind = "file"
+)
+
+// UploadResult describes a stored asset
+type UploadResult struct {
+	URL      string
+	PublicID string
+	Width    int
+	Height   int
+	Duration float64 // for audio, in seconds
+	FileSize int64
+	Format   string
+	// ContentType is the type sniffed from the stored bytes, when the driver
+	// looked at them
+	ContentType string
+}
+
+// Storage stores uploaded media. Public IDs are only meaningful to the driver
+// that issued them.
+type Storage interface {
+	// Upload stores the contents of file and returns where it can be downloaded
+	Upload(ctx context.Context, kind Kind, file io.Reader, filename string) (*UploadResult, error)
+	// Copy duplicates a stored asset under a new public ID, so the copy is
+	// unaffected when the source is deleted
+	Copy(ctx context.Context, kind Kind, publicID, sourceURL string) (*UploadResult, error)
+	// Delete removes an asset
+	Delete(ctx context.Context, kind Kind, publicID string) error
+}
+
+// Tracker keeps a record of stored assets, so ones nothing references any
+// more can be found and deleted
+type Tracker interface {
+	// Track records an asset that was just stored, or is about to be by a
+	// direct upload
+	Track(ctx context.Context, kind Kind, publicID string)
+	// Release queues an asset that is no longer used, to be deleted in the
+	// background once nothing else references it
+	Release(ctx context.Context, kind Kind, publicID string)
+}
+
+// New creates the storage driver selected by cfg. folder groups the assets
+// of one feature, e.g. "profiles".
+func New(cfg *config.Config, folder string) (Storage, error) {
+	switch cfg.StorageDriver {
+	case "local":
+		local, err := NewLocalStorage(cfg.LocalStorageDir, cfg.LocalStorageURL, cfg.StorageSigningSecret, folder)
+		if err != nil {
+			return nil, err
+		}
+		return local, nil
+	case "cloudinary", "":
+		svc, err := cloudinary.NewService(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret, folder)
+		if err != nil {
+			return nil, err
+		}
+		return NewCloudinaryStorage(svc), nil
+	}
+	return nil, fmt.Errorf("storage: unknown driver %q", cfg.StorageDriver)
+}
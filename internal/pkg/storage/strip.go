@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// StripMetadata removes EXIF, XMP, IPTC and comment metadata, which can hold
+// GPS coordinates and camera details, from a JPEG, PNG or WebP image without
+// re-encoding it. A JPEG's EXIF orientation is kept so photos still display
+// upright. Other formats are returned unchanged.
+func StripMetadata(contentType string, data []byte) ([]byte, error) {
+	switch contentType {
+	case typeJPEG:
+		return stripJPEG(data)
+	case typePNG:
+		return stripPNG(data)
+	case typeWebP:
+		return stripWebP(data)
+	}
+	return data, nil
+}
+
+// JPEG markers
+const (
+	jpegSOS  = 0xda // start of scan: entropy-coded data follows
+	jpegAPP1 = 0xe1 // EXIF or XMP
+	jpegAPP2 = 0xe2 // ICC color profile
+	jpegAPPE = 0xee // Adobe color transform
+	jpegAPPF = 0xef
+	jpegCOM  = 0xfe
+)
+
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, errMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	keptOrientation := false
+	for i := 2; ; {
+		if i+2 > len(data) || data[i] != 0xff {
+			return nil, errMalformed
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xff: // fill byte
+			i++
+			continue
+		case marker == jpegSOS:
+			// The image data and everything after it is kept as is
+			return append(out, data[i:]...), nil
+		case marker == 0x01 || marker >= 0xd0 && marker <= 0xd7: // no payload
+			out = append(out, data[i:i+2]...)
+			i += 2
+			continue
+		}
+
+		if i+4 > len(data) {
+			return nil, errMalformed
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end < i+4 || end > len(data) {
+			return nil, errMalformed
+		}
+		segment := data[i:end]
+		i = end
+
+		switch {
+		case marker == jpegAPP1:
+			if o := exifOrientation(segment[4:]); o > 1 && !keptOrientation {
+				out = append(out, orientationSegment(o)...)
+				keptOrientation = true
+			}
+		case marker == jpegCOM, marker > jpegAPP2 && marker <= jpegAPPF && marker != jpegAPPE:
+			// Comments and vendor metadata such as IPTC (APP13)
+		default:
+			// JFIF, ICC profiles, Adobe and the tables the image needs to decode
+			out = append(out, segment...)
+		}
+	}
+}
+
+// exifOrientation reads the orientation tag from an APP1 payload, or zero
+func exifOrientation(payload []byte) uint16 {
+	if !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+		return 0
+	}
+	tiff := payload[6:]
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for e := ifd + 2; e+12 <= len(tiff) && count > 0; e, count = e+12, count-1 {
+		if order.Uint16(tiff[e:]) == 0x0112 {
+			return order.Uint16(tiff[e+8:])
+		}
+	}
+	return 0
+}
+
+// orientationSegment builds an APP1 segment holding only an EXIF orientation tag
+func orientationSegment(orientation uint16) []byte {
+	payload := []byte("Exif\x00\x00")
+	payload = append(payload, "MM\x00\x2a"...)                    // big-endian TIFF header
+	payload = binary.BigEndian.AppendUint32(payload, 8)           // first IFD offset
+	payload = binary.BigEndian.AppendUint16(payload, 1)           // one entry
+	payload = binary.BigEndian.AppendUint16(payload, 0x0112)      // orientation
+	payload = binary.BigEndian.AppendUint16(payload, 3)           // SHORT
+	payload = binary.BigEndian.AppendUint32(payload, 1)           // one value
+	payload = binary.BigEndian.AppendUint16(payload, orientation) // the value, padded to 4 bytes
+	payload = append(payload, 0, 0, 0, 0, 0, 0)                   // padding, then no next IFD
+
+	segment := []byte{0xff, jpegAPP1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// pngMetadataChunks are the ancillary PNG chunks that hold metadata
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true, // also carries XMP
+	"tIME": true,
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		return nil, errMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:8]...)
+	for i := 8; i < len(data); {
+		if i+12 > len(data) {
+			return nil, errMalformed
+		}
+		n := int64(binary.BigEndian.Uint32(data[i:]))
+		if n > int64(len(data)-i-12) {
+			return nil, errMalformed
+		}
+		end := i + 12 + int(n) // length, type, data and CRC
+		name := string(data[i+4 : i+8])
+		if !pngMetadataChunks[name] {
+			out = append(out, data[i:end]...)
+		}
+		i = end
+		if name == "IEND" {
+			break
+		}
+	}
+	return out, nil
+}
+
+// VP8X flags for metadata chunks
+const (
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+)
+
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errMalformed
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+	for i := 12; i+8 <= len(data); {
+		n := int64(binary.LittleEndian.Uint32(data[i+4:]))
+		if n > int64(len(data)-i-8) {
+			return nil, errMalformed
+		}
+		end := i + 8 + int(n)
+		if n&1 == 1 && end < len(data) {
+			end++ // chunks are padded to an even size
+		}
+
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= webpFlagEXIF | webpFlagXMP
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// exifSegment builds an APP1 segment with an orientation tag and a GPS IFD pointer
+func exifSegment(orientation uint16) []byte {
+	tiff := []byte("II\x2a\x00")
+	tiff = binary.LittleEndian.AppendUint32(tiff, 8)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = append(tiff, 0x12, 0x01, 3, 0, 1, 0, 0, 0)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0)
+	tiff = append(tiff, 0x25, 0x88, 4, 0, 1, 0, 0, 0, 0, 0, 0, 0) // GPS IFD
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS 51.5074 N 0.1278 W"...)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xff, 0xe1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+func TestStripMetadata_JPEGKeepsOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 4, 3)), nil))
+	plain := buf.Bytes()
+
+	comment := []byte{0xff, 0xfe, 0x00, 0x07, 'h', 'e', 'l', 'l', 'o'}
+	tagged := append([]byte{0xff, 0xd8}, exifSegment(6)...)
+	tagged = append(tagged, comment...)
+	tagged = append(tagged, plain[2:]...)
+
+	stripped, err := StripMetadata("image/jpeg", tagged)
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(stripped, []byte("GPS")))
+	require.False(t, bytes.Contains(stripped, []byte("hello")))
+	require.True(t, bytes.HasPrefix(stripped[2:], orientationSegment(6)))
+	require.Equal(t, uint16(6), exifOrientation(stripped[6:]))
+
+	img, err := jpeg.Decode(bytes.NewReader(stripped))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 4, 3), img.Bounds())
+
+	// Upright photos need no EXIF at all
+	stripped, err = StripMetadata("image/jpeg", append(append([]byte{0xff, 0xd8}, exifSegment(1)...), plain[2:]...))
+	require.NoError(t, err)
+	require.Equal(t, plain, stripped)
+}
+
+func TestStripMetadata_WebP(t *testing.T) {
+	chunk := func(name string, payload []byte) []byte {
+		b := append([]byte(name), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+		return append(b, payload...)
+	}
+	body := append([]byte("WEBP"), chunk("VP8X", []byte{webpFlagEXIF | webpFlagXMP | 0x10, 0, 0, 0, 1, 0, 0, 1, 0, 0})...)
+	body = append(body, chunk("ALPH", []byte{1, 2})...)
+	body = append(body, chunk("VP8 ", []byte{1, 2, 3, 4})...)
+	body = append(body, chunk("EXIF", []byte("GPS data"))...)
+	body = append(body, chunk("XMP ", []byte("<x:xmpmeta/>"))...)
+	data := append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+
+	stripped, err := StripMetadata("image/webp", data)
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(stripped, []byte("GPS")))
+	require.False(t, bytes.Contains(stripped, []byte("xmpmeta")))
+	require.Equal(t, byte(0x10), stripped[20], "metadata flags are cleared, others kept")
+	require.Equal(t, uint32(len(stripped)-8), binary.LittleEndian.Uint32(stripped[4:]))
+	require.True(t, bytes.Contains(stripped, []byte("ALPH\x02\x00\x00\x00\x01\x02VP8 ")))
+}
+
+func TestStripMetadata_RejectsMalformed(t *testing.T) {
+	for _, contentType := range []string{"image/jpeg", "image/png", "image/webp"} {
+		_, err := StripMetadata(contentType, []byte("not an image"))
+		require.Error(t, err, contentType)
+	}
+
+	// Unknown formats pass through
+	data, err := StripMetadata("image/gif", []byte("GIF89a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("GIF89a"), data)
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// File validation constants
+var (
+	AllowedImageTypes = []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
+	AllowedAudioTypes = []string{".mp3", ".wav", ".aac", ".m4a", ".ogg"}
+	AllowedFileTypes  = []string{".pdf", ".docx", ".doc", ".epub", ".txt"}
+
+	MaxImageSize = int64(10 * 1024 * 1024) // 10MB
+	MaxAudioSize = int64(25 * 1024 * 1024) // 25MB
+	MaxFileSize  = int64(50 * 1024 * 1024) // 50MB
+
+	MaxImageDimension = 12000            // pixels, either side
+	MaxImagePixels    = int64(50000000)  // width x height, so small files cannot decode to huge bitmaps
+	MaxAudioDuration  = 60 * time.Minute // at the lowest common bitrates this still fits MaxAudioSize
+)
+
+// Validation error codes, for API error responses
+const (
+	CodeFileTooLarge    = "FILE_TOO_LARGE"
+	CodeUnsupportedType = "UNSUPPORTED_FILE_TYPE"
+	CodeContentMismatch = "FILE_CONTENT_MISMATCH"
+	CodeImageTooLarge   = "IMAGE_DIMENSIONS_TOO_LARGE"
+	CodeAudioTooLong    = "AUDIO_TOO_LONG"
+	CodeUnreadableMedia = "UNREADABLE_MEDIA"
+)
+
+// ValidationError explains why an upload was rejected
+type ValidationError struct {
+	Code    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func invalid(code, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validate checks a file's size and extension against the limits for its kind.
+// It only looks at what the client declared; Inspect checks the contents.
+func Validate(kind Kind, filename string, size int64) error {
+	var label string
+	switch kind {
+	case KindImage:
+		label = "image "
+	case KindAudio:
+		label = "audio "
+	}
+	maxSize := MaxSize(kind)
+
+	// Check file size
+	if size > maxSize {
+		return invalid(CodeFileTooLarge, "%sfile size exceeds maximum allowed size of %d MB", label, maxSize/(1024*1024))
+	}
+
+	// Check file extension
+	ext := getFileExtension(filename)
+	allowed := AllowedTypes(kind)
+	if !isAllowedExtension(ext, allowed) {
+		return invalid(CodeUnsupportedType, "invalid %sfile type: %s. Allowed types: %s", label, ext, strings.Join(allowed, ", "))
+	}
+
+	return nil
+}
+
+// ValidateStored checks what storage reports about an asset that was uploaded
+// straight to it, since the API never saw the bytes
+func ValidateStored(kind Kind, stat *UploadResult) error {
+	ext := "." + strings.ToLower(stat.Format)
+	if err := Validate(kind, ext, stat.FileSize); err != nil {
+		return err
+	}
+	if stat.ContentType != "" && stat.ContentType != extensionTypes[ext] {
+		return invalid(CodeContentMismatch, "file contents do not match its %s extension", ext)
+	}
+
+	switch kind {
+	case KindImage:
+		return CheckDimensions(stat.Width, stat.Height)
+	case KindAudio:
+		return CheckDuration(stat.Duration)
+	}
+	return nil
+}
+
+// CheckDimensions checks an image's size in pixels against the limits
+func CheckDimensions(width, height int) error {
+	if width > MaxImageDimension || height > MaxImageDimension || int64(width)*int64(height) > MaxImagePixels {
+		return invalid(CodeImageTooLarge, "image dimensions %dx%d exceed the maximum of %d pixels per side or %d megapixels", width, height, MaxImageDimension, MaxImagePixels/1000000)
+	}
+	return nil
+}
+
+// CheckDuration checks an audio file's length, in seconds, against the limit
+func CheckDuration(seconds float64) error {
+	if seconds > MaxAudioDuration.Seconds() {
+		return invalid(CodeAudioTooLong, "audio duration exceeds maximum allowed length of %d minutes", int(MaxAudioDuration.Minutes()))
+	}
+	return nil
+}
+
+// MaxSize returns the largest upload allowed for kind
+func MaxSize(kind Kind) int64 {
+	switch kind {
+	case KindImage:
+		return MaxImageSize
+	case KindAudio:
+		return MaxAudioSize
+	}
+	return MaxFileSize
+}
+
+// AllowedTypes returns the file extensions allowed for kind
+func AllowedTypes(kind Kind) []string {
+	switch kind {
+	case KindImage:
+		return AllowedImageTypes
+	case KindAudio:
+		return AllowedAudioTypes
+	}
+	return AllowedFileTypes
+}
+
+// getFileExtension returns the lowercase file extension including the dot
+func getFileExtension(filename string) string {
+	ext := filepath.Ext(filename)
+	return strings.ToLower(ext)
+}
+
+// isAllowedExtension checks if the extension is in the allowed list
+func isAllowedExtension(ext string, allowedTypes []string) bool {
+	for _, allowed := range allowedTypes {
+		if ext == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xyz-asif/gotodo/internal/config"
+	"github.com/xyz-asif/gotodo/internal/pkg/ratelimit"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// rateLimitMiddleware builds the per-route-group rate limit policies from config.
+// Patterns are full gin routes, so it must be attached before any routes are registered.
+func rateLimitMiddleware(db *mongo.Database, cfg *config.Config) gin.HandlerFunc {
+	window := time.Duration(cfg.RateLimitWindowSeconds) * time.Second
+	if window <= 0 {
+		window = time.Minute
+	}
+
+	// A shared Mongo store keeps limits consistent across API instances;
+	// the in-memory stores are per-process and swept by each limiter's janitor.
+	var sharedStore ratelimit.Store
+	if cfg.RateLimitStore == "mongo" {
+		sharedStore = ratelimit.NewMongoStore(db, "rate_limits")
+	}
+
+	newLimiter := func(name string, limit int) *ratelimit.RateLimiter {
+		var store ratelimit.Store
+		switch cfg.RateLimitStore {
+		case "mongo":
+			store = sharedStore
+		case "gcra":
+			store = ratelimit.NewGCRAStore()
+		default:
+			store = ratelimit.NewMemoryStore()
+		}
+
+		l := ratelimit.New(limit, window, ratelimit.WithStore(store), ratelimit.WithNamespace(name))
+		l.StartCleanup(window)
+		return l
+	}
+
+	// Strict: login and abuse-prone endpoints
+	authLimiter := newLimiter("auth", cfg.RateLimitAuthRequests)
+	reportLimiter := newLimiter("reports", cfg.RateLimitReportRequests)
+	uploadLimiter := newLimiter("uploads", cfg.RateLimitUploadRequests)
+
+	// Loose: read-heavy browsing endpoints
+	feedLimiter := newLimiter("feed", cfg.RateLimitFeedRequests)
+	searchLimiter := newLimiter("search", cfg.RateLimitSearchRequests)
+
+	return ratelimit.RouteMiddleware([]ratelimit.Rule{
+		{Pattern: "/api/v1/auth/google", Limiter: authLimiter},
+		{Pattern: "/api/v1/auth/dev-login", Limiter: authLimiter},
+		{Pattern: "/api/v1/reports", Limiter: reportLimiter},
+		{Pattern: "/api/v1/media/upload", Limiter: uploadLimiter},
+		{Pattern: "/api/v1/media/upload-intents", Limiter: uploadLimiter},
+		{Pattern: "/api/v1/anchors/:id/items/upload", Limiter: uploadLimiter},
+		{Pattern: "/api/v1/users/me/profile-picture", Limiter: uploadLimiter},
+		{Pattern: "/api/v1/users/me/cover-image", Limiter: uploadLimiter},
+		{Pattern: "/api/v1/feed/*", Limiter: feedLimiter},
+		{Pattern: "/api/v1/search*", Limiter: searchLimiter},
+	})
+}
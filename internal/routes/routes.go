@@ -2,23 +2,29 @@ package routes
 
 import (
 	"context"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xyz-asif/gotodo/internal/config"
 	"github.com/xyz-asif/gotodo/internal/features/anchor_follows"
 	"github.com/xyz-asif/gotodo/internal/features/anchors"
+	"github.com/xyz-asif/gotodo/internal/features/assets"
 	"github.com/xyz-asif/gotodo/internal/features/auth"
+	"github.com/xyz-asif/gotodo/internal/features/bookmarks"
 	"github.com/xyz-asif/gotodo/internal/features/comments"
+	"github.com/xyz-asif/gotodo/internal/features/export"
 	"github.com/xyz-asif/gotodo/internal/features/feed"
 	"github.com/xyz-asif/gotodo/internal/features/follows"
 	"github.com/xyz-asif/gotodo/internal/features/interests"
 	"github.com/xyz-asif/gotodo/internal/features/likes"
 	"github.com/xyz-asif/gotodo/internal/features/media"
 	"github.com/xyz-asif/gotodo/internal/features/notifications"
+	"github.com/xyz-asif/gotodo/internal/features/purge"
 	"github.com/xyz-asif/gotodo/internal/features/safety"
 	"github.com/xyz-asif/gotodo/internal/features/search"
+	"github.com/xyz-asif/gotodo/internal/features/uploads"
 	"github.com/xyz-asif/gotodo/internal/features/users"
-	"github.com/xyz-asif/gotodo/internal/pkg/cloudinary"
+	"github.com/xyz-asif/gotodo/internal/pkg/storage"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -48,30 +54,32 @@ func (s *authAnchorServiceAdapter) DeleteAllByUser(ctx context.Context, userID p
 			continue
 		}
 
-		// 3. Delete assets from Cloudinary
-		if s.cld != nil {
-			for _, item := range items {
-				if item.Type == "image" && item.ImageData != nil && item.ImageData.PublicID != "" {
-					_ = s.cld.Delete(ctx, item.ImageData.PublicID, "image")
-				}
-				if item.Type == "audio" && item.AudioData != nil && item.AudioData.PublicID != "" {
-					_ = s.cld.Delete(ctx, item.AudioData.PublicID, "video") // Audio uses "video" resource type in Cloudinary
-				}
-			}
-		}
-
-		// 4. Delete Anchor (and items from DB)
+		// 3. Delete Anchor (and items from DB)
 		if err := s.repo.DeleteAnchor(ctx, anchor.ID); err != nil {
 			return err
 		}
+
+		// 4. Delete assets from media storage once no item points at them;
+		// failed deletes are retried by the media reconciler
+		for _, item := range items {
+			if item.Type == "image" && item.ImageData != nil {
+				s.media.Release(ctx, storage.KindImage, item.ImageData.PublicID)
+			}
+			if item.Type == "audio" && item.AudioData != nil {
+				s.media.Release(ctx, storage.KindAudio, item.AudioData.PublicID)
+			}
+			if item.Type == "file" && item.FileData != nil {
+				s.media.Release(ctx, storage.KindFile, item.FileData.PublicID)
+			}
+		}
 	}
 	return nil
 }
 
 // authAnchorServiceAdapter adapts anchors.Repository to auth.AnchorService interface
 type authAnchorServiceAdapter struct {
-	repo *anchors.Repository
-	cld  *cloudinary.Service
+	repo  *anchors.Repository
+	media storage.Tracker
 }
 
 func (s *authAnchorServiceAdapter) GetPinnedAnchors(ctx context.Context, userID primitive.ObjectID, includePrivate bool) ([]auth.PinnedAnchorData, error) {
@@ -99,42 +107,73 @@ func (s *authAnchorServiceAdapter) GetPinnedAnchors(ctx context.Context, userID
 	return result, nil
 }
 
-func SetupRoutes(router *gin.Engine, db *mongo.Database, cfg *config.Config) {
+// SetupRoutes registers every feature's routes and starts their background
+// workers. The returned function flushes state that would otherwise be lost
+// on exit; call it during graceful shutdown, after the server has stopped.
+func SetupRoutes(router *gin.Engine, db *mongo.Database, cfg *config.Config) func(context.Context) {
 	// API v1 group
 	api := router.Group("/api/v1")
 
+	// Per-route-group rate limits (must be attached before routes are registered)
+	if cfg.RateLimitEnabled {
+		api.Use(rateLimitMiddleware(db, cfg))
+	}
+
 	// Initialize shared repositories needing external wiring
 	followsRepo := follows.NewRepository(db)
 	anchorsRepo := anchors.NewRepository(db)
 	anchorFollowsRepo := anchor_follows.NewRepository(db)
 
-	// Initialize Cloudinary for adapter usage (to delete assets)
+	// Initialize media storage for adapter usage (to delete assets)
 	// We can reuse the config.
-	cld, _ := cloudinary.NewService(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret, "anchor")
+	mediaStorage, _ := storage.New(cfg, "anchor")
+
+	// Every feature that stores media records it with the tracker, so unused assets can be collected
+	mediaTracker := assets.NewTracker(assets.NewRepository(db), time.Duration(cfg.MediaGCGraceHours)*time.Hour)
+
+	// Link previews are cached in Mongo and shared by URL items and the preview endpoint
+	linkPreviewer := anchors.NewPreviewer(db)
+
+	// Anchor views are counted in batches; the last batch is flushed on shutdown
+	viewTracker := anchors.NewViewTracker(db, anchorsRepo, time.Duration(cfg.ViewDedupWindowMinutes)*time.Minute)
+	viewTracker.Start(context.Background(), time.Duration(cfg.ViewFlushIntervalSeconds)*time.Second)
 
 	// Create adapters for auth package
 	followService := &authFollowServiceAdapter{repo: followsRepo}
-	anchorService := &authAnchorServiceAdapter{repo: anchorsRepo, cld: cld}
+	anchorService := &authAnchorServiceAdapter{repo: anchorsRepo, media: mediaTracker}
 
 	// Register feature routes
 	users.RegisterRoutes(api, db, cfg)
-	auth.RegisterRoutes(api, db, cfg, followService, anchorService)
+	auth.RegisterRoutes(api, db, cfg, followService, anchorService, mediaTracker)
 
 	// Set follower provider to break cycle
 	notifService := notifications.GetService(db)
 	notifService.SetFollowerProvider(anchorFollowsRepo)
 
-	anchors.RegisterRoutes(api, db, cfg, anchorFollowsRepo, notifService)
+	anchors.RegisterRoutes(api, db, cfg, anchorFollowsRepo, notifService, mediaTracker, linkPreviewer, viewTracker)
 	anchor_follows.RegisterRoutes(api, db, cfg)
 	follows.RegisterRoutes(api, db, cfg)
 	likes.RegisterRoutes(api, db, cfg)
 	comments.RegisterRoutes(api, db, cfg)
+	bookmarks.RegisterRoutes(api, db, cfg)
 
 	notifications.RegisterRoutes(api, db, cfg, anchorsRepo)
 
 	search.RegisterRoutes(api, db, cfg)
 	feed.RegisterRoutes(api, db, cfg)
-	media.RegisterRoutes(api, db, cfg)
+	media.RegisterRoutes(api, db, cfg, mediaTracker, linkPreviewer)
+	uploads.RegisterRoutes(api, db, cfg, mediaTracker)
 	interests.RegisterRoutes(api, db, cfg)
 	safety.RegisterRoutes(api, db, cfg)
+	export.RegisterRoutes(api, db, cfg)
+
+	// Deleted accounts are purged in the background after their grace period; the anchors adapter removes content and assets
+	purge.RegisterRoutes(api, db, cfg, anchorService, mediaStorage)
+
+	// Assets nothing references any more are deleted in the background
+	assets.RegisterRoutes(api, db, cfg, mediaStorage)
+
+	return func(ctx context.Context) {
+		viewTracker.Stop(ctx)
+	}
 }